@@ -9,12 +9,18 @@ import (
 	"time"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/mr-isik/loki-backend/internal/config"
 	"github.com/mr-isik/loki-backend/internal/database"
+	"github.com/mr-isik/loki-backend/internal/domain"
+	"github.com/mr-isik/loki-backend/internal/engine"
+	"github.com/mr-isik/loki-backend/internal/engine/nodes"
 	"github.com/mr-isik/loki-backend/internal/handler"
 	"github.com/mr-isik/loki-backend/internal/repository"
 	"github.com/mr-isik/loki-backend/internal/router"
 	"github.com/mr-isik/loki-backend/internal/service"
+	"github.com/mr-isik/loki-backend/internal/trigger"
 	"github.com/mr-isik/loki-backend/internal/util"
+	"github.com/mr-isik/loki-backend/internal/worker"
 
 	_ "github.com/mr-isik/loki-backend/docs" // Swagger docs
 )
@@ -40,13 +46,7 @@ import (
 
 func main() {
 
-	dbConfig := database.NewConfig(
-		getEnv("DB_HOST", "localhost"),
-		getEnv("DB_PORT", "5432"),
-		getEnv("DB_USER", "loki"),
-		getEnv("DB_PASSWORD", "loki_password"),
-		getEnv("DB_NAME", "loki_db"),
-	)
+	dbConfig := config.Database()
 
 	log.Println("🔌 Connecting to database...")
 	db, err := database.NewDatabase(dbConfig)
@@ -61,12 +61,15 @@ func main() {
 	}
 
 	jwtManager := util.NewJWTManager(
-		getEnv("JWT_ACCESS_SECRET", "your-super-secret-access-key-change-this-in-production"),
-		getEnv("JWT_REFRESH_SECRET", "your-super-secret-refresh-key-change-this-in-production"),
+		config.GetEnv("JWT_ACCESS_SECRET", "your-super-secret-access-key-change-this-in-production"),
+		config.GetEnv("JWT_REFRESH_SECRET", "your-super-secret-refresh-key-change-this-in-production"),
 		15*time.Minute,
 		7*24*time.Hour,
 	)
 
+	engineConfig := config.Engine()
+	activeRuns := engine.NewActiveRuns()
+
 	userRepo := repository.NewUserRepository(db.Pool)
 	workspaceRepo := repository.NewWorkspaceRepository(db.Pool)
 	workflowRepo := repository.NewWorkflowRepository(db.Pool)
@@ -75,33 +78,54 @@ func main() {
 	nodeTemplateRepo := repository.NewNodeTemplateRepository(db.Pool)
 	workflowRunRepo := repository.NewWorkflowRunRepository(db.Pool)
 	nodeRunLogRepo := repository.NewNodeRunLogRepository(db.Pool)
+	workflowRunStateRepo := repository.NewWorkflowRunStateRepository(db.Pool)
+	workflowRunJobRepo := repository.NewWorkflowRunJobRepository(db.Pool)
+	nodeTestRunRepo := repository.NewNodeTestRunRepository(db.Pool)
+	workflowScheduleRepo := repository.NewWorkflowScheduleRepository(db.Pool)
+	workflowPollRepo := repository.NewWorkflowPollRepository(db.Pool)
+	workflowListenRepo := repository.NewWorkflowListenRepository(db.Pool)
 
 	authService := service.NewAuthService(userRepo, jwtManager)
 	userService := service.NewUserService(userRepo)
 	workspaceService := service.NewWorkspaceService(workspaceRepo)
-	workflowService := service.NewWorkflowService(workflowRepo, workspaceRepo)
+	workflowService := service.NewWorkflowService(workflowRepo, workspaceRepo, workflowNodeRepo, workflowEdgeRepo, nodeTemplateRepo)
 	workflowEdgeService := service.NewWorkflowEdgeService(workflowEdgeRepo)
 	workflowNodeService := service.NewWorkflowNodeService(workflowNodeRepo)
 	nodeTemplateService := service.NewNodeTemplateService(nodeTemplateRepo)
-	workflowRunService := service.NewWorkflowRunService(workflowRunRepo)
-	nodeRunLogService := service.NewNodeRunLogService(nodeRunLogRepo)
+	workflowRunService := service.NewWorkflowRunService(workflowRunRepo, workflowService, activeRuns)
+	nodeRunLogService := service.NewNodeRunLogService(nodeRunLogRepo, workflowRunRepo, workflowService)
+	nodeTestService := service.NewNodeTestService(workflowNodeRepo, nodeTestRunRepo, workflowService, engineConfig)
+	workflowScheduleService := service.NewWorkflowScheduleService(workflowScheduleRepo, workflowService)
+	workflowExecutionService := service.NewWorkflowExecutionService(
+		workflowRepo,
+		workflowNodeRepo,
+		workflowEdgeRepo,
+		workflowRunRepo,
+		nodeRunLogRepo,
+		workflowRunStateRepo,
+		workflowRunJobRepo,
+		engineConfig,
+		activeRuns,
+		config.RunRecoveryPolicy(),
+	)
+
+	webhookService := service.NewWebhookService(workflowRepo, workflowNodeRepo, workflowRunService, workflowExecutionService)
+
+	// Sub-workflows are started through the execution service
+	engine.RegisterNode("execute_workflow", func() domain.INodeExecutor {
+		return &nodes.ExecuteWorkflowNode{Runner: workflowExecutionService}
+	})
 
 	authHandler := handler.NewAuthHandler(authService)
 	userHandler := handler.NewUserHandler(userService)
 	workspaceHandler := handler.NewWorkspaceHandler(workspaceService)
-	workflowHandler := handler.NewWorkflowHandler(
-		workflowService,
-		workflowNodeService,
-		workflowEdgeService,
-		workflowRunService,
-		nodeRunLogRepo,
-		workflowRunRepo,
-	)
+	workflowHandler := handler.NewWorkflowHandler(workflowService, workflowRunService, workflowExecutionService, workflowScheduleService)
 	workflowEdgeHandler := handler.NewWorkflowEdgeHandler(workflowEdgeService)
-	workflowNodeHandler := handler.NewWorkflowNodeHandler(workflowNodeService)
+	workflowNodeHandler := handler.NewWorkflowNodeHandler(workflowNodeService, nodeTestService)
 	nodeTemplateHandler := handler.NewNodeTemplateHandler(nodeTemplateService)
 	workflowRunHandler := handler.NewWorkflowRunHandler(workflowRunService)
 	nodeRunLogHandler := handler.NewNodeRunLogHandler(nodeRunLogService)
+	webhookHandler := handler.NewWebhookHandler(webhookService, workflowRunService)
 
 	app := fiber.New(fiber.Config{
 		AppName:      "Loki Backend API",
@@ -109,13 +133,58 @@ func main() {
 		ErrorHandler: customErrorHandler,
 	})
 
-	router.SetupRoutes(app, jwtManager, authHandler, userHandler, workspaceHandler, workflowHandler, workflowEdgeHandler, workflowNodeHandler, nodeTemplateHandler, workflowRunHandler, nodeRunLogHandler)
+	router.SetupRoutes(app, jwtManager, authHandler, userHandler, workspaceHandler, workflowHandler, workflowEdgeHandler, workflowNodeHandler, nodeTemplateHandler, workflowRunHandler, nodeRunLogHandler, webhookHandler)
 
-	port := getEnv("PORT", ":3000")
+	port := config.GetEnv("PORT", ":3000")
 	if port[0] != ':' {
 		port = ":" + port
 	}
 
+	// Pick up cancellations requested through other instances.
+	watchCtx, stopWatch := context.WithCancel(ctx)
+	defer stopWatch()
+	go activeRuns.Watch(watchCtx, workflowRunRepo, config.GetEnvDuration("RUN_CANCEL_POLL_INTERVAL", 2*time.Second))
+
+	// Execute queued runs in this process unless dedicated workers (cmd/worker) are used.
+	workerCtx, stopWorker := context.WithCancel(ctx)
+	defer stopWorker()
+	var workerDone chan struct{}
+	if config.GetEnv("EMBEDDED_WORKER", "true") == "true" {
+		workerConfig := config.Worker()
+
+		runWorker := worker.NewWorker(workerConfig, workflowRunJobRepo, workflowExecutionService)
+		workerDone = make(chan struct{})
+		go func() {
+			defer close(workerDone)
+			runWorker.Run(workerCtx)
+		}()
+	}
+
+	// Fire the cron, polling and listen triggers of published workflows on
+	// the instance holding the leader lock.
+	schedulerCtx, stopScheduler := context.WithCancel(ctx)
+	defer stopScheduler()
+	if config.GetEnv("SCHEDULER_ENABLED", "true") == "true" {
+		schedulerConfig := trigger.DefaultConfig()
+		schedulerConfig.SyncInterval = config.GetEnvDuration("SCHEDULER_SYNC_INTERVAL", schedulerConfig.SyncInterval)
+		schedulerConfig.MisfireThreshold = config.GetEnvDuration("SCHEDULER_MISFIRE_THRESHOLD", schedulerConfig.MisfireThreshold)
+		schedulerConfig.FireRetention = config.GetEnvDuration("SCHEDULER_FIRE_RETENTION", schedulerConfig.FireRetention)
+
+		pollerConfig := trigger.DefaultPollerConfig()
+		pollerConfig.SyncInterval = schedulerConfig.SyncInterval
+		pollerConfig.SeenRetention = config.GetEnvDuration("POLL_SEEN_RETENTION", pollerConfig.SeenRetention)
+
+		listenerConfig := trigger.DefaultListenerConfig()
+		listenerConfig.SyncInterval = schedulerConfig.SyncInterval
+		listenerConfig.MaxBackoff = config.GetEnvDuration("LISTEN_MAX_BACKOFF", listenerConfig.MaxBackoff)
+
+		scheduler := trigger.NewScheduler(schedulerConfig, workflowScheduleRepo, workflowRunService, workflowExecutionService)
+		poller := trigger.NewPoller(pollerConfig, workflowPollRepo, workflowRunService, workflowExecutionService)
+		listener := trigger.NewListener(listenerConfig, workflowListenRepo, workflowRunService, workflowExecutionService)
+		leaderLock := repository.NewAdvisoryLock(db.Pool, trigger.LeaderLockKey)
+		go trigger.RunAsLeader(schedulerCtx, leaderLock, config.GetEnvDuration("SCHEDULER_LEADER_INTERVAL", 5*time.Second), trigger.RunAll(scheduler.Run, poller.Run, listener.Run))
+	}
+
 	go func() {
 		log.Printf("🚀 Server is running on http://localhost%s", port)
 		if err := app.Listen(port); err != nil {
@@ -136,14 +205,18 @@ func main() {
 		log.Fatalf("❌ Server forced to shutdown: %v", err)
 	}
 
-	log.Println("✅ Server stopped gracefully")
-}
-
-func getEnv(key, fallback string) string {
-	if value := os.Getenv(key); value != "" {
-		return value
+	// Stop leasing jobs and give in-flight runs a chance to finish; runs that
+	// do not are resumed by another worker once their lease expires.
+	stopWorker()
+	if workerDone != nil {
+		select {
+		case <-workerDone:
+		case <-ctx.Done():
+			log.Println("⚠️ Runs still in progress; they will be resumed by another worker")
+		}
 	}
-	return fallback
+
+	log.Println("✅ Server stopped gracefully")
 }
 
 func customErrorHandler(c *fiber.Ctx, err error) error {
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"context"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/mr-isik/loki-backend/internal/config"
+	"github.com/mr-isik/loki-backend/internal/database"
+	"github.com/mr-isik/loki-backend/internal/domain"
+	"github.com/mr-isik/loki-backend/internal/engine"
+	"github.com/mr-isik/loki-backend/internal/engine/nodes"
+	"github.com/mr-isik/loki-backend/internal/repository"
+	"github.com/mr-isik/loki-backend/internal/service"
+	"github.com/mr-isik/loki-backend/internal/worker"
+)
+
+// The worker executes queued workflow runs. Any number of workers can run
+// against the same database; the API only queues runs when it is started
+// with EMBEDDED_WORKER=false.
+func main() {
+	dbConfig := config.Database()
+
+	log.Println("🔌 Connecting to database...")
+	db, err := database.NewDatabase(dbConfig)
+	if err != nil {
+		log.Fatalf("❌ Failed to connect to database: %v", err)
+	}
+	defer db.Close()
+
+	engineConfig := config.Engine()
+	activeRuns := engine.NewActiveRuns()
+
+	workerConfig := config.Worker()
+
+	workflowRepo := repository.NewWorkflowRepository(db.Pool)
+	workflowEdgeRepo := repository.NewWorkflowEdgeRepository(db.Pool)
+	workflowNodeRepo := repository.NewWorkflowNodeRepository(db.Pool)
+	workflowRunRepo := repository.NewWorkflowRunRepository(db.Pool)
+	nodeRunLogRepo := repository.NewNodeRunLogRepository(db.Pool)
+	workflowRunStateRepo := repository.NewWorkflowRunStateRepository(db.Pool)
+	workflowRunJobRepo := repository.NewWorkflowRunJobRepository(db.Pool)
+
+	workflowExecutionService := service.NewWorkflowExecutionService(
+		workflowRepo,
+		workflowNodeRepo,
+		workflowEdgeRepo,
+		workflowRunRepo,
+		nodeRunLogRepo,
+		workflowRunStateRepo,
+		workflowRunJobRepo,
+		engineConfig,
+		activeRuns,
+		config.RunRecoveryPolicy(),
+	)
+
+	// Sub-workflows are started through the execution service
+	engine.RegisterNode("execute_workflow", func() domain.INodeExecutor {
+		return &nodes.ExecuteWorkflowNode{Runner: workflowExecutionService}
+	})
+
+	ctx, stop := context.WithCancel(context.Background())
+	defer stop()
+
+	// Cancellations are requested through the API, possibly on another instance.
+	go activeRuns.Watch(ctx, workflowRunRepo, config.GetEnvDuration("RUN_CANCEL_POLL_INTERVAL", 2*time.Second))
+
+	runWorker := worker.NewWorker(workerConfig, workflowRunJobRepo, workflowExecutionService)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		runWorker.Run(ctx)
+	}()
+
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, os.Interrupt, syscall.SIGTERM)
+	<-quit
+
+	log.Println("🛑 Shutting down worker...")
+	stop()
+
+	// Runs that do not finish in time are resumed by another worker once
+	// their lease expires.
+	select {
+	case <-done:
+		log.Println("✅ Worker stopped gracefully")
+	case <-time.After(config.GetEnvDuration("WORKER_SHUTDOWN_TIMEOUT", 30*time.Second)):
+		log.Println("⚠️ Runs still in progress; they will be resumed by another worker")
+	}
+}
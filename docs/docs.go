@@ -205,6 +205,81 @@ const docTemplate = `{
                 }
             }
         },
+        "/hooks/{workflow_id}/{path}": {
+            "post": {
+                "description": "Start a run of a published workflow from its webhook node. The request must match the node's method and path and pass its authentication (header token, basic auth or an HMAC signature). The run input carries the parsed body, query, headers and raw body. In the on_received response mode the run is returned with 202 right away; in the respond_node mode the request waits for the run and returns its output, which a respond node can set.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Webhooks"
+                ],
+                "summary": "Trigger a workflow webhook",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Workflow ID (UUID)",
+                        "name": "workflow_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Path configured on the webhook node",
+                        "name": "path",
+                        "in": "path"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/domain.WorkflowRunResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "405": {
+                        "description": "Method Not Allowed",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
         "/node-run-logs": {
             "post": {
                 "security": [
@@ -1191,35 +1266,46 @@ const docTemplate = `{
                 }
             }
         },
-        "/workflow-runs/{id}": {
-            "get": {
+        "/workflow-nodes/{id}/test": {
+            "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Retrieve workflow run information by ID",
+                "description": "Execute a node with its saved data and the given input, without running the workflow. The outcome is stored as a test run, apart from workflow runs.",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Workflow Runs"
+                    "Workflow Nodes"
                 ],
-                "summary": "Get workflow run by ID",
+                "summary": "Test workflow node",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Workflow Run ID (UUID)",
+                        "description": "Workflow Node ID (UUID)",
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "description": "Node input, keyed by input handle",
+                        "name": "request",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/domain.TestNodeRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/domain.WorkflowRunResponse"
+                            "$ref": "#/definitions/domain.NodeTestRunResponse"
                         }
                     },
                     "400": {
@@ -1234,6 +1320,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/handler.ErrorResponse"
                         }
                     },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
                     "404": {
                         "description": "Not Found",
                         "schema": {
@@ -1249,45 +1341,39 @@ const docTemplate = `{
                 }
             }
         },
-        "/workflow-runs/{id}/status": {
-            "patch": {
+        "/workflow-nodes/{id}/tests": {
+            "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Update the status of a workflow run",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Retrieve the latest test runs of a node, newest first",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Workflow Runs"
+                    "Workflow Nodes"
                 ],
-                "summary": "Update workflow run status",
+                "summary": "List node test runs",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Workflow Run ID (UUID)",
+                        "description": "Workflow Node ID (UUID)",
                         "name": "id",
                         "in": "path",
                         "required": true
-                    },
-                    {
-                        "description": "Status update",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/domain.UpdateWorkflowRunStatusRequest"
-                        }
                     }
                 ],
                 "responses": {
-                    "204": {
-                        "description": "No Content"
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.NodeTestRunResponse"
+                            }
+                        }
                     },
                     "400": {
                         "description": "Bad Request",
@@ -1301,6 +1387,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/handler.ErrorResponse"
                         }
                     },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
                     "404": {
                         "description": "Not Found",
                         "schema": {
@@ -1316,49 +1408,35 @@ const docTemplate = `{
                 }
             }
         },
-        "/workflow-runs/{run_id}/logs": {
+        "/workflow-runs/{id}": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Retrieve all node execution logs for a specific workflow run with pagination",
+                "description": "Retrieve workflow run information by ID",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Node Run Logs"
+                    "Workflow Runs"
                 ],
-                "summary": "Get node run logs by workflow run ID",
+                "summary": "Get workflow run by ID",
                 "parameters": [
                     {
                         "type": "string",
                         "description": "Workflow Run ID (UUID)",
-                        "name": "run_id",
+                        "name": "id",
                         "in": "path",
                         "required": true
-                    },
-                    {
-                        "type": "integer",
-                        "default": 1,
-                        "description": "Page number (1-based)",
-                        "name": "page",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "default": 20,
-                        "description": "Items per page",
-                        "name": "page_size",
-                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Returns paginated logs",
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/domain.PaginatedResponse"
+                            "$ref": "#/definitions/domain.WorkflowRunResponse"
                         }
                     },
                     "400": {
@@ -1373,6 +1451,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/handler.ErrorResponse"
                         }
                     },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -1382,35 +1466,35 @@ const docTemplate = `{
                 }
             }
         },
-        "/workflows/{id}": {
-            "get": {
+        "/workflow-runs/{id}/cancel": {
+            "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Retrieve workflow information by ID",
+                "description": "Stop a pending or running workflow run. Nodes that are still running are cancelled and nodes that have not started are skipped.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Workflows"
+                    "Workflow Runs"
                 ],
-                "summary": "Get workflow by ID",
+                "summary": "Cancel workflow run",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Workflow ID (UUID)",
+                        "description": "Workflow Run ID (UUID)",
                         "name": "id",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "202": {
+                        "description": "Accepted",
                         "schema": {
-                            "$ref": "#/definitions/domain.WorkflowResponse"
+                            "$ref": "#/definitions/domain.WorkflowRunResponse"
                         }
                     },
                     "400": {
@@ -1431,6 +1515,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/handler.ErrorResponse"
                         }
                     },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -1438,45 +1528,41 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "put": {
+            }
+        },
+        "/workflow-runs/{id}/children": {
+            "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Update workflow information",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "List the runs started by execute_workflow nodes of a workflow run",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Workflows"
+                    "Workflow Runs"
                 ],
-                "summary": "Update workflow",
+                "summary": "List child runs",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Workflow ID (UUID)",
+                        "description": "Workflow Run ID (UUID)",
                         "name": "id",
                         "in": "path",
                         "required": true
-                    },
-                    {
-                        "description": "Workflow update details",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/domain.UpdateWorkflowRequest"
-                        }
                     }
                 ],
                 "responses": {
-                    "204": {
-                        "description": "No Content"
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.WorkflowRunResponse"
+                            }
+                        }
                     },
                     "400": {
                         "description": "Bad Request",
@@ -1490,12 +1576,6 @@ const docTemplate = `{
                             "$ref": "#/definitions/handler.ErrorResponse"
                         }
                     },
-                    "403": {
-                        "description": "Forbidden",
-                        "schema": {
-                            "$ref": "#/definitions/handler.ErrorResponse"
-                        }
-                    },
                     "404": {
                         "description": "Not Found",
                         "schema": {
@@ -1509,33 +1589,62 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "delete": {
+            }
+        },
+        "/workflow-runs/{id}/rerun": {
+            "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Delete a workflow (soft delete)",
+                "description": "Start a new run of the workflow with the input payload of an earlier run. With from_node, the new run continues from that node: nodes it does not lead to are restored from the outputs of the earlier run instead of executed again.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Workflows"
+                    "Workflow Runs"
                 ],
-                "summary": "Delete workflow",
+                "summary": "Rerun workflow run",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Workflow ID (UUID)",
+                        "description": "Workflow Run ID (UUID)",
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Node ID (UUID) to continue from",
+                        "name": "from_node",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Wait for the run to finish and return its result",
+                        "name": "wait",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Seconds to wait in synchronous mode (default 30, max 300)",
+                        "name": "timeout",
+                        "in": "query"
                     }
                 ],
                 "responses": {
-                    "204": {
-                        "description": "No Content"
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.WorkflowRunResponse"
+                        }
+                    },
+                    "202": {
+                        "description": "Still running when the timeout expired",
+                        "schema": {
+                            "$ref": "#/definitions/domain.WorkflowRunResponse"
+                        }
                     },
                     "400": {
                         "description": "Bad Request",
@@ -1549,19 +1658,352 @@ const docTemplate = `{
                             "$ref": "#/definitions/handler.ErrorResponse"
                         }
                     },
-                    "403": {
-                        "description": "Forbidden",
-                        "schema": {
-                            "$ref": "#/definitions/handler.ErrorResponse"
-                        }
-                    },
                     "404": {
                         "description": "Not Found",
                         "schema": {
                             "$ref": "#/definitions/handler.ErrorResponse"
                         }
                     },
-                    "500": {
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ValidationErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/workflow-runs/{id}/status": {
+            "patch": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Update the status of a workflow run",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Workflow Runs"
+                ],
+                "summary": "Update workflow run status",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Workflow Run ID (UUID)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Status update",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.UpdateWorkflowRunStatusRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/workflow-runs/{run_id}/logs": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Retrieve all node execution logs for a specific workflow run with pagination",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Node Run Logs"
+                ],
+                "summary": "Get node run logs by workflow run ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Workflow Run ID (UUID)",
+                        "name": "run_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Page number (1-based)",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Items per page",
+                        "name": "page_size",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Returns paginated logs",
+                        "schema": {
+                            "$ref": "#/definitions/domain.PaginatedResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/workflows/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Retrieve workflow information by ID",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Workflows"
+                ],
+                "summary": "Get workflow by ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Workflow ID (UUID)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.WorkflowResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Update workflow information",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Workflows"
+                ],
+                "summary": "Update workflow",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Workflow ID (UUID)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Workflow update details",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.UpdateWorkflowRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Delete a workflow (soft delete)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Workflows"
+                ],
+                "summary": "Delete workflow",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Workflow ID (UUID)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
                         "description": "Internal Server Error",
                         "schema": {
                             "$ref": "#/definitions/handler.ErrorResponse"
@@ -1577,14 +2019,145 @@ const docTemplate = `{
                         "BearerAuth": []
                     }
                 ],
-                "description": "Archive a workflow to make it inactive",
+                "description": "Archive a workflow to make it inactive",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Workflows"
+                ],
+                "summary": "Archive workflow",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Workflow ID (UUID)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/workflows/{id}/publish": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Publish a workflow to make it active",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Workflows"
+                ],
+                "summary": "Publish workflow",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Workflow ID (UUID)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ValidationErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/workflows/{id}/run": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Execute a workflow immediately. The optional JSON body is the run's input payload; trigger nodes output it and expressions read it as $run.input. With wait=true the request blocks until the run finishes or the timeout expires, and returns the run with its output.",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "Workflows"
                 ],
-                "summary": "Archive workflow",
+                "summary": "Run workflow",
                 "parameters": [
                     {
                         "type": "string",
@@ -1592,11 +2165,40 @@ const docTemplate = `{
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "description": "Run input payload",
+                        "name": "payload",
+                        "in": "body",
+                        "schema": {
+                            "type": "object"
+                        }
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Wait for the run to finish and return its result",
+                        "name": "wait",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Seconds to wait in synchronous mode (default 30, max 300)",
+                        "name": "timeout",
+                        "in": "query"
                     }
                 ],
                 "responses": {
-                    "204": {
-                        "description": "No Content"
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.WorkflowRunResponse"
+                        }
+                    },
+                    "202": {
+                        "description": "Still running when the timeout expired",
+                        "schema": {
+                            "$ref": "#/definitions/domain.WorkflowRunResponse"
+                        }
                     },
                     "400": {
                         "description": "Bad Request",
@@ -1610,16 +2212,16 @@ const docTemplate = `{
                             "$ref": "#/definitions/handler.ErrorResponse"
                         }
                     },
-                    "403": {
-                        "description": "Forbidden",
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
                             "$ref": "#/definitions/handler.ErrorResponse"
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "422": {
+                        "description": "Unprocessable Entity",
                         "schema": {
-                            "$ref": "#/definitions/handler.ErrorResponse"
+                            "$ref": "#/definitions/handler.ValidationErrorResponse"
                         }
                     },
                     "500": {
@@ -1631,21 +2233,21 @@ const docTemplate = `{
                 }
             }
         },
-        "/workflows/{id}/publish": {
-            "post": {
+        "/workflows/{id}/schedule": {
+            "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Publish a workflow to make it active",
+                "description": "List the cron triggers of a workflow with their next fire times. Only published workflows are scheduled.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "Workflows"
                 ],
-                "summary": "Publish workflow",
+                "summary": "Get workflow schedule",
                 "parameters": [
                     {
                         "type": "string",
@@ -1653,11 +2255,21 @@ const docTemplate = `{
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "default": 5,
+                        "description": "Number of upcoming fire times per trigger (max 100)",
+                        "name": "count",
+                        "in": "query"
                     }
                 ],
                 "responses": {
-                    "204": {
-                        "description": "No Content"
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.WorkflowScheduleResponse"
+                        }
                     },
                     "400": {
                         "description": "Bad Request",
@@ -1692,21 +2304,21 @@ const docTemplate = `{
                 }
             }
         },
-        "/workflows/{id}/run": {
+        "/workflows/{id}/validate": {
             "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Execute a workflow immediately",
+                "description": "Run the static checks on a workflow graph and list the errors and warnings per node",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "Workflows"
                 ],
-                "summary": "Run workflow",
+                "summary": "Validate workflow",
                 "parameters": [
                     {
                         "type": "string",
@@ -1720,7 +2332,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/domain.WorkflowRunResponse"
+                            "$ref": "#/definitions/domain.WorkflowValidationResult"
                         }
                     },
                     "400": {
@@ -1735,6 +2347,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/handler.ErrorResponse"
                         }
                     },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
                     "404": {
                         "description": "Not Found",
                         "schema": {
@@ -2421,9 +3039,18 @@ const docTemplate = `{
                 "status"
             ],
             "properties": {
+                "iteration": {
+                    "description": "Iteration is the loop iteration index of nodes that run inside a loop",
+                    "type": "integer",
+                    "minimum": 0
+                },
                 "node_id": {
                     "type": "string"
                 },
+                "pinned": {
+                    "description": "Pinned marks a node whose pinned output replaces its execution",
+                    "type": "boolean"
+                },
                 "run_id": {
                     "type": "string"
                 },
@@ -2511,6 +3138,9 @@ const docTemplate = `{
         "domain.CreateWorkflowRequest": {
             "type": "object",
             "properties": {
+                "settings": {
+                    "$ref": "#/definitions/domain.WorkflowSettings"
+                },
                 "title": {
                     "type": "string",
                     "maxLength": 255
@@ -2523,6 +3153,28 @@ const docTemplate = `{
                 "workflow_id"
             ],
             "properties": {
+                "depth": {
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "from_node_id": {
+                    "type": "string"
+                },
+                "input_data": {
+                    "type": "object",
+                    "additionalProperties": true
+                },
+                "parent_node_id": {
+                    "type": "string"
+                },
+                "parent_run_id": {
+                    "description": "ParentRunID and Depth link a sub-workflow run to the run that called it",
+                    "type": "string"
+                },
+                "source_run_id": {
+                    "description": "SourceRunID and FromNodeID record what a rerun was started from",
+                    "type": "string"
+                },
                 "workflow_id": {
                     "type": "string"
                 }
@@ -2541,6 +3193,45 @@ const docTemplate = `{
                 }
             }
         },
+        "domain.CronTriggerSchedule": {
+            "type": "object",
+            "properties": {
+                "catch_up_limit": {
+                    "type": "integer"
+                },
+                "end_at": {
+                    "type": "string"
+                },
+                "error": {
+                    "description": "Error explains why the trigger's configuration cannot be scheduled.",
+                    "type": "string"
+                },
+                "expression": {
+                    "type": "string"
+                },
+                "last_fire_at": {
+                    "type": "string"
+                },
+                "missed_fire_policy": {
+                    "type": "string"
+                },
+                "next_fire_times": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "node_id": {
+                    "type": "string"
+                },
+                "start_at": {
+                    "type": "string"
+                },
+                "timezone": {
+                    "type": "string"
+                }
+            }
+        },
         "domain.LoginRequest": {
             "type": "object",
             "required": [
@@ -2567,9 +3258,38 @@ const docTemplate = `{
                 }
             }
         },
+        "domain.NodeRunAttempt": {
+            "type": "object",
+            "properties": {
+                "attempt": {
+                    "type": "integer"
+                },
+                "error_class": {
+                    "type": "string"
+                },
+                "error_msg": {
+                    "type": "string"
+                },
+                "finished_at": {
+                    "type": "string"
+                },
+                "started_at": {
+                    "type": "string"
+                },
+                "status": {
+                    "$ref": "#/definitions/domain.NodeRunLogStatus"
+                }
+            }
+        },
         "domain.NodeRunLogResponse": {
             "type": "object",
             "properties": {
+                "attempts": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.NodeRunAttempt"
+                    }
+                },
                 "created_at": {
                     "type": "string"
                 },
@@ -2582,12 +3302,26 @@ const docTemplate = `{
                 "id": {
                     "type": "string"
                 },
+                "input_data": {
+                    "type": "object",
+                    "additionalProperties": true
+                },
+                "iteration": {
+                    "type": "integer"
+                },
                 "log_output": {
                     "type": "string"
                 },
                 "node_id": {
                     "type": "string"
                 },
+                "output_data": {
+                    "type": "object",
+                    "additionalProperties": true
+                },
+                "pinned": {
+                    "type": "boolean"
+                },
                 "run_id": {
                     "type": "string"
                 },
@@ -2609,14 +3343,18 @@ const docTemplate = `{
                 "running",
                 "completed",
                 "failed",
-                "skipped"
+                "skipped",
+                "timed_out",
+                "cancelled"
             ],
             "x-enum-varnames": [
                 "NodeRunLogStatusPending",
                 "NodeRunLogStatusRunning",
                 "NodeRunLogStatusCompleted",
                 "NodeRunLogStatusFailed",
-                "NodeRunLogStatusSkipped"
+                "NodeRunLogStatusSkipped",
+                "NodeRunLogStatusTimedOut",
+                "NodeRunLogStatusCancelled"
             ]
         },
         "domain.NodeTemplateResponse": {
@@ -2653,6 +3391,46 @@ const docTemplate = `{
                 }
             }
         },
+        "domain.NodeTestRunResponse": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "duration_ms": {
+                    "type": "integer"
+                },
+                "error_msg": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "input_data": {
+                    "type": "object",
+                    "additionalProperties": true
+                },
+                "log": {
+                    "type": "string"
+                },
+                "node_id": {
+                    "type": "string"
+                },
+                "output_data": {
+                    "type": "object",
+                    "additionalProperties": true
+                },
+                "status": {
+                    "$ref": "#/definitions/domain.NodeRunLogStatus"
+                },
+                "triggered_handle": {
+                    "type": "string"
+                },
+                "workflow_id": {
+                    "type": "string"
+                }
+            }
+        },
         "domain.PaginatedResponse": {
             "type": "object",
             "properties": {
@@ -2723,15 +3501,33 @@ const docTemplate = `{
                 }
             }
         },
+        "domain.TestNodeRequest": {
+            "type": "object",
+            "properties": {
+                "input": {
+                    "type": "object",
+                    "additionalProperties": true
+                }
+            }
+        },
         "domain.UpdateNodeRunLogRequest": {
             "type": "object",
             "properties": {
                 "error_msg": {
                     "type": "string"
                 },
+                "input_data": {
+                    "description": "InputData and OutputData are left unchanged when nil",
+                    "type": "object",
+                    "additionalProperties": true
+                },
                 "log_output": {
                     "type": "string"
                 },
+                "output_data": {
+                    "type": "object",
+                    "additionalProperties": true
+                },
                 "status": {
                     "$ref": "#/definitions/domain.NodeRunLogStatus"
                 }
@@ -2791,6 +3587,9 @@ const docTemplate = `{
         "domain.UpdateWorkflowRequest": {
             "type": "object",
             "properties": {
+                "settings": {
+                    "$ref": "#/definitions/domain.WorkflowSettings"
+                },
                 "status": {
                     "enum": [
                         "draft",
@@ -2864,6 +3663,40 @@ const docTemplate = `{
                 }
             }
         },
+        "domain.ValidationIssue": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string"
+                },
+                "edge_id": {
+                    "type": "string"
+                },
+                "field": {
+                    "type": "string"
+                },
+                "message": {
+                    "type": "string"
+                },
+                "node_id": {
+                    "type": "string"
+                },
+                "severity": {
+                    "$ref": "#/definitions/domain.ValidationSeverity"
+                }
+            }
+        },
+        "domain.ValidationSeverity": {
+            "type": "string",
+            "enum": [
+                "error",
+                "warning"
+            ],
+            "x-enum-varnames": [
+                "ValidationSeverityError",
+                "ValidationSeverityWarning"
+            ]
+        },
         "domain.WorkflowEdge": {
             "type": "object",
             "properties": {
@@ -2967,6 +3800,9 @@ const docTemplate = `{
                 "id": {
                     "type": "string"
                 },
+                "settings": {
+                    "$ref": "#/definitions/domain.WorkflowSettings"
+                },
                 "status": {
                     "$ref": "#/definitions/domain.WorkflowStatus"
                 },
@@ -2984,15 +3820,41 @@ const docTemplate = `{
         "domain.WorkflowRunResponse": {
             "type": "object",
             "properties": {
+                "cancel_requested_at": {
+                    "type": "string"
+                },
                 "created_at": {
                     "type": "string"
                 },
+                "depth": {
+                    "type": "integer"
+                },
                 "finished_at": {
                     "type": "string"
                 },
+                "from_node_id": {
+                    "type": "string"
+                },
                 "id": {
                     "type": "string"
                 },
+                "input_data": {
+                    "type": "object",
+                    "additionalProperties": true
+                },
+                "output_data": {
+                    "type": "object",
+                    "additionalProperties": true
+                },
+                "parent_node_id": {
+                    "type": "string"
+                },
+                "parent_run_id": {
+                    "type": "string"
+                },
+                "source_run_id": {
+                    "type": "string"
+                },
                 "started_at": {
                     "type": "string"
                 },
@@ -3024,6 +3886,38 @@ const docTemplate = `{
                 "WorkflowRunStatusCancelled"
             ]
         },
+        "domain.WorkflowScheduleResponse": {
+            "type": "object",
+            "properties": {
+                "active": {
+                    "type": "boolean"
+                },
+                "triggers": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.CronTriggerSchedule"
+                    }
+                },
+                "workflow_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.WorkflowSettings": {
+            "type": "object",
+            "properties": {
+                "max_parallelism": {
+                    "description": "MaxParallelism limits how many nodes of a run execute at the same time (0 is unlimited)",
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "timeout_seconds": {
+                    "description": "TimeoutSeconds limits the total duration of a run (0 uses the server maximum)",
+                    "type": "integer",
+                    "minimum": 0
+                }
+            }
+        },
         "domain.WorkflowStatus": {
             "type": "string",
             "enum": [
@@ -3037,6 +3931,26 @@ const docTemplate = `{
                 "WorkflowStatusArchived"
             ]
         },
+        "domain.WorkflowValidationResult": {
+            "type": "object",
+            "properties": {
+                "errors": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.ValidationIssue"
+                    }
+                },
+                "valid": {
+                    "type": "boolean"
+                },
+                "warnings": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.ValidationIssue"
+                    }
+                }
+            }
+        },
         "domain.WorkspaceResponse": {
             "type": "object",
             "properties": {
@@ -3061,6 +3975,20 @@ const docTemplate = `{
                     "type": "string"
                 }
             }
+        },
+        "handler.ValidationErrorResponse": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string"
+                },
+                "message": {
+                    "type": "string"
+                },
+                "validation": {
+                    "$ref": "#/definitions/domain.WorkflowValidationResult"
+                }
+            }
         }
     },
     "securityDefinitions": {
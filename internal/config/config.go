@@ -0,0 +1,89 @@
+package config
+
+import (
+	"log"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/mr-isik/loki-backend/internal/database"
+	"github.com/mr-isik/loki-backend/internal/domain"
+	"github.com/mr-isik/loki-backend/internal/engine"
+	"github.com/mr-isik/loki-backend/internal/worker"
+)
+
+// Database returns the database settings from the environment.
+func Database() *database.Config {
+	return database.NewConfig(
+		GetEnv("DB_HOST", "localhost"),
+		GetEnv("DB_PORT", "5432"),
+		GetEnv("DB_USER", "loki"),
+		GetEnv("DB_PASSWORD", "loki_password"),
+		GetEnv("DB_NAME", "loki_db"),
+	)
+}
+
+// Engine returns the engine settings from the environment. Every call
+// creates its own node slots, so a process should call it once.
+func Engine() engine.Config {
+	engineConfig := engine.DefaultConfig()
+	engineConfig.DefaultNodeTimeout = GetEnvDuration("ENGINE_NODE_TIMEOUT", engineConfig.DefaultNodeTimeout)
+	engineConfig.MaxNodeTimeout = GetEnvDuration("ENGINE_MAX_NODE_TIMEOUT", engineConfig.MaxNodeTimeout)
+	engineConfig.MaxRunDuration = GetEnvDuration("ENGINE_MAX_RUN_DURATION", engineConfig.MaxRunDuration)
+	engineConfig.DefaultLoopConcurrency = GetEnvInt("ENGINE_LOOP_CONCURRENCY", engineConfig.DefaultLoopConcurrency)
+	engineConfig.NodeSlots = engine.NewSemaphore(GetEnvInt("ENGINE_MAX_CONCURRENT_NODES", 64))
+	engineConfig.MaxWorkflowDepth = GetEnvInt("ENGINE_MAX_WORKFLOW_DEPTH", engineConfig.MaxWorkflowDepth)
+	engineConfig.MaxLogPayloadBytes = GetEnvInt("ENGINE_MAX_LOG_PAYLOAD_BYTES", engineConfig.MaxLogPayloadBytes)
+	engineConfig.PinnedOutputs = GetEnv("ENGINE_PINNED_OUTPUTS", "true") == "true"
+	return engineConfig
+}
+
+// Worker returns the run queue worker settings from the environment.
+func Worker() worker.Config {
+	workerConfig := worker.DefaultConfig()
+	workerConfig.Concurrency = GetEnvInt("WORKER_CONCURRENCY", workerConfig.Concurrency)
+	workerConfig.PollInterval = GetEnvDuration("WORKER_POLL_INTERVAL", workerConfig.PollInterval)
+	workerConfig.LeaseDuration = GetEnvDuration("WORKER_LEASE_DURATION", workerConfig.LeaseDuration)
+	workerConfig.MaxAttempts = GetEnvInt("WORKER_MAX_ATTEMPTS", workerConfig.MaxAttempts)
+	return workerConfig
+}
+
+// RunRecoveryPolicy returns how interrupted runs are recovered.
+func RunRecoveryPolicy() domain.RunRecoveryPolicy {
+	return domain.RunRecoveryPolicy(GetEnv("RUN_RECOVERY_POLICY", string(domain.RunRecoveryPolicyResume)))
+}
+
+func GetEnv(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
+
+func GetEnvInt(key string, fallback int) int {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		log.Printf("⚠️ Invalid integer for %s (%q), using %d", key, value, fallback)
+		return fallback
+	}
+	return n
+}
+
+func GetEnvDuration(key string, fallback time.Duration) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		log.Printf("⚠️ Invalid duration for %s (%q), using %s", key, value, fallback)
+		return fallback
+	}
+	return d
+}
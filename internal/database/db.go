@@ -374,6 +374,289 @@ func (db *Database) RunMigrations(ctx context.Context) error {
 				CREATE INDEX IF NOT EXISTS idx_node_run_logs_started_at ON node_run_logs(started_at DESC);
 			`,
 		},
+		{
+			name: "008_add_node_run_log_attempts",
+			sql: `
+				-- Record every execution attempt of nodes with a retry policy
+				ALTER TABLE node_run_logs ADD COLUMN IF NOT EXISTS attempts JSONB NOT NULL DEFAULT '[]'::JSONB;
+			`,
+		},
+		{
+			name: "009_add_execution_timeouts",
+			sql: `
+				-- Workflow-wide execution settings (e.g. maximum run duration)
+				ALTER TABLE workflows ADD COLUMN IF NOT EXISTS settings JSONB NOT NULL DEFAULT '{}'::JSONB;
+
+				-- Allow timed out node runs to be reported separately from failures.
+				-- NOT VALID keeps this re-runnable once later migrations add more statuses.
+				ALTER TABLE node_run_logs DROP CONSTRAINT IF EXISTS node_run_logs_status_check;
+				ALTER TABLE node_run_logs ADD CONSTRAINT node_run_logs_status_check CHECK (
+					status IN ('pending', 'running', 'completed', 'failed', 'skipped', 'timed_out')
+				) NOT VALID;
+			`,
+		},
+		{
+			name: "010_add_run_cancellation",
+			sql: `
+				-- Cancellation requests are stored so that the instance executing the run can pick them up
+				ALTER TABLE workflow_runs ADD COLUMN IF NOT EXISTS cancel_requested_at TIMESTAMPTZ;
+				CREATE INDEX IF NOT EXISTS idx_workflow_runs_cancel_requested ON workflow_runs(id) WHERE cancel_requested_at IS NOT NULL;
+
+				-- Allow node runs interrupted by a cancellation to be reported as such
+				ALTER TABLE node_run_logs DROP CONSTRAINT IF EXISTS node_run_logs_status_check;
+				ALTER TABLE node_run_logs ADD CONSTRAINT node_run_logs_status_check CHECK (
+					status IN ('pending', 'running', 'completed', 'failed', 'skipped', 'timed_out', 'cancelled')
+				) NOT VALID;
+			`,
+		},
+		{
+			name: "011_create_workflow_run_node_states",
+			sql: `
+				-- Checkpoints of settled nodes, used to resume interrupted runs
+				CREATE TABLE IF NOT EXISTS workflow_run_node_states (
+					run_id UUID NOT NULL REFERENCES workflow_runs(id) ON DELETE CASCADE,
+					node_id UUID NOT NULL REFERENCES workflow_nodes(id) ON DELETE CASCADE,
+					status VARCHAR(50) NOT NULL,
+					triggered_handle VARCHAR(255) NOT NULL DEFAULT '',
+					output JSONB,
+					updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+					PRIMARY KEY (run_id, node_id)
+				);
+			`,
+		},
+		{
+			name: "012_create_workflow_run_jobs_table",
+			sql: `
+				-- Queue of runs waiting to be executed by a worker
+				CREATE TABLE IF NOT EXISTS workflow_run_jobs (
+					id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+					run_id UUID NOT NULL UNIQUE REFERENCES workflow_runs(id) ON DELETE CASCADE,
+					status VARCHAR(50) NOT NULL DEFAULT 'queued',
+					attempts INT NOT NULL DEFAULT 0,
+					lease_owner VARCHAR(255),
+					lease_expires_at TIMESTAMPTZ,
+					created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+					updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+					CONSTRAINT chk_workflow_run_job_status CHECK (
+						status IN ('queued', 'leased', 'completed')
+					)
+				);
+
+				-- Workers only look at jobs that are waiting or leased
+				CREATE INDEX IF NOT EXISTS idx_workflow_run_jobs_pending ON workflow_run_jobs(created_at) WHERE status IN ('queued', 'leased');
+			`,
+		},
+		{
+			name: "013_add_node_run_log_iteration",
+			sql: `
+				-- Index of the loop iteration a log belongs to
+				ALTER TABLE node_run_logs ADD COLUMN IF NOT EXISTS iteration INT;
+			`,
+		},
+		{
+			name: "014_add_sub_workflow_runs",
+			sql: `
+				-- Runs started by an execute_workflow node point to the calling run
+				ALTER TABLE workflow_runs ADD COLUMN IF NOT EXISTS parent_run_id UUID REFERENCES workflow_runs(id) ON DELETE SET NULL;
+				ALTER TABLE workflow_runs ADD COLUMN IF NOT EXISTS depth INT NOT NULL DEFAULT 0;
+				ALTER TABLE workflow_runs ADD COLUMN IF NOT EXISTS input_data JSONB;
+				ALTER TABLE workflow_runs ADD COLUMN IF NOT EXISTS output_data JSONB;
+
+				CREATE INDEX IF NOT EXISTS idx_workflow_runs_parent_run_id ON workflow_runs(parent_run_id) WHERE parent_run_id IS NOT NULL;
+
+				INSERT INTO node_templates (name, description, type_key, category, inputs, outputs) VALUES
+					('Execute Workflow', 'Run another workflow of the workspace and use its result.', 'execute_workflow', 'control', '[
+						{"id": "input", "label": "Run"}
+					]'::JSONB, '[
+						{"id": "output_success", "label": "Finished"},
+						{"id": "output_error", "label": "Failed"}
+					]'::JSONB)
+				ON CONFLICT (type_key) DO NOTHING;
+			`,
+		},
+		{
+			name: "015_add_node_run_log_payloads",
+			sql: `
+				-- Input a node was executed with and the output it produced
+				ALTER TABLE node_run_logs ADD COLUMN IF NOT EXISTS input_data JSONB;
+				ALTER TABLE node_run_logs ADD COLUMN IF NOT EXISTS output_data JSONB;
+			`,
+		},
+		{
+			name: "016_add_manual_trigger",
+			sql: `
+				INSERT INTO node_templates (name, description, type_key, category, inputs, outputs) VALUES
+					('Manual Trigger', 'Start the workflow from the API or the editor, optionally with an input payload.', 'manual_trigger', 'trigger', '[]'::JSONB, '[
+						{"id": "output", "label": "On Run"}
+					]'::JSONB)
+				ON CONFLICT (type_key) DO NOTHING;
+			`,
+		},
+		{
+			name: "017_add_respond_node",
+			sql: `
+				INSERT INTO node_templates (name, description, type_key, category, inputs, outputs) VALUES
+					('Respond', 'Set the result that a synchronous run returns to the caller.', 'respond', 'utility', '[
+						{"id": "input", "label": "Input"}
+					]'::JSONB, '[
+						{"id": "output", "label": "Continue"}
+					]'::JSONB)
+				ON CONFLICT (type_key) DO NOTHING;
+			`,
+		},
+		{
+			name: "018_create_node_test_runs",
+			sql: `
+				-- Debug executions of single nodes, kept apart from workflow runs
+				CREATE TABLE IF NOT EXISTS node_test_runs (
+					id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+					node_id UUID NOT NULL REFERENCES workflow_nodes(id) ON DELETE CASCADE,
+					workflow_id UUID NOT NULL REFERENCES workflows(id) ON DELETE CASCADE,
+					user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+					status VARCHAR(50) NOT NULL,
+					triggered_handle VARCHAR(255) NOT NULL DEFAULT '',
+					log_output TEXT NOT NULL DEFAULT '',
+					error_msg TEXT NOT NULL DEFAULT '',
+					input_data JSONB,
+					output_data JSONB,
+					duration_ms BIGINT NOT NULL DEFAULT 0,
+					created_at TIMESTAMP NOT NULL DEFAULT NOW()
+				);
+
+				CREATE INDEX IF NOT EXISTS idx_node_test_runs_node_id ON node_test_runs(node_id, created_at DESC);
+			`,
+		},
+		{
+			name: "019_add_workflow_run_rerun_source",
+			sql: `
+				-- Reruns point to the run they were started from and, when they
+				-- continued from a node, to that node
+				ALTER TABLE workflow_runs ADD COLUMN IF NOT EXISTS source_run_id UUID REFERENCES workflow_runs(id) ON DELETE SET NULL;
+				ALTER TABLE workflow_runs ADD COLUMN IF NOT EXISTS from_node_id UUID REFERENCES workflow_nodes(id) ON DELETE SET NULL;
+			`,
+		},
+		{
+			name: "020_add_node_run_log_pinned",
+			sql: `
+				-- Logs of nodes whose pinned output was used instead of executing them
+				ALTER TABLE node_run_logs ADD COLUMN IF NOT EXISTS pinned BOOLEAN NOT NULL DEFAULT FALSE;
+			`,
+		},
+		{
+			name: "021_add_set_variable_node",
+			sql: `
+				INSERT INTO node_templates (name, description, type_key, category, inputs, outputs) VALUES
+					('Set Variable', 'Set, increment or append to a run variable that any node can read as $vars.', 'set_variable', 'utility', '[
+						{"id": "input", "label": "Input"}
+					]'::JSONB, '[
+						{"id": "output", "label": "Continue"}
+					]'::JSONB)
+				ON CONFLICT (type_key) DO NOTHING;
+			`,
+		},
+		{
+			name: "022_create_workflow_schedules",
+			sql: `
+				-- State of the cron triggers fired by the scheduler
+				CREATE TABLE IF NOT EXISTS workflow_schedules (
+					node_id UUID PRIMARY KEY REFERENCES workflow_nodes(id) ON DELETE CASCADE,
+					workflow_id UUID NOT NULL REFERENCES workflows(id) ON DELETE CASCADE,
+					last_fire_at TIMESTAMPTZ NOT NULL,
+					updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+				);
+
+				CREATE INDEX IF NOT EXISTS idx_workflow_schedules_workflow_id ON workflow_schedules(workflow_id);
+			`,
+		},
+		{
+			name: "023_update_webhook_node_description",
+			sql: `
+				UPDATE node_templates
+				SET description = 'Trigger a published workflow with an HTTP request to /hooks/{workflow_id}/{path}.'
+				WHERE type_key = 'webhook';
+			`,
+		},
+		{
+			name: "024_create_workflow_schedule_fires",
+			sql: `
+				-- Fire times started by the scheduler, so that each one starts a single run
+				CREATE TABLE IF NOT EXISTS workflow_schedule_fires (
+					workflow_id UUID NOT NULL REFERENCES workflows(id) ON DELETE CASCADE,
+					node_id UUID NOT NULL REFERENCES workflow_nodes(id) ON DELETE CASCADE,
+					fire_time TIMESTAMPTZ NOT NULL,
+					created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+					PRIMARY KEY (workflow_id, node_id, fire_time)
+				);
+
+				CREATE INDEX IF NOT EXISTS idx_workflow_schedule_fires_fire_time ON workflow_schedule_fires(fire_time);
+			`,
+		},
+		{
+			name: "025_create_workflow_poll_state",
+			sql: `
+				-- Polling state of poll_http triggers
+				CREATE TABLE IF NOT EXISTS workflow_poll_states (
+					node_id UUID PRIMARY KEY REFERENCES workflow_nodes(id) ON DELETE CASCADE,
+					workflow_id UUID NOT NULL REFERENCES workflows(id) ON DELETE CASCADE,
+					last_poll_at TIMESTAMPTZ NOT NULL,
+					updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+				);
+
+				-- Items already returned by a poll_http trigger, by ID or content hash
+				CREATE TABLE IF NOT EXISTS workflow_poll_seen_items (
+					node_id UUID NOT NULL REFERENCES workflow_nodes(id) ON DELETE CASCADE,
+					workflow_id UUID NOT NULL REFERENCES workflows(id) ON DELETE CASCADE,
+					item_key TEXT NOT NULL,
+					seen_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+					PRIMARY KEY (node_id, item_key)
+				);
+
+				CREATE INDEX IF NOT EXISTS idx_workflow_poll_seen_items_seen_at ON workflow_poll_seen_items(seen_at);
+
+				INSERT INTO node_templates (name, description, type_key, category, inputs, outputs) VALUES
+					('HTTP Polling', 'Poll an HTTP endpoint on an interval and trigger the workflow for every new item.', 'poll_http', 'trigger', '[]'::JSONB, '[
+						{"id": "output", "label": "On New Item"}
+					]'::JSONB)
+				ON CONFLICT (type_key) DO NOTHING;
+			`,
+		},
+		{
+			name: "026_add_db_postgres_listen_node",
+			sql: `
+				INSERT INTO node_templates (name, description, type_key, category, inputs, outputs) VALUES
+					('Postgres Listen', 'Trigger the workflow for every NOTIFY sent on a PostgreSQL channel.', 'db_postgres_listen', 'trigger', '[]'::JSONB, '[
+						{"id": "output", "label": "On Notification"}
+					]'::JSONB)
+				ON CONFLICT (type_key) DO NOTHING;
+			`,
+		},
+		{
+			name: "027_drop_workflow_runs_heartbeat",
+			sql: `
+				-- Only databases created by an earlier version of 011 have the heartbeat;
+				-- interrupted runs are detected through their job leases instead
+				DROP INDEX IF EXISTS idx_workflow_runs_heartbeat_at;
+				ALTER TABLE workflow_runs DROP COLUMN IF EXISTS heartbeat_at;
+			`,
+		},
+		{
+			name: "028_add_failed_workflow_run_job_status",
+			sql: `
+				-- Jobs whose run was attempted too often are given up as failed
+				ALTER TABLE workflow_run_jobs DROP CONSTRAINT IF EXISTS chk_workflow_run_job_status;
+				ALTER TABLE workflow_run_jobs ADD CONSTRAINT chk_workflow_run_job_status CHECK (
+					status IN ('queued', 'leased', 'completed', 'failed')
+				) NOT VALID;
+			`,
+		},
+		{
+			name: "029_add_workflow_run_parent_node",
+			sql: `
+				-- Sub-workflow runs a parent waits on execute within the parent's job; the node
+				-- lets a resumed parent find them again
+				ALTER TABLE workflow_runs ADD COLUMN IF NOT EXISTS parent_node_id UUID REFERENCES workflow_nodes(id) ON DELETE SET NULL;
+			`,
+		},
 	}
 
 	// Execute migrations in order
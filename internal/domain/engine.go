@@ -2,6 +2,8 @@ package domain
 
 import (
 	"context"
+
+	"github.com/google/uuid"
 )
 
 type NodeResult struct {
@@ -14,3 +16,60 @@ type NodeResult struct {
 type INodeExecutor interface {
 	Execute(ctx context.Context, nodeData []byte) (*NodeResult, error)
 }
+
+// INodeConfigValidator is implemented by node executors whose data must
+// contain certain fields. The fields are checked before a workflow runs.
+type INodeConfigValidator interface {
+	// RequiredFields returns the data fields the node cannot run without.
+	// Fields of nested objects are separated by dots.
+	RequiredFields() []string
+}
+
+// NodeRunInfo identifies the run and node an executor is invoked for.
+type NodeRunInfo struct {
+	RunID      uuid.UUID
+	WorkflowID uuid.UUID
+	NodeID     uuid.UUID
+}
+
+type nodeRunInfoKey struct{}
+
+// WithNodeRunInfo returns a context carrying info for the executor.
+func WithNodeRunInfo(ctx context.Context, info NodeRunInfo) context.Context {
+	return context.WithValue(ctx, nodeRunInfoKey{}, info)
+}
+
+// NodeRunInfoFromContext returns the run information set by the engine.
+func NodeRunInfoFromContext(ctx context.Context) (NodeRunInfo, bool) {
+	info, ok := ctx.Value(nodeRunInfoKey{}).(NodeRunInfo)
+	return info, ok
+}
+
+// ITriggerNode is implemented by the node executors that start a workflow.
+// The engine passes them the input payload of the run in the "trigger" data
+// field, and they output it for the nodes that follow.
+type ITriggerNode interface {
+	IsTrigger() bool
+}
+
+// RunVariables gives executors access to the run-wide variables that
+// expressions read as $vars.
+type RunVariables interface {
+	// UpdateVariable sets a variable to the value fn computes from its
+	// current value. Concurrent updates within a run are applied one at a
+	// time.
+	UpdateVariable(name string, fn func(current interface{}, exists bool) (interface{}, error)) (interface{}, error)
+}
+
+type runVariablesKey struct{}
+
+// WithRunVariables returns a context carrying the variables of a run.
+func WithRunVariables(ctx context.Context, vars RunVariables) context.Context {
+	return context.WithValue(ctx, runVariablesKey{}, vars)
+}
+
+// RunVariablesFromContext returns the run variables set by the engine.
+func RunVariablesFromContext(ctx context.Context) (RunVariables, bool) {
+	vars, ok := ctx.Value(runVariablesKey{}).(RunVariables)
+	return vars, ok
+}
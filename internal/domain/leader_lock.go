@@ -0,0 +1,15 @@
+package domain
+
+import "context"
+
+// LeaderLock is a lock that at most one instance holds at a time. The
+// instance holding it runs the triggers, so that schedules fire once however
+// many instances are deployed. The lock is released when its holder dies.
+type LeaderLock interface {
+	// TryAcquire takes the lock if it is free and reports whether it did.
+	TryAcquire(ctx context.Context) (bool, error)
+	// Check returns an error when a lock taken by TryAcquire is no longer
+	// held, e.g. because the database connection was lost.
+	Check(ctx context.Context) error
+	Release(ctx context.Context) error
+}
@@ -20,44 +20,79 @@ const (
 	NodeRunLogStatusCompleted NodeRunLogStatus = "completed"
 	NodeRunLogStatusFailed    NodeRunLogStatus = "failed"
 	NodeRunLogStatusSkipped   NodeRunLogStatus = "skipped"
+	NodeRunLogStatusTimedOut  NodeRunLogStatus = "timed_out"
+	NodeRunLogStatusCancelled NodeRunLogStatus = "cancelled"
 )
 
+// NodeRunLog records the execution of a node within a run. InputData and
+// OutputData hold the data the node was executed with and the data it
+// produced; payloads over the size limit are replaced by a truncation marker.
+// Pinned logs belong to nodes whose pinned output was used instead of
+// executing them.
 type NodeRunLog struct {
-	ID         uuid.UUID        `json:"id"`
-	RunID      uuid.UUID        `json:"run_id"`
-	NodeID     uuid.UUID        `json:"node_id"`
+	ID         uuid.UUID              `json:"id"`
+	RunID      uuid.UUID              `json:"run_id"`
+	NodeID     uuid.UUID              `json:"node_id"`
+	Status     NodeRunLogStatus       `json:"status"`
+	LogOutput  string                 `json:"log_output,omitempty"`
+	ErrorMsg   string                 `json:"error_msg,omitempty"`
+	Attempts   []NodeRunAttempt       `json:"attempts,omitempty"`
+	Iteration  *int                   `json:"iteration,omitempty"`
+	Pinned     bool                   `json:"pinned"`
+	InputData  map[string]interface{} `json:"input_data,omitempty"`
+	OutputData map[string]interface{} `json:"output_data,omitempty"`
+	StartedAt  time.Time              `json:"started_at"`
+	FinishedAt *time.Time             `json:"finished_at,omitempty"`
+	CreatedAt  time.Time              `json:"created_at"`
+	UpdatedAt  time.Time              `json:"updated_at"`
+}
+
+// NodeRunAttempt records a single execution attempt of a node that has a
+// retry policy configured.
+type NodeRunAttempt struct {
+	Attempt    int              `json:"attempt"`
 	Status     NodeRunLogStatus `json:"status"`
-	LogOutput  string           `json:"log_output,omitempty"`
+	ErrorClass string           `json:"error_class,omitempty"`
 	ErrorMsg   string           `json:"error_msg,omitempty"`
 	StartedAt  time.Time        `json:"started_at"`
-	FinishedAt *time.Time       `json:"finished_at,omitempty"`
-	CreatedAt  time.Time        `json:"created_at"`
-	UpdatedAt  time.Time        `json:"updated_at"`
+	FinishedAt time.Time        `json:"finished_at"`
 }
 
 type CreateNodeRunLogRequest struct {
 	RunID  uuid.UUID        `json:"run_id" validate:"required,uuid4"`
 	NodeID uuid.UUID        `json:"node_id" validate:"required,uuid4"`
 	Status NodeRunLogStatus `json:"status" validate:"required"`
+	// Iteration is the loop iteration index of nodes that run inside a loop
+	Iteration *int `json:"iteration,omitempty" validate:"omitempty,min=0"`
+	// Pinned marks a node whose pinned output replaces its execution
+	Pinned bool `json:"pinned,omitempty"`
 }
 
 type UpdateNodeRunLogRequest struct {
 	Status    NodeRunLogStatus `json:"status" validate:"omitempty"`
 	LogOutput string           `json:"log_output" validate:"omitempty"`
 	ErrorMsg  string           `json:"error_msg" validate:"omitempty"`
+	// InputData and OutputData are left unchanged when nil
+	InputData  map[string]interface{} `json:"input_data,omitempty"`
+	OutputData map[string]interface{} `json:"output_data,omitempty"`
 }
 
 type NodeRunLogResponse struct {
-	ID         uuid.UUID        `json:"id"`
-	RunID      uuid.UUID        `json:"run_id"`
-	NodeID     uuid.UUID        `json:"node_id"`
-	Status     NodeRunLogStatus `json:"status"`
-	LogOutput  string           `json:"log_output,omitempty"`
-	ErrorMsg   string           `json:"error_msg,omitempty"`
-	StartedAt  time.Time        `json:"started_at"`
-	FinishedAt *time.Time       `json:"finished_at,omitempty"`
-	CreatedAt  time.Time        `json:"created_at"`
-	UpdatedAt  time.Time        `json:"updated_at"`
+	ID         uuid.UUID              `json:"id"`
+	RunID      uuid.UUID              `json:"run_id"`
+	NodeID     uuid.UUID              `json:"node_id"`
+	Status     NodeRunLogStatus       `json:"status"`
+	LogOutput  string                 `json:"log_output,omitempty"`
+	ErrorMsg   string                 `json:"error_msg,omitempty"`
+	Attempts   []NodeRunAttempt       `json:"attempts,omitempty"`
+	Iteration  *int                   `json:"iteration,omitempty"`
+	Pinned     bool                   `json:"pinned"`
+	InputData  map[string]interface{} `json:"input_data,omitempty"`
+	OutputData map[string]interface{} `json:"output_data,omitempty"`
+	StartedAt  time.Time              `json:"started_at"`
+	FinishedAt *time.Time             `json:"finished_at,omitempty"`
+	CreatedAt  time.Time              `json:"created_at"`
+	UpdatedAt  time.Time              `json:"updated_at"`
 }
 
 func (nrl *NodeRunLog) ToResponse() *NodeRunLogResponse {
@@ -68,6 +103,11 @@ func (nrl *NodeRunLog) ToResponse() *NodeRunLogResponse {
 		Status:     nrl.Status,
 		LogOutput:  nrl.LogOutput,
 		ErrorMsg:   nrl.ErrorMsg,
+		Attempts:   nrl.Attempts,
+		Iteration:  nrl.Iteration,
+		Pinned:     nrl.Pinned,
+		InputData:  nrl.InputData,
+		OutputData: nrl.OutputData,
 		StartedAt:  nrl.StartedAt,
 		FinishedAt: nrl.FinishedAt,
 		CreatedAt:  nrl.CreatedAt,
@@ -80,11 +120,16 @@ type NodeRunLogRepository interface {
 	GetByID(ctx context.Context, id uuid.UUID) (*NodeRunLog, error)
 	GetByRunID(ctx context.Context, runID uuid.UUID) ([]*NodeRunLog, error)
 	Update(ctx context.Context, id uuid.UUID, req *UpdateNodeRunLogRequest) error
+	AddAttempt(ctx context.Context, id uuid.UUID, attempt *NodeRunAttempt) error
+	// CancelUnfinished marks every pending or running log of a run as cancelled.
+	CancelUnfinished(ctx context.Context, runID uuid.UUID) error
 }
 
 type NodeRunLogService interface {
 	CreateNodeRunLog(ctx context.Context, req *CreateNodeRunLogRequest) error
 	GetNodeRunLog(ctx context.Context, id uuid.UUID) (*NodeRunLogResponse, error)
-	GetNodeRunLogsByRunID(ctx context.Context, runID uuid.UUID) ([]*NodeRunLogResponse, error)
+	// GetNodeRunLogsByRunID reports runs of workflows the user cannot access
+	// as not found.
+	GetNodeRunLogsByRunID(ctx context.Context, runID uuid.UUID, userID uuid.UUID) ([]*NodeRunLogResponse, error)
 	UpdateNodeRunLog(ctx context.Context, id uuid.UUID, req *UpdateNodeRunLogRequest) error
 }
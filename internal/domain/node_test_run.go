@@ -0,0 +1,75 @@
+package domain
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// NodeTestRun is a debug execution of a single node with caller-supplied
+// input. Test runs are kept apart from workflow runs and their logs.
+type NodeTestRun struct {
+	ID              uuid.UUID              `json:"id"`
+	NodeID          uuid.UUID              `json:"node_id"`
+	WorkflowID      uuid.UUID              `json:"workflow_id"`
+	UserID          uuid.UUID              `json:"user_id"`
+	Status          NodeRunLogStatus       `json:"status"`
+	TriggeredHandle string                 `json:"triggered_handle,omitempty"`
+	Log             string                 `json:"log,omitempty"`
+	ErrorMsg        string                 `json:"error_msg,omitempty"`
+	InputData       map[string]interface{} `json:"input_data,omitempty"`
+	OutputData      map[string]interface{} `json:"output_data,omitempty"`
+	DurationMs      int64                  `json:"duration_ms"`
+	CreatedAt       time.Time              `json:"created_at"`
+}
+
+// TestNodeRequest holds the input a node is tested with. Like the input of a
+// node during a run, it is keyed by input handle and read by expressions as
+// $input.
+type TestNodeRequest struct {
+	Input map[string]interface{} `json:"input"`
+}
+
+type NodeTestRunResponse struct {
+	ID              uuid.UUID              `json:"id"`
+	NodeID          uuid.UUID              `json:"node_id"`
+	WorkflowID      uuid.UUID              `json:"workflow_id"`
+	Status          NodeRunLogStatus       `json:"status"`
+	TriggeredHandle string                 `json:"triggered_handle,omitempty"`
+	Log             string                 `json:"log,omitempty"`
+	ErrorMsg        string                 `json:"error_msg,omitempty"`
+	InputData       map[string]interface{} `json:"input_data,omitempty"`
+	OutputData      map[string]interface{} `json:"output_data,omitempty"`
+	DurationMs      int64                  `json:"duration_ms"`
+	CreatedAt       time.Time              `json:"created_at"`
+}
+
+func (r *NodeTestRun) ToResponse() *NodeTestRunResponse {
+	return &NodeTestRunResponse{
+		ID:              r.ID,
+		NodeID:          r.NodeID,
+		WorkflowID:      r.WorkflowID,
+		Status:          r.Status,
+		TriggeredHandle: r.TriggeredHandle,
+		Log:             r.Log,
+		ErrorMsg:        r.ErrorMsg,
+		InputData:       r.InputData,
+		OutputData:      r.OutputData,
+		DurationMs:      r.DurationMs,
+		CreatedAt:       r.CreatedAt,
+	}
+}
+
+type NodeTestRunRepository interface {
+	// Create stores a test run and sets its ID and creation time.
+	Create(ctx context.Context, run *NodeTestRun) error
+	// ListByNodeID returns the latest test runs of a node, newest first.
+	ListByNodeID(ctx context.Context, nodeID uuid.UUID, limit int) ([]*NodeTestRun, error)
+}
+
+type NodeTestService interface {
+	// TestNode executes a node of a workflow the user can access.
+	TestNode(ctx context.Context, nodeID uuid.UUID, userID uuid.UUID, req *TestNodeRequest) (*NodeTestRunResponse, error)
+	ListNodeTestRuns(ctx context.Context, nodeID uuid.UUID, userID uuid.UUID) ([]*NodeTestRunResponse, error)
+}
@@ -0,0 +1,52 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrWebhookNotFound is returned when no webhook node of a published
+	// workflow listens on the requested path.
+	ErrWebhookNotFound = errors.New("webhook not found")
+	// ErrWebhookMethodNotAllowed is returned when a webhook listens on the
+	// path but not for the request's method.
+	ErrWebhookMethodNotAllowed = errors.New("webhook method not allowed")
+	// ErrWebhookUnauthorized is returned when a request fails the webhook's
+	// authentication.
+	ErrWebhookUnauthorized = errors.New("webhook request not authorized")
+)
+
+// WebhookRequest is an inbound HTTP request to a workflow's webhook.
+type WebhookRequest struct {
+	WorkflowID uuid.UUID
+	Method     string
+	// Path is the part of the URL after the workflow ID.
+	Path string
+	// Headers are keyed by lowercase header name.
+	Headers map[string]string
+	Query   map[string]string
+	Body    []byte
+}
+
+// Header returns the value of a request header, ignoring the case of name.
+func (r *WebhookRequest) Header(name string) string {
+	return r.Headers[strings.ToLower(name)]
+}
+
+// WebhookResult is the run started by a webhook request.
+type WebhookResult struct {
+	Run *WorkflowRunResponse
+	// ResponseMode tells whether the caller is answered right away or once
+	// the run has finished.
+	ResponseMode string
+}
+
+type WebhookService interface {
+	// HandleWebhook verifies a request against the matching webhook node and
+	// queues a run of the workflow with the request as its input.
+	HandleWebhook(ctx context.Context, req *WebhookRequest) (*WebhookResult, error)
+}
@@ -21,33 +21,45 @@ const (
 )
 
 type Workflow struct {
-	ID          uuid.UUID      `json:"id"`
-	WorkspaceID uuid.UUID      `json:"workspace_id"`
-	Title       string         `json:"title"`
-	Status      WorkflowStatus `json:"status"`
-	CreatedAt   time.Time      `json:"created_at"`
-	UpdatedAt   time.Time      `json:"updated_at"`
+	ID          uuid.UUID        `json:"id"`
+	WorkspaceID uuid.UUID        `json:"workspace_id"`
+	Title       string           `json:"title"`
+	Status      WorkflowStatus   `json:"status"`
+	Settings    WorkflowSettings `json:"settings"`
+	CreatedAt   time.Time        `json:"created_at"`
+	UpdatedAt   time.Time        `json:"updated_at"`
+}
+
+// WorkflowSettings holds workflow-wide execution options
+type WorkflowSettings struct {
+	// TimeoutSeconds limits the total duration of a run (0 uses the server maximum)
+	TimeoutSeconds int `json:"timeout_seconds,omitempty" validate:"omitempty,min=0"`
+	// MaxParallelism limits how many nodes of a run execute at the same time (0 is unlimited)
+	MaxParallelism int `json:"max_parallelism,omitempty" validate:"omitempty,min=0"`
 }
 
 // CreateWorkflowRequest represents the request to create a workflow
 type CreateWorkflowRequest struct {
-	Title string `json:"title" validate:"omitempty,max=255"`
+	Title    string            `json:"title" validate:"omitempty,max=255"`
+	Settings *WorkflowSettings `json:"settings,omitempty"`
 }
 
 // UpdateWorkflowRequest represents the request to update a workflow
 type UpdateWorkflowRequest struct {
-	Title  string         `json:"title,omitempty" validate:"omitempty,max=255"`
-	Status WorkflowStatus `json:"status,omitempty" validate:"omitempty,oneof=draft published archived"`
+	Title    string            `json:"title,omitempty" validate:"omitempty,max=255"`
+	Status   WorkflowStatus    `json:"status,omitempty" validate:"omitempty,oneof=draft published archived"`
+	Settings *WorkflowSettings `json:"settings,omitempty"`
 }
 
 // WorkflowResponse represents the workflow response
 type WorkflowResponse struct {
-	ID          uuid.UUID      `json:"id"`
-	WorkspaceID uuid.UUID      `json:"workspace_id"`
-	Title       string         `json:"title"`
-	Status      WorkflowStatus `json:"status"`
-	CreatedAt   time.Time      `json:"created_at"`
-	UpdatedAt   time.Time      `json:"updated_at"`
+	ID          uuid.UUID        `json:"id"`
+	WorkspaceID uuid.UUID        `json:"workspace_id"`
+	Title       string           `json:"title"`
+	Status      WorkflowStatus   `json:"status"`
+	Settings    WorkflowSettings `json:"settings"`
+	CreatedAt   time.Time        `json:"created_at"`
+	UpdatedAt   time.Time        `json:"updated_at"`
 }
 
 // ToResponse converts Workflow to WorkflowResponse
@@ -57,6 +69,7 @@ func (w *Workflow) ToResponse() *WorkflowResponse {
 		WorkspaceID: w.WorkspaceID,
 		Title:       w.Title,
 		Status:      w.Status,
+		Settings:    w.Settings,
 		CreatedAt:   w.CreatedAt,
 		UpdatedAt:   w.UpdatedAt,
 	}
@@ -81,4 +94,6 @@ type WorkflowService interface {
 	DeleteWorkflow(ctx context.Context, id uuid.UUID, userID uuid.UUID) error
 	PublishWorkflow(ctx context.Context, id uuid.UUID, userID uuid.UUID) error
 	ArchiveWorkflow(ctx context.Context, id uuid.UUID, userID uuid.UUID) error
+	// ValidateWorkflow runs the static checks on the workflow's graph.
+	ValidateWorkflow(ctx context.Context, id uuid.UUID, userID uuid.UUID) (*WorkflowValidationResult, error)
 }
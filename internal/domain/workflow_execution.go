@@ -0,0 +1,63 @@
+package domain
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrWorkflowDepthExceeded = errors.New("maximum sub-workflow depth exceeded")
+)
+
+// RunRecoveryPolicy decides what happens to runs interrupted by a restart or
+// crash of the instance executing them.
+type RunRecoveryPolicy string
+
+const (
+	RunRecoveryPolicyResume RunRecoveryPolicy = "resume"
+	RunRecoveryPolicyFail   RunRecoveryPolicy = "fail"
+)
+
+type WorkflowExecutionService interface {
+	// StartRun queues a freshly created run for execution by a worker.
+	StartRun(ctx context.Context, runID uuid.UUID) error
+	// ExecuteRun executes a queued run and returns once it has finished.
+	// resume is set when an earlier attempt was interrupted, in which case
+	// the run continues from its checkpoints or fails, depending on the
+	// recovery policy.
+	ExecuteRun(ctx context.Context, runID uuid.UUID, resume bool) error
+	// FailRun fails an unfinished run without executing it, e.g. once its
+	// job was attempted too often.
+	FailRun(ctx context.Context, runID uuid.UUID) error
+	// CreateRerun creates a pending run with the input of an earlier run.
+	// With fromNodeID set, the run continues from that node with the outputs
+	// the earlier run recorded for the nodes before it.
+	CreateRerun(ctx context.Context, sourceRunID uuid.UUID, fromNodeID *uuid.UUID) (*WorkflowRunResponse, error)
+
+	SubWorkflowRunner
+}
+
+// SubWorkflowRunner starts workflows from within a run of another workflow.
+type SubWorkflowRunner interface {
+	// RunSubWorkflow creates a run of another workflow in the same workspace
+	// as the parent run. With Wait set it executes the run and returns its
+	// result; otherwise it only queues it.
+	RunSubWorkflow(ctx context.Context, req *SubWorkflowRequest) (*SubWorkflowResult, error)
+}
+
+type SubWorkflowRequest struct {
+	ParentRunID uuid.UUID
+	// ParentNodeID is the execute_workflow node starting the run.
+	ParentNodeID uuid.UUID
+	WorkflowID   uuid.UUID
+	Input        map[string]interface{}
+	Wait         bool
+}
+
+type SubWorkflowResult struct {
+	RunID  uuid.UUID
+	Status WorkflowRunStatus
+	Output map[string]interface{}
+}
@@ -0,0 +1,20 @@
+package domain
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// ListenTrigger is a db_postgres_listen node of a published workflow.
+type ListenTrigger struct {
+	NodeID     uuid.UUID
+	WorkflowID uuid.UUID
+	Data       map[string]any
+}
+
+type WorkflowListenRepository interface {
+	// ListActive returns the db_postgres_listen triggers of every published
+	// workflow.
+	ListActive(ctx context.Context) ([]*ListenTrigger, error)
+}
@@ -0,0 +1,33 @@
+package domain
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PollTrigger is a poll_http node of a published workflow together with the
+// state of its polling.
+type PollTrigger struct {
+	NodeID     uuid.UUID
+	WorkflowID uuid.UUID
+	Data       map[string]any
+	// LastPollAt is when the endpoint was last polled successfully, or nil
+	// when it has never been.
+	LastPollAt *time.Time
+}
+
+type WorkflowPollRepository interface {
+	// ListActive returns the poll_http triggers of every published workflow.
+	ListActive(ctx context.Context) ([]*PollTrigger, error)
+	// MarkSeen records the distinct keys of the items returned by a poll and
+	// returns those that had not been seen before.
+	MarkSeen(ctx context.Context, nodeID uuid.UUID, workflowID uuid.UUID, keys []string) ([]string, error)
+	// UnmarkSeen forgets items, so that the next poll treats them as new.
+	UnmarkSeen(ctx context.Context, nodeID uuid.UUID, keys []string) error
+	// SetLastPoll records a successful poll of a trigger.
+	SetLastPoll(ctx context.Context, nodeID uuid.UUID, workflowID uuid.UUID, polledAt time.Time) error
+	// DeleteSeenBefore forgets items that no poll has returned since before.
+	DeleteSeenBefore(ctx context.Context, before time.Time) error
+}
@@ -10,6 +10,13 @@ import (
 
 var (
 	ErrWorkflowRunNotFound = errors.New("workflow run not found")
+	ErrWorkflowRunFinished = errors.New("workflow run already finished")
+	// ErrWorkflowRunNotFinished is returned when a run must have finished
+	// for an operation, such as rerunning it from one of its nodes.
+	ErrWorkflowRunNotFinished = errors.New("workflow run has not finished")
+	// ErrInvalidRerunNode is returned when a run cannot continue from the
+	// requested node.
+	ErrInvalidRerunNode = errors.New("workflow run cannot be rerun from this node")
 )
 
 type WorkflowRunStatus string
@@ -30,10 +37,35 @@ type WorkflowRun struct {
 	FinishedAt *time.Time        `json:"finished_at,omitempty"`
 	CreatedAt  time.Time         `json:"created_at"`
 	UpdatedAt  time.Time         `json:"updated_at"`
+
+	CancelRequestedAt *time.Time `json:"cancel_requested_at,omitempty"`
+
+	// ParentRunID is the run whose execute_workflow node started this run.
+	// ParentNodeID is set when that node waits for the run to finish, in
+	// which case the run executes within the parent run instead of a job.
+	ParentRunID  *uuid.UUID             `json:"parent_run_id,omitempty"`
+	ParentNodeID *uuid.UUID             `json:"parent_node_id,omitempty"`
+	Depth        int                    `json:"depth"`
+	InputData    map[string]interface{} `json:"input_data,omitempty"`
+	OutputData   map[string]interface{} `json:"output_data,omitempty"`
+
+	// SourceRunID is the run this run was rerun from. FromNodeID is set when
+	// the rerun continued from one of its nodes with the outputs of the
+	// source run for the nodes before it.
+	SourceRunID *uuid.UUID `json:"source_run_id,omitempty"`
+	FromNodeID  *uuid.UUID `json:"from_node_id,omitempty"`
 }
 
 type CreateWorkflowRunRequest struct {
 	WorkflowID uuid.UUID `json:"workflow_id" validate:"required,uuid4"`
+	// ParentRunID and Depth link a sub-workflow run to the run that called it
+	ParentRunID  *uuid.UUID             `json:"parent_run_id,omitempty"`
+	ParentNodeID *uuid.UUID             `json:"parent_node_id,omitempty"`
+	Depth        int                    `json:"depth,omitempty" validate:"omitempty,min=0"`
+	InputData    map[string]interface{} `json:"input_data,omitempty"`
+	// SourceRunID and FromNodeID record what a rerun was started from
+	SourceRunID *uuid.UUID `json:"source_run_id,omitempty"`
+	FromNodeID  *uuid.UUID `json:"from_node_id,omitempty"`
 }
 
 type UpdateWorkflowRunStatusRequest struct {
@@ -48,6 +80,17 @@ type WorkflowRunResponse struct {
 	FinishedAt *time.Time        `json:"finished_at,omitempty"`
 	CreatedAt  time.Time         `json:"created_at"`
 	UpdatedAt  time.Time         `json:"updated_at"`
+
+	CancelRequestedAt *time.Time `json:"cancel_requested_at,omitempty"`
+
+	ParentRunID  *uuid.UUID             `json:"parent_run_id,omitempty"`
+	ParentNodeID *uuid.UUID             `json:"parent_node_id,omitempty"`
+	Depth        int                    `json:"depth"`
+	InputData    map[string]interface{} `json:"input_data,omitempty"`
+	OutputData   map[string]interface{} `json:"output_data,omitempty"`
+
+	SourceRunID *uuid.UUID `json:"source_run_id,omitempty"`
+	FromNodeID  *uuid.UUID `json:"from_node_id,omitempty"`
 }
 
 func (wr *WorkflowRun) ToResponse() *WorkflowRunResponse {
@@ -59,19 +102,52 @@ func (wr *WorkflowRun) ToResponse() *WorkflowRunResponse {
 		FinishedAt: wr.FinishedAt,
 		CreatedAt:  wr.CreatedAt,
 		UpdatedAt:  wr.UpdatedAt,
+
+		CancelRequestedAt: wr.CancelRequestedAt,
+
+		ParentRunID:  wr.ParentRunID,
+		ParentNodeID: wr.ParentNodeID,
+		Depth:        wr.Depth,
+		InputData:    wr.InputData,
+		OutputData:   wr.OutputData,
+
+		SourceRunID: wr.SourceRunID,
+		FromNodeID:  wr.FromNodeID,
 	}
 }
 
 type WorkflowRunRepository interface {
-	Create(ctx context.Context, workflowID uuid.UUID) (*WorkflowRun, error)
+	Create(ctx context.Context, req *CreateWorkflowRunRequest) (*WorkflowRun, error)
 	GetByID(ctx context.Context, id uuid.UUID) (*WorkflowRun, error)
 	UpdateStatus(ctx context.Context, id uuid.UUID, status WorkflowRunStatus, finishedAt *time.Time) error
 	ListByWorkflowID(ctx context.Context, workflowID uuid.UUID, limit, offset int) ([]*WorkflowRun, int, error)
+	// ListByParentRunID returns the sub-workflow runs started by a run.
+	ListByParentRunID(ctx context.Context, parentRunID uuid.UUID) ([]*WorkflowRun, error)
+	// SetOutput stores the result of a finished run.
+	SetOutput(ctx context.Context, id uuid.UUID, output map[string]interface{}) error
+	// RequestCancel records a cancellation request for an unfinished run.
+	RequestCancel(ctx context.Context, id uuid.UUID) error
+	// ListCancelRequested returns the runs among ids whose cancellation was requested.
+	ListCancelRequested(ctx context.Context, ids []uuid.UUID) ([]uuid.UUID, error)
 }
 
 type WorkflowRunService interface {
-	StartWorkflowRun(ctx context.Context, workflowID uuid.UUID) (*WorkflowRunResponse, error)
+	// StartWorkflowRun creates a pending run of a workflow with an optional input payload.
+	StartWorkflowRun(ctx context.Context, workflowID uuid.UUID, input map[string]interface{}) (*WorkflowRunResponse, error)
 	GetWorkflowRun(ctx context.Context, id uuid.UUID) (*WorkflowRunResponse, error)
 	ListWorkflowRuns(ctx context.Context, workflowID uuid.UUID, limit, offset int) ([]*WorkflowRunResponse, int, error)
 	UpdateRunStatus(ctx context.Context, id uuid.UUID, status WorkflowRunStatus) error
+	// CancelWorkflowRun and ListChildRuns report runs of workflows the user
+	// cannot access as not found.
+	CancelWorkflowRun(ctx context.Context, id uuid.UUID, userID uuid.UUID) (*WorkflowRunResponse, error)
+	ListChildRuns(ctx context.Context, id uuid.UUID, userID uuid.UUID) ([]*WorkflowRunResponse, error)
+	// WaitForRun polls a run until it has finished or ctx is done. It returns
+	// the last state of the run and whether the run finished.
+	WaitForRun(ctx context.Context, id uuid.UUID) (*WorkflowRunResponse, bool, error)
+}
+
+// RunCanceller stops runs executing in the current process.
+type RunCanceller interface {
+	// Cancel stops the run and reports whether it was running here.
+	Cancel(runID uuid.UUID) bool
 }
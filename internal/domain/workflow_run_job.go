@@ -0,0 +1,49 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrNoRunJobAvailable = errors.New("no workflow run job available")
+	ErrRunJobLeaseLost   = errors.New("workflow run job lease lost")
+)
+
+type WorkflowRunJobStatus string
+
+const (
+	WorkflowRunJobStatusQueued    WorkflowRunJobStatus = "queued"
+	WorkflowRunJobStatusLeased    WorkflowRunJobStatus = "leased"
+	WorkflowRunJobStatusCompleted WorkflowRunJobStatus = "completed"
+	WorkflowRunJobStatusFailed    WorkflowRunJobStatus = "failed"
+)
+
+// WorkflowRunJob is a queued request to execute a workflow run. Workers lease
+// jobs for a limited time and keep extending the lease while they execute the
+// run; a job whose lease expired is picked up again by another worker.
+type WorkflowRunJob struct {
+	ID             uuid.UUID            `json:"id"`
+	RunID          uuid.UUID            `json:"run_id"`
+	Status         WorkflowRunJobStatus `json:"status"`
+	Attempts       int                  `json:"attempts"`
+	LeaseOwner     string               `json:"lease_owner,omitempty"`
+	LeaseExpiresAt *time.Time           `json:"lease_expires_at,omitempty"`
+	CreatedAt      time.Time            `json:"created_at"`
+	UpdatedAt      time.Time            `json:"updated_at"`
+}
+
+type WorkflowRunJobRepository interface {
+	Enqueue(ctx context.Context, runID uuid.UUID) (*WorkflowRunJob, error)
+	// Lease claims the oldest queued job, or a job whose lease expired, and
+	// returns ErrNoRunJobAvailable when there is none.
+	Lease(ctx context.Context, owner string, lease time.Duration) (*WorkflowRunJob, error)
+	// ExtendLease returns ErrRunJobLeaseLost when owner no longer holds the job.
+	ExtendLease(ctx context.Context, id uuid.UUID, owner string, lease time.Duration) error
+	Complete(ctx context.Context, id uuid.UUID, owner string) error
+	// Fail gives up on a job whose run could not be executed.
+	Fail(ctx context.Context, id uuid.UUID, owner string) error
+}
@@ -0,0 +1,25 @@
+package domain
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// WorkflowRunNodeState is the checkpoint of a node that has settled during a
+// run. Together with the workflow graph, the states of a run are enough to
+// rebuild the engine's frontier after a restart.
+type WorkflowRunNodeState struct {
+	RunID           uuid.UUID              `json:"run_id"`
+	NodeID          uuid.UUID              `json:"node_id"`
+	Status          NodeRunLogStatus       `json:"status"`
+	TriggeredHandle string                 `json:"triggered_handle"`
+	Output          map[string]interface{} `json:"output,omitempty"`
+	UpdatedAt       time.Time              `json:"updated_at"`
+}
+
+type WorkflowRunStateRepository interface {
+	Save(ctx context.Context, state *WorkflowRunNodeState) error
+	GetByRunID(ctx context.Context, runID uuid.UUID) ([]*WorkflowRunNodeState, error)
+}
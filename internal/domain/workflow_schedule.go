@@ -0,0 +1,75 @@
+package domain
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Missed-fire policies of cron triggers. A fire time counts as missed when
+// the scheduler could not start it on time, e.g. because no instance was
+// running.
+const (
+	// MissedFirePolicySkip drops missed fire times.
+	MissedFirePolicySkip = "skip"
+	// MissedFirePolicyCatchUp starts runs for the latest missed fire times,
+	// up to the trigger's catch-up limit.
+	MissedFirePolicyCatchUp = "catch_up"
+)
+
+// CronTrigger is a cron node of a workflow together with the state of its
+// schedule.
+type CronTrigger struct {
+	NodeID         uuid.UUID
+	WorkflowID     uuid.UUID
+	WorkflowStatus WorkflowStatus
+	Data           map[string]any
+	// LastFireAt is the latest fire time that has been handled. It is set
+	// to the activation time when a schedule is first picked up, so that
+	// fire times from before that are not caught up.
+	LastFireAt *time.Time
+}
+
+// CronTriggerSchedule shows when a cron trigger fires.
+type CronTriggerSchedule struct {
+	NodeID           uuid.UUID   `json:"node_id"`
+	Expression       string      `json:"expression"`
+	Timezone         string      `json:"timezone"`
+	StartAt          *time.Time  `json:"start_at,omitempty"`
+	EndAt            *time.Time  `json:"end_at,omitempty"`
+	MissedFirePolicy string      `json:"missed_fire_policy"`
+	CatchUpLimit     int         `json:"catch_up_limit,omitempty"`
+	LastFireAt       *time.Time  `json:"last_fire_at,omitempty"`
+	NextFireTimes    []time.Time `json:"next_fire_times"`
+	// Error explains why the trigger's configuration cannot be scheduled.
+	Error string `json:"error,omitempty"`
+}
+
+// WorkflowScheduleResponse lists the cron triggers of a workflow. Only
+// published workflows are scheduled.
+type WorkflowScheduleResponse struct {
+	WorkflowID uuid.UUID              `json:"workflow_id"`
+	Active     bool                   `json:"active"`
+	Triggers   []*CronTriggerSchedule `json:"triggers"`
+}
+
+type WorkflowScheduleRepository interface {
+	// ListActive returns the cron triggers of every published workflow.
+	ListActive(ctx context.Context) ([]*CronTrigger, error)
+	ListByWorkflowID(ctx context.Context, workflowID uuid.UUID) ([]*CronTrigger, error)
+	// SetLastFire records the latest fire time handled for a trigger.
+	SetLastFire(ctx context.Context, nodeID uuid.UUID, workflowID uuid.UUID, firedAt time.Time) error
+	// ClaimFire records that a fire time of a trigger is being started and
+	// reports whether this call recorded it. Only the caller that claims a
+	// fire time may start its run.
+	ClaimFire(ctx context.Context, nodeID uuid.UUID, workflowID uuid.UUID, fireTime time.Time) (bool, error)
+	// DeleteFiresBefore removes claimed fire times older than before.
+	DeleteFiresBefore(ctx context.Context, before time.Time) error
+}
+
+type WorkflowScheduleService interface {
+	// GetWorkflowSchedule returns the next count fire times of every cron
+	// trigger of a workflow.
+	GetWorkflowSchedule(ctx context.Context, workflowID uuid.UUID, userID uuid.UUID, count int) (*WorkflowScheduleResponse, error)
+}
@@ -0,0 +1,64 @@
+package domain
+
+import (
+	"github.com/google/uuid"
+)
+
+type ValidationSeverity string
+
+const (
+	ValidationSeverityError   ValidationSeverity = "error"
+	ValidationSeverityWarning ValidationSeverity = "warning"
+)
+
+// Validation issue codes
+const (
+	ValidationCodeCycle           = "cycle"
+	ValidationCodeMissingNode     = "missing_node"
+	ValidationCodeUnknownHandle   = "unknown_handle"
+	ValidationCodeUnreachable     = "unreachable"
+	ValidationCodeMissingConfig   = "missing_config"
+	ValidationCodeUnknownType     = "unknown_type"
+	ValidationCodeUnknownTemplate = "unknown_template"
+	ValidationCodeInvalidSetting  = "invalid_setting"
+)
+
+// ValidationIssue describes a single problem found in a workflow graph. It
+// refers to the node and/or edge it was found on.
+type ValidationIssue struct {
+	Severity ValidationSeverity `json:"severity"`
+	Code     string             `json:"code"`
+	Message  string             `json:"message"`
+	NodeID   *uuid.UUID         `json:"node_id,omitempty"`
+	EdgeID   *uuid.UUID         `json:"edge_id,omitempty"`
+	Field    string             `json:"field,omitempty"`
+}
+
+// WorkflowValidationResult holds the outcome of the static checks run on a
+// workflow graph. A workflow with errors cannot be run or published;
+// warnings are informational.
+type WorkflowValidationResult struct {
+	Valid    bool              `json:"valid"`
+	Errors   []ValidationIssue `json:"errors"`
+	Warnings []ValidationIssue `json:"warnings"`
+}
+
+// Add records an issue and updates Valid accordingly.
+func (r *WorkflowValidationResult) Add(issue ValidationIssue) {
+	if issue.Severity == ValidationSeverityError {
+		r.Errors = append(r.Errors, issue)
+		r.Valid = false
+		return
+	}
+	r.Warnings = append(r.Warnings, issue)
+}
+
+// WorkflowValidationError is returned when a workflow cannot be run or
+// published because its graph has errors.
+type WorkflowValidationError struct {
+	Result *WorkflowValidationResult
+}
+
+func (e *WorkflowValidationError) Error() string {
+	return "workflow graph is invalid"
+}
@@ -0,0 +1,115 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mr-isik/loki-backend/internal/domain"
+)
+
+// ErrRunCancelled is the cancellation cause of runs stopped on request.
+var ErrRunCancelled = errors.New("workflow run cancelled")
+
+// ErrLeaseLost is the cancellation cause of runs whose job was leased by
+// another worker. Such runs are abandoned without recording anything, since
+// the other worker is resuming them.
+var ErrLeaseLost = errors.New("workflow run job lease lost")
+
+// ActiveRuns tracks the runs executing in this process together with the
+// functions that cancel them. It is safe for concurrent use.
+type ActiveRuns struct {
+	mu   sync.Mutex
+	runs map[uuid.UUID]context.CancelCauseFunc
+}
+
+// NewActiveRuns creates an empty ActiveRuns.
+func NewActiveRuns() *ActiveRuns {
+	return &ActiveRuns{
+		runs: make(map[uuid.UUID]context.CancelCauseFunc),
+	}
+}
+
+// Track registers a run and returns the context it must execute under. The
+// returned function must be called once the run has finished.
+func (a *ActiveRuns) Track(ctx context.Context, runID uuid.UUID) (context.Context, func()) {
+	runCtx, cancel := context.WithCancelCause(ctx)
+
+	a.mu.Lock()
+	a.runs[runID] = cancel
+	a.mu.Unlock()
+
+	return runCtx, func() {
+		a.mu.Lock()
+		delete(a.runs, runID)
+		a.mu.Unlock()
+		cancel(nil)
+	}
+}
+
+// Cancel stops a run executing in this process and reports whether it was found.
+func (a *ActiveRuns) Cancel(runID uuid.UUID) bool {
+	a.mu.Lock()
+	cancel, ok := a.runs[runID]
+	a.mu.Unlock()
+
+	if ok {
+		cancel(ErrRunCancelled)
+	}
+	return ok
+}
+
+// IDs returns the IDs of the runs executing in this process.
+func (a *ActiveRuns) IDs() []uuid.UUID {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	ids := make([]uuid.UUID, 0, len(a.runs))
+	for id := range a.runs {
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+// Watch polls the database for cancellation requests of runs executing in
+// this process, so that a cancel received by another instance still stops
+// the run. It returns when ctx is done.
+func (a *ActiveRuns) Watch(ctx context.Context, repo domain.WorkflowRunRepository, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		ids := a.IDs()
+		if len(ids) == 0 {
+			continue
+		}
+
+		cancelled, err := repo.ListCancelRequested(ctx, ids)
+		if err != nil {
+			log.Printf("⚠️ Failed to poll run cancellations: %v", err)
+			continue
+		}
+		for _, id := range cancelled {
+			a.Cancel(id)
+		}
+	}
+}
+
+// isCancelled reports whether ctx was cancelled through ActiveRuns.Cancel.
+func isCancelled(ctx context.Context) bool {
+	return errors.Is(context.Cause(ctx), ErrRunCancelled)
+}
+
+// isAbandoned reports whether ctx was cancelled with ErrLeaseLost.
+func isAbandoned(ctx context.Context) bool {
+	return errors.Is(context.Cause(ctx), ErrLeaseLost)
+}
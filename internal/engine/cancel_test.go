@@ -0,0 +1,130 @@
+package engine
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mr-isik/loki-backend/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestActiveRuns_Cancel(t *testing.T) {
+	runs := NewActiveRuns()
+	runID := uuid.New()
+
+	ctx, done := runs.Track(context.Background(), runID)
+	assert.Equal(t, []uuid.UUID{runID}, runs.IDs())
+	assert.False(t, runs.Cancel(uuid.New()))
+	assert.True(t, runs.Cancel(runID))
+	assert.ErrorIs(t, context.Cause(ctx), ErrRunCancelled)
+
+	done()
+	assert.Empty(t, runs.IDs())
+	assert.False(t, runs.Cancel(runID))
+}
+
+func TestActiveRuns_Watch(t *testing.T) {
+	runs := NewActiveRuns()
+	runID := uuid.New()
+	runCtx, done := runs.Track(context.Background(), runID)
+	defer done()
+
+	mockRunRepo := newMockRunRepo()
+	mockRunRepo.On("ListCancelRequested", mock.Anything, []uuid.UUID{runID}).Return([]uuid.UUID{runID}, nil)
+
+	watchCtx, stop := context.WithCancel(context.Background())
+	defer stop()
+	go runs.Watch(watchCtx, mockRunRepo, 5*time.Millisecond)
+
+	select {
+	case <-runCtx.Done():
+		assert.True(t, isCancelled(runCtx))
+	case <-time.After(time.Second):
+		t.Fatal("run was not cancelled")
+	}
+}
+
+func TestWorkflowEngine_Execute_Cancelled(t *testing.T) {
+	// Topology: Slow -> Next
+	RegisterNode("test_slow", func() domain.INodeExecutor { return &slowNode{} })
+
+	runID := uuid.New()
+	workflowID := uuid.New()
+	slowID := uuid.New()
+	nextID := uuid.New()
+
+	nodes := []domain.WorkflowNode{
+		{ID: slowID, WorkflowID: workflowID, Data: map[string]interface{}{"type": "test_slow"}},
+		{ID: nextID, WorkflowID: workflowID, Data: map[string]interface{}{"type": "set_data"}},
+	}
+	edges := []domain.WorkflowEdge{
+		{ID: uuid.New(), WorkflowID: workflowID, SourceNodeID: slowID, TargetNodeID: nextID, SourceHandle: "output", TargetHandle: "input"},
+	}
+
+	mockRunRepo := newMockRunRepo()
+	mockLogRepo := new(MockLogRepo)
+	mockRunRepo.On("UpdateStatus", mock.Anything, runID, mock.Anything, mock.Anything).Return(nil)
+	mockLogRepo.On("Create", mock.Anything, mock.Anything).Return(&domain.NodeRunLog{ID: uuid.New()}, nil)
+	mockLogRepo.On("Update", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	mockLogRepo.On("CancelUnfinished", mock.Anything, runID).Return(nil)
+
+	runs := NewActiveRuns()
+	ctx, done := runs.Track(context.Background(), runID)
+	defer done()
+
+	time.AfterFunc(20*time.Millisecond, func() { runs.Cancel(runID) })
+
+	engine := NewWorkflowEngine(nodes, edges, runID, workflowID, mockLogRepo, mockRunRepo)
+	err := engine.Execute(ctx)
+
+	assert.ErrorIs(t, err, ErrRunCancelled)
+	mockLogRepo.AssertCalled(t, "Update", mock.Anything, mock.Anything, mock.MatchedBy(func(req *domain.UpdateNodeRunLogRequest) bool {
+		return req.Status == domain.NodeRunLogStatusCancelled
+	}))
+	mockLogRepo.AssertCalled(t, "Create", mock.Anything, mock.MatchedBy(func(req *domain.CreateNodeRunLogRequest) bool {
+		return req.NodeID == nextID && req.Status == domain.NodeRunLogStatusSkipped
+	}))
+	mockLogRepo.AssertCalled(t, "CancelUnfinished", mock.Anything, runID)
+	mockRunRepo.AssertCalled(t, "UpdateStatus", mock.Anything, runID, domain.WorkflowRunStatusCancelled, mock.Anything)
+	mockRunRepo.AssertNotCalled(t, "UpdateStatus", mock.Anything, runID, domain.WorkflowRunStatusFailed, mock.Anything)
+}
+
+func TestWorkflowEngine_Execute_LeaseLost(t *testing.T) {
+	RegisterNode("test_slow", func() domain.INodeExecutor { return &slowNode{} })
+
+	runID := uuid.New()
+	workflowID := uuid.New()
+	slowID := uuid.New()
+	nextID := uuid.New()
+
+	nodes := []domain.WorkflowNode{
+		{ID: slowID, WorkflowID: workflowID, Data: map[string]interface{}{"type": "test_slow"}},
+		{ID: nextID, WorkflowID: workflowID, Data: map[string]interface{}{"type": "set_data"}},
+	}
+	edges := []domain.WorkflowEdge{
+		{ID: uuid.New(), WorkflowID: workflowID, SourceNodeID: slowID, TargetNodeID: nextID, SourceHandle: "output", TargetHandle: "input"},
+	}
+
+	mockRunRepo := newMockRunRepo()
+	mockLogRepo := new(MockLogRepo)
+	mockRunRepo.On("UpdateStatus", mock.Anything, runID, mock.Anything, mock.Anything).Return(nil)
+	mockLogRepo.On("Create", mock.Anything, mock.Anything).Return(&domain.NodeRunLog{ID: uuid.New()}, nil)
+	mockLogRepo.On("Update", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	ctx, cancel := context.WithCancelCause(context.Background())
+	defer cancel(nil)
+	time.AfterFunc(20*time.Millisecond, func() { cancel(ErrLeaseLost) })
+
+	engine := NewWorkflowEngine(nodes, edges, runID, workflowID, mockLogRepo, mockRunRepo)
+	err := engine.Execute(ctx)
+
+	// Another worker resumes the run, so nothing after the start is recorded.
+	assert.ErrorIs(t, err, ErrLeaseLost)
+	mockLogRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything, mock.Anything)
+	mockRunRepo.AssertNotCalled(t, "UpdateStatus", mock.Anything, runID, domain.WorkflowRunStatusFailed, mock.Anything)
+	mockRunRepo.AssertNotCalled(t, "UpdateStatus", mock.Anything, runID, domain.WorkflowRunStatusCancelled, mock.Anything)
+	mockRunRepo.AssertNotCalled(t, "UpdateStatus", mock.Anything, runID, domain.WorkflowRunStatusCompleted, mock.Anything)
+}
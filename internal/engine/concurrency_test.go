@@ -0,0 +1,147 @@
+package engine
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mr-isik/loki-backend/internal/domain"
+	"github.com/mr-isik/loki-backend/internal/engine/nodes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// countingNode records how many of its executions overlap.
+type countingNode struct {
+	running int32
+	peak    int32
+	calls   int32
+}
+
+func (n *countingNode) Execute(ctx context.Context, rawData []byte) (*domain.NodeResult, error) {
+	atomic.AddInt32(&n.calls, 1)
+	running := atomic.AddInt32(&n.running, 1)
+	for {
+		peak := atomic.LoadInt32(&n.peak)
+		if running <= peak || atomic.CompareAndSwapInt32(&n.peak, peak, running) {
+			break
+		}
+	}
+	time.Sleep(10 * time.Millisecond)
+	atomic.AddInt32(&n.running, -1)
+	return &domain.NodeResult{Status: "completed", OutputData: map[string]interface{}{}}, nil
+}
+
+func newConcurrencyTestEngine(nodes []domain.WorkflowNode, edges []domain.WorkflowEdge) *WorkflowEngine {
+	runID := uuid.New()
+
+	mockRunRepo := newMockRunRepo()
+	mockLogRepo := new(MockLogRepo)
+	mockRunRepo.On("UpdateStatus", mock.Anything, runID, mock.Anything, mock.Anything).Return(nil)
+	mockLogRepo.On("Create", mock.Anything, mock.Anything).Return(&domain.NodeRunLog{ID: uuid.New()}, nil)
+	mockLogRepo.On("Update", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	return NewWorkflowEngine(nodes, edges, runID, uuid.New(), mockLogRepo, mockRunRepo)
+}
+
+func parallelNodes(typeKey string, count int) []domain.WorkflowNode {
+	nodes := make([]domain.WorkflowNode, count)
+	for i := range nodes {
+		nodes[i] = domain.WorkflowNode{ID: uuid.New(), Data: map[string]interface{}{"type": typeKey}}
+	}
+	return nodes
+}
+
+func TestWorkflowEngine_Execute_MaxParallelism(t *testing.T) {
+	node := &countingNode{}
+	RegisterNode("test_counting_run", func() domain.INodeExecutor { return node })
+
+	engine := newConcurrencyTestEngine(parallelNodes("test_counting_run", 6), nil)
+	engine.Settings.MaxParallelism = 2
+
+	err := engine.Execute(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, int32(6), atomic.LoadInt32(&node.calls))
+	assert.LessOrEqual(t, atomic.LoadInt32(&node.peak), int32(2))
+}
+
+func TestWorkflowEngine_Execute_NodeSlots(t *testing.T) {
+	node := &countingNode{}
+	RegisterNode("test_counting_global", func() domain.INodeExecutor { return node })
+
+	engine := newConcurrencyTestEngine(parallelNodes("test_counting_global", 4), nil)
+	engine.Config.NodeSlots = NewSemaphore(1)
+
+	err := engine.Execute(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, int32(4), atomic.LoadInt32(&node.calls))
+	assert.Equal(t, int32(1), atomic.LoadInt32(&node.peak))
+}
+
+// inProcessRunner runs sub-workflows to completion within the calling node,
+// as the execution service does for waiting execute_workflow nodes.
+type inProcessRunner struct {
+	config Config
+	nodes  []domain.WorkflowNode
+}
+
+func (r *inProcessRunner) RunSubWorkflow(ctx context.Context, req *domain.SubWorkflowRequest) (*domain.SubWorkflowResult, error) {
+	child := newConcurrencyTestEngine(r.nodes, nil)
+	child.Config = r.config
+	if err := child.Execute(ctx); err != nil {
+		return nil, err
+	}
+	return &domain.SubWorkflowResult{RunID: child.RunID, Status: domain.WorkflowRunStatusCompleted, Output: child.Output()}, nil
+}
+
+func TestWorkflowEngine_Execute_NodeSlotsWithSubWorkflow(t *testing.T) {
+	config := DefaultConfig()
+	config.NodeSlots = NewSemaphore(1)
+	runner := &inProcessRunner{config: config, nodes: parallelNodes("set_data", 2)}
+	RegisterNode("execute_workflow", func() domain.INodeExecutor { return &nodes.ExecuteWorkflowNode{Runner: runner} })
+
+	// The parent holds no slot while it waits, so its child can run.
+	parent := newConcurrencyTestEngine([]domain.WorkflowNode{
+		{ID: uuid.New(), Data: map[string]interface{}{"type": "execute_workflow", "workflow_id": uuid.NewString()}},
+	}, nil)
+	parent.Config = config
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	err := parent.Execute(ctx)
+
+	assert.NoError(t, err)
+	assert.NoError(t, ctx.Err())
+}
+
+func TestWorkflowEngine_Execute_LoopConcurrency(t *testing.T) {
+	node := &countingNode{}
+	RegisterNode("test_counting_loop", func() domain.INodeExecutor { return node })
+
+	loopID := uuid.New()
+	bodyID := uuid.New()
+	nodes := []domain.WorkflowNode{
+		{ID: loopID, Data: map[string]interface{}{
+			"type":        "loop",
+			"items":       []interface{}{1, 2, 3, 4, 5, 6, 7, 8},
+			"concurrency": 3,
+			"batch_size":  4,
+		}},
+		{ID: bodyID, Data: map[string]interface{}{"type": "test_counting_loop"}},
+	}
+	edges := []domain.WorkflowEdge{
+		{ID: uuid.New(), SourceNodeID: loopID, TargetNodeID: bodyID, SourceHandle: "output_item", TargetHandle: "input"},
+	}
+
+	engine := newConcurrencyTestEngine(nodes, edges)
+
+	err := engine.Execute(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, int32(8), atomic.LoadInt32(&node.calls))
+	assert.LessOrEqual(t, atomic.LoadInt32(&node.peak), int32(3))
+}
@@ -0,0 +1,83 @@
+package engine
+
+import (
+	"time"
+
+	"github.com/mr-isik/loki-backend/internal/domain"
+)
+
+// Config holds server-wide execution limits shared by every engine.
+type Config struct {
+	// DefaultNodeTimeout applies to nodes that do not set their own timeout.
+	DefaultNodeTimeout time.Duration
+	// MaxNodeTimeout caps the timeout a node may request.
+	MaxNodeTimeout time.Duration
+	// MaxRunDuration caps the total duration of a run. Workflows may ask for less.
+	MaxRunDuration time.Duration
+	// DefaultLoopConcurrency limits the iterations a loop runs at the same
+	// time when the loop does not set its own concurrency.
+	DefaultLoopConcurrency int
+	// NodeSlots limits the nodes executing at the same time across every run
+	// of the process. It is shared by every engine built from this Config.
+	NodeSlots *Semaphore
+	// MaxWorkflowDepth limits how deeply execute_workflow nodes may nest
+	// sub-workflow runs, which stops runaway recursion.
+	MaxWorkflowDepth int
+	// MaxLogPayloadBytes limits the size of the node input and output stored
+	// in run logs. Larger payloads are replaced by a truncation marker; zero
+	// disables payload logging.
+	MaxLogPayloadBytes int
+	// PinnedOutputs lets nodes replace their execution with pinned output
+	// data. Production deployments should disable it.
+	PinnedOutputs bool
+}
+
+// DefaultConfig returns the limits used when nothing else is configured.
+func DefaultConfig() Config {
+	return Config{
+		DefaultNodeTimeout:     10 * time.Second,
+		MaxNodeTimeout:         time.Hour,
+		MaxRunDuration:         24 * time.Hour,
+		DefaultLoopConcurrency: 10,
+		MaxWorkflowDepth:       10,
+		MaxLogPayloadBytes:     64 * 1024,
+		PinnedOutputs:          true,
+	}
+}
+
+// NodeTimeout returns the effective timeout for a node.
+func (c Config) NodeTimeout(settings NodeSettings) time.Duration {
+	timeout := c.DefaultNodeTimeout
+	if settings.TimeoutSeconds > 0 {
+		timeout = time.Duration(settings.TimeoutSeconds) * time.Second
+	}
+	if c.MaxNodeTimeout > 0 && timeout > c.MaxNodeTimeout {
+		timeout = c.MaxNodeTimeout
+	}
+	return timeout
+}
+
+// RunTimeout returns the effective maximum duration of a run, or 0 when runs
+// are unbounded.
+func (c Config) RunTimeout(settings domain.WorkflowSettings) time.Duration {
+	timeout := c.MaxRunDuration
+	if settings.TimeoutSeconds > 0 {
+		requested := time.Duration(settings.TimeoutSeconds) * time.Second
+		if timeout <= 0 || requested < timeout {
+			timeout = requested
+		}
+	}
+	return timeout
+}
+
+// LoopConcurrency returns how many iterations of a loop may run at the same
+// time. A requested concurrency of 0 uses the default.
+func (c Config) LoopConcurrency(requested int) int {
+	if requested > 0 {
+		return requested
+	}
+	if c.DefaultLoopConcurrency > 0 {
+		return c.DefaultLoopConcurrency
+	}
+	return 1
+}
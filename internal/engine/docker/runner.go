@@ -100,6 +100,13 @@ func (r *Runner) RunContainer(ctx context.Context, req RunRequest) (string, erro
 		defer hj.Close()
 	}
 
+	// Kill the container as soon as the context is done so that cancelled or
+	// timed out runs do not leave it running in the background.
+	stopKill := context.AfterFunc(ctx, func() {
+		_ = r.cli.ContainerKill(context.Background(), containerID, "SIGKILL")
+	})
+	defer stopKill()
+
 	// 4. Start the container
 	if err := r.cli.ContainerStart(ctx, containerID, types.ContainerStartOptions{}); err != nil {
 		return "", fmt.Errorf("failed to start container: %w", err)
@@ -141,6 +148,10 @@ func (r *Runner) RunContainer(ctx context.Context, req RunRequest) (string, erro
 		fmt.Printf("warning: error reading container logs: %v\n", err)
 	}
 
+	if ctx.Err() != nil {
+		return "", ctx.Err()
+	}
+
 	// 7. Check wait result
 	select {
 	case err := <-errCh:
@@ -158,9 +169,7 @@ func (r *Runner) RunContainer(ctx context.Context, req RunRequest) (string, erro
 			stdoutStr := stdoutBuf.String()
 			return "", fmt.Errorf("container exited with status code %d\nStdout: %s\nStderr: %s", status.StatusCode, stdoutStr, stderrStr)
 		}
-	case <-ctx.Done(): // Context cancelled (timeout)
-		// We should try to kill it if ctx is cancelled, though AutoRemove might not trigger if it's forcefully killed mid-run without careful cleanup.
-		// For now we'll just return the context error.
+	case <-ctx.Done(): // Context cancelled (timeout); the container is killed above.
 		return "", ctx.Err()
 	}
 
@@ -5,6 +5,7 @@ import (
 "encoding/json"
 "errors"
 "fmt"
+"log"
 "sync"
 "time"
 
@@ -17,12 +18,29 @@ type WorkflowEngine struct {
 	Nodes      map[uuid.UUID]domain.WorkflowNode
 	Edges      []domain.WorkflowEdge
 	RunID      uuid.UUID
-	LogRepo    domain.NodeRunLogRepository
-	RunRepo    domain.WorkflowRunRepository
 	WorkflowID uuid.UUID
 
+	// Config holds the server-wide execution limits.
+	Config Config
+	// Settings holds the workflow-wide execution options.
+	Settings domain.WorkflowSettings
+	// StateRepo stores node checkpoints so that the run can be resumed. It
+	// is optional; without it nothing is checkpointed.
+	StateRepo domain.WorkflowRunStateRepository
+	// Input is the payload the run was started with. Trigger nodes output
+	// it and expressions read it as $run.input.
+	Input map[string]interface{}
+	// UsePinnedOutputs makes nodes with an enabled pinned output emit it
+	// instead of executing. It is off for published workflows.
+	UsePinnedOutputs bool
+
+	// observers receive the lifecycle events of the run.
+	observers []RunObserver
+
 	nodeOutputs map[uuid.UUID]map[string]interface{}
-	mu          sync.RWMutex
+	// variables holds the run variables. Only the root engine uses it.
+	variables map[string]interface{}
+	mu        sync.RWMutex
 
 	// Parallel execution state
 	depMu            sync.Mutex
@@ -30,9 +48,21 @@ type WorkflowEngine struct {
 	nodeErrors       []error
 	triggeredHandles map[uuid.UUID]string // sourceNodeID → triggeredHandle
 
+	// runSlots limits the nodes of this run executing at the same time. It
+	// is shared with the sub-engines of loops.
+	runSlots *Semaphore
+
 	isSubEngine bool
+	parent      *WorkflowEngine
+	// iteration is the index of the loop iteration a sub-engine runs.
+	iteration *int
+	// lastOutput is the output of the node that finished last.
+	lastOutput map[string]interface{}
 }
 
+// NewWorkflowEngine creates an engine for a run. When both repositories are
+// given, a RepositoryObserver records the run in them; other observers can
+// be added with AddObserver.
 func NewWorkflowEngine(
 nodes []domain.WorkflowNode,
 edges []domain.WorkflowEdge,
@@ -46,23 +76,62 @@ runRepo domain.WorkflowRunRepository,
 		nodeMap[node.ID] = node
 	}
 
-	return &WorkflowEngine{
+	e := &WorkflowEngine{
 		Nodes:            nodeMap,
 		Edges:            edges,
 		RunID:            runID,
 		WorkflowID:       workflowID,
-		LogRepo:          logRepo,
-		RunRepo:          runRepo,
+		Config:           DefaultConfig(),
 		nodeOutputs:      make(map[uuid.UUID]map[string]interface{}),
+		variables:        make(map[string]interface{}),
 		triggeredHandles: make(map[uuid.UUID]string),
 	}
+	if logRepo != nil && runRepo != nil {
+		e.AddObserver(NewRepositoryObserver(logRepo, runRepo))
+	}
+	return e
 }
 
 // Execute runs the workflow DAG with parallel execution of independent nodes.
 func (e *WorkflowEngine) Execute(ctx context.Context) error {
+	return e.execute(ctx, nil)
+}
+
+// Resume continues an interrupted run from its checkpoints. Nodes that had
+// settled are restored instead of executed again; nodes that were running
+// when the run was interrupted are executed again.
+func (e *WorkflowEngine) Resume(ctx context.Context) error {
+	if e.StateRepo == nil {
+		return errors.New("resuming a run requires a state repository")
+	}
+
+	states, err := e.StateRepo.GetByRunID(ctx, e.RunID)
+	if err != nil {
+		return e.failRun(ctx, fmt.Sprintf("failed to load checkpoints: %v", err))
+	}
+
+	checkpoints := make(map[uuid.UUID]*domain.WorkflowRunNodeState, len(states))
+	for _, state := range states {
+		checkpoints[state.NodeID] = state
+	}
+
+	return e.execute(ctx, checkpoints)
+}
+
+func (e *WorkflowEngine) execute(ctx context.Context, checkpoints map[uuid.UUID]*domain.WorkflowRunNodeState) error {
 	if !e.isSubEngine {
-		if err := e.RunRepo.UpdateStatus(ctx, e.RunID, domain.WorkflowRunStatusRunning, nil); err != nil {
-			return fmt.Errorf("failed to start run: %w", err)
+		if err := e.notifyRunStart(ctx, checkpoints != nil); err != nil {
+			return e.failRun(ctx, fmt.Sprintf("failed to start run: %v", err))
+		}
+	}
+
+	runCtx := ctx
+	if !e.isSubEngine {
+		e.runSlots = NewSemaphore(e.Settings.MaxParallelism)
+		if timeout := e.Config.RunTimeout(e.Settings); timeout > 0 {
+			var cancel context.CancelFunc
+			runCtx, cancel = context.WithTimeout(ctx, timeout)
+			defer cancel()
 		}
 	}
 
@@ -75,20 +144,100 @@ func (e *WorkflowEngine) Execute(ctx context.Context) error {
 		return e.failRun(ctx, "No start nodes found")
 	}
 
+	// Nodes on a cycle would never become ready and stay unprocessed.
+	if e.hasCycle() {
+		return e.failRun(ctx, "workflow graph contains a cycle")
+	}
+
 	var wg sync.WaitGroup
 
+	// liveInputs counts the incoming edges of each node that were taken.
+	liveInputs := make(map[uuid.UUID]int)
+	// settled records the nodes that were processed or skipped.
+	settled := make(map[uuid.UUID]bool)
+	silentNodes := e.loopBodyNodes()
+
+	var scheduleNode, settleSkipped func(nodeID uuid.UUID)
+
+	// settleEdges resolves every outgoing edge of a finished or skipped node.
+	// A target becomes ready once all of its incoming edges are settled: it
+	// runs when at least one of them was taken and is skipped otherwise.
+	var settleEdges func(nodeID uuid.UUID, triggeredHandle string, taken bool)
+	settleEdges = func(nodeID uuid.UUID, triggeredHandle string, taken bool) {
+		for _, edge := range e.Edges {
+			if edge.SourceNodeID != nodeID {
+				continue
+			}
+			// Only consider targets that exist in the engine (important for subengines)
+			if _, exists := e.Nodes[edge.TargetNodeID]; !exists {
+				continue
+			}
+
+			e.depMu.Lock()
+			if taken && isEdgeTaken(edge, triggeredHandle) {
+				liveInputs[edge.TargetNodeID]++
+			}
+			inDegree[edge.TargetNodeID]--
+			ready := inDegree[edge.TargetNodeID] == 0
+			live := liveInputs[edge.TargetNodeID] > 0
+			e.depMu.Unlock()
+
+			if !ready {
+				continue
+			}
+			if live {
+				scheduleNode(edge.TargetNodeID)
+				continue
+			}
+			settleSkipped(edge.TargetNodeID)
+		}
+	}
+
+	// settleSkipped records a node that does not run and skips the nodes
+	// that only it leads to.
+	settleSkipped = func(nodeID uuid.UUID) {
+		e.depMu.Lock()
+		settled[nodeID] = true
+		e.depMu.Unlock()
+		if _, restored := checkpoints[nodeID]; !restored && !silentNodes[nodeID] {
+			e.skipNode(runCtx, nodeID)
+			// Skips follow from the checkpoints of upstream nodes, so a
+			// resumed run recomputes a skip whose checkpoint was lost.
+			if err := e.checkpoint(runCtx, nodeID, domain.NodeRunLogStatusSkipped, ""); err != nil {
+				log.Printf("⚠️ %v", err)
+			}
+		}
+		settleEdges(nodeID, "", false)
+	}
+
 	// scheduleNode launches a goroutine to process a single node.
-	var scheduleNode func(nodeID uuid.UUID)
 	scheduleNode = func(nodeID uuid.UUID) {
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
 
-			if ctx.Err() != nil {
+			if runCtx.Err() != nil {
 				return
 			}
 
-			triggeredHandle, err := e.processNode(ctx, nodeID)
+			e.depMu.Lock()
+			settled[nodeID] = true
+			e.depMu.Unlock()
+
+			if state, ok := checkpoints[nodeID]; ok && state.Status != domain.NodeRunLogStatusSkipped {
+				e.mu.Lock()
+				e.nodeOutputs[nodeID] = state.Output
+				e.mu.Unlock()
+				e.restoreVariable(e.Nodes[nodeID], state.Output)
+				e.depMu.Lock()
+				e.triggeredHandles[nodeID] = state.TriggeredHandle
+				e.depMu.Unlock()
+
+				settleEdges(nodeID, state.TriggeredHandle, true)
+				return
+			}
+
+			triggeredHandle, err := e.processNode(runCtx, nodeID)
 			
 			node := e.Nodes[nodeID]
 			nodeType := ""
@@ -97,12 +246,16 @@ func (e *WorkflowEngine) Execute(ctx context.Context) error {
 			}
 
 			// Sub-Workflow execution for loops
-			if err == nil && nodeType == "loop" {
-				err = e.executeLoop(ctx, nodeID)
+			if err == nil && nodeType == "loop" && triggeredHandle != errorHandle {
+				// A pinned loop stands in for all of its iterations.
+				if e.pinnedOutput(parseNodeSettings(node)) == nil {
+					err = e.executeLoop(runCtx, nodeID)
+				}
 				if err != nil {
 					e.errMu.Lock()
 					e.nodeErrors = append(e.nodeErrors, fmt.Errorf("loop iteration failed at node %s: %w", nodeID, err))
 					e.errMu.Unlock()
+					settleEdges(nodeID, "", false)
 					return
 				}
 				// Force the main engine to continue ONLY along output_done branch
@@ -111,6 +264,7 @@ func (e *WorkflowEngine) Execute(ctx context.Context) error {
 				e.errMu.Lock()
 				e.nodeErrors = append(e.nodeErrors, fmt.Errorf("node %s failed: %w", nodeID, err))
 				e.errMu.Unlock()
+				settleEdges(nodeID, "", false)
 				return
 			}
 
@@ -118,47 +272,69 @@ func (e *WorkflowEngine) Execute(ctx context.Context) error {
 			e.triggeredHandles[nodeID] = triggeredHandle
 			e.depMu.Unlock()
 
-			nextNodes := e.findNextNodes(nodeID, triggeredHandle)
-
-			for _, nextID := range nextNodes {
-				ready := false
+			e.mu.Lock()
+			e.lastOutput = e.nodeOutputs[nodeID]
+			e.mu.Unlock()
 
-				e.depMu.Lock()
-				inDegree[nextID]--
-				if inDegree[nextID] == 0 {
-					ready = true
-				}
-				e.depMu.Unlock()
-
-				if ready {
-					scheduleNode(nextID)
-				}
+			// A node whose output cannot be saved would run again on resume,
+			// so the run fails rather than continuing without a checkpoint.
+			if err := e.checkpoint(runCtx, nodeID, domain.NodeRunLogStatusCompleted, triggeredHandle); err != nil {
+				e.errMu.Lock()
+				e.nodeErrors = append(e.nodeErrors, err)
+				e.errMu.Unlock()
+				settleEdges(nodeID, "", false)
+				return
 			}
+			settleEdges(nodeID, triggeredHandle, true)
 		}()
 	}
 
+	// A run fired by a trigger starts at that trigger only; the other start
+	// nodes, and the branches only they lead to, are skipped.
+	triggerID, hasTrigger := e.triggerNodeID(startNodes)
 	for _, nodeID := range startNodes {
+		if hasTrigger && nodeID != triggerID {
+			settleSkipped(nodeID)
+			continue
+		}
 		scheduleNode(nodeID)
 	}
 
 	wg.Wait()
 
+	if isAbandoned(ctx) {
+		return ErrLeaseLost
+	}
+
+	if isCancelled(ctx) {
+		return e.cancelRun(ctx, settled, silentNodes)
+	}
+
+	if errors.Is(runCtx.Err(), context.DeadlineExceeded) && ctx.Err() == nil {
+		e.errMu.Lock()
+		if len(e.nodeErrors) == 0 {
+			e.nodeErrors = append(e.nodeErrors, fmt.Errorf("workflow run exceeded its maximum duration of %s", e.Config.RunTimeout(e.Settings)))
+		}
+		e.errMu.Unlock()
+	}
+
 	e.errMu.Lock()
 	errs := make([]error, len(e.nodeErrors))
 	copy(errs, e.nodeErrors)
 	e.errMu.Unlock()
 
 	if len(errs) > 0 {
+		runErr := errors.Join(errs...)
 		if !e.isSubEngine {
-			now := time.Now()
-			e.RunRepo.UpdateStatus(ctx, e.RunID, domain.WorkflowRunStatusFailed, &now)
+			if err := e.notifyRunFinish(ctx, domain.WorkflowRunStatusFailed, nil, runErr); err != nil {
+				log.Printf("⚠️ Failed to fail run %s: %v", e.RunID, err)
+			}
 		}
-		return errors.Join(errs...)
+		return runErr
 	}
 
 	if !e.isSubEngine {
-		now := time.Now()
-		if err := e.RunRepo.UpdateStatus(ctx, e.RunID, domain.WorkflowRunStatusCompleted, &now); err != nil {
+		if err := e.notifyRunFinish(ctx, domain.WorkflowRunStatusCompleted, e.Output(), nil); err != nil {
 			return fmt.Errorf("failed to complete run: %w", err)
 		}
 	}
@@ -166,6 +342,34 @@ func (e *WorkflowEngine) Execute(ctx context.Context) error {
 	return nil
 }
 
+// cancelRun finalizes a run stopped through ActiveRuns.Cancel: interrupted
+// node logs are marked cancelled and nodes that never started are skipped.
+func (e *WorkflowEngine) cancelRun(ctx context.Context, settled, silentNodes map[uuid.UUID]bool) error {
+	if e.isSubEngine {
+		return ErrRunCancelled
+	}
+
+	persistCtx := context.WithoutCancel(ctx)
+	e.depMu.Lock()
+	var pending []uuid.UUID
+	for id := range e.Nodes {
+		if !settled[id] && !silentNodes[id] {
+			pending = append(pending, id)
+		}
+	}
+	e.depMu.Unlock()
+
+	for _, id := range pending {
+		e.skipNode(persistCtx, id)
+	}
+
+	if err := e.notifyRunFinish(persistCtx, domain.WorkflowRunStatusCancelled, nil, ErrRunCancelled); err != nil {
+		return fmt.Errorf("failed to cancel run: %w", err)
+	}
+
+	return ErrRunCancelled
+}
+
 func (e *WorkflowEngine) buildInDegreeMap() map[uuid.UUID]int {
 	inDegree := make(map[uuid.UUID]int)
 
@@ -190,19 +394,12 @@ func (e *WorkflowEngine) processNode(ctx context.Context, nodeID uuid.UUID) (str
 		return "", fmt.Errorf("node %s not found", nodeID)
 	}
 
-	logEntry, err := e.LogRepo.Create(ctx, &domain.CreateNodeRunLogRequest{
-		RunID:  e.RunID,
-		NodeID: nodeID,
-		Status: domain.NodeRunLogStatusRunning,
-	})
-	if err != nil {
-		return "", fmt.Errorf("failed to create log: %w", err)
-	}
-
-	inputData := make(map[string]interface{})
+	settings := parseNodeSettings(node)
+	pin := e.pinnedOutput(settings)
 
-	for k, v := range node.Data {
-		inputData[k] = v
+	event, err := e.startNode(ctx, node, pin != nil)
+	if err != nil {
+		return "", err
 	}
 
 	incomingEdges := e.getIncomingEdges(nodeID)
@@ -221,39 +418,134 @@ func (e *WorkflowEngine) processNode(ctx context.Context, nodeID uuid.UUID) (str
 	}
 	e.mu.RUnlock()
 
+	if pin != nil {
+		return e.emitPinnedOutput(ctx, event, pin, inputsFromUpstream), nil
+	}
+
+	resolvedData, err := resolveNodeData(node.Data, e.expressionScope(inputsFromUpstream))
+	if err != nil {
+		e.finishNode(ctx, event, domain.NodeRunLogStatusFailed, "", err.Error(), nil, nil)
+		return e.handleNodeFailure(nodeID, settings, map[string]interface{}{"error": err.Error()},
+			fmt.Errorf("failed to resolve expressions: %w", err))
+	}
+
+	inputData := make(map[string]interface{})
+	for k, v := range resolvedData {
+		inputData[k] = v
+	}
+
 	inputData["input"] = inputsFromUpstream
 
 	typeVal, ok := node.Data["type"]
 	if !ok {
-		return "", fmt.Errorf("node type not found in data for node %s", nodeID)
+		err := fmt.Errorf("node type not found in data for node %s", nodeID)
+		e.finishNode(ctx, event, domain.NodeRunLogStatusFailed, "", err.Error(), inputData, nil)
+		return "", err
 	}
 	nodeType, ok := typeVal.(string)
 	if !ok {
-		return "", fmt.Errorf("invalid node type format for node %s", nodeID)
+		err := fmt.Errorf("invalid node type format for node %s", nodeID)
+		e.finishNode(ctx, event, domain.NodeRunLogStatusFailed, "", err.Error(), inputData, nil)
+		return "", err
 	}
 
 	executor, err := NewNodeExecutor(nodeType)
 	if err != nil {
-		e.updateLog(ctx, logEntry.ID, domain.NodeRunLogStatusFailed, "", err.Error())
-		return "", err
+		e.finishNode(ctx, event, domain.NodeRunLogStatusFailed, "", err.Error(), inputData, nil)
+		return e.handleNodeFailure(nodeID, settings, map[string]interface{}{"error": err.Error()}, err)
+	}
+
+	if _, ok := executor.(domain.ITriggerNode); ok {
+		inputData["trigger"] = e.Input
 	}
 
 	jsonData, _ := json.Marshal(inputData)
-	
-	timeoutCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
-	defer cancel()
 
-	result, err := executor.Execute(timeoutCtx, jsonData)
+	nodeTimeout := e.Config.NodeTimeout(settings)
+	// A sub-workflow is bounded by its own run rather than the default node timeout.
+	if nodeType == "execute_workflow" && settings.TimeoutSeconds == 0 && e.Config.MaxNodeTimeout > 0 {
+		nodeTimeout = e.Config.MaxNodeTimeout
+	}
+	policy := settings.Retry
+	maxAttempts := policy.Attempts()
+
+	execCtx := domain.WithNodeRunInfo(ctx, domain.NodeRunInfo{
+		RunID:      e.RunID,
+		WorkflowID: e.WorkflowID,
+		NodeID:     nodeID,
+	})
+	execCtx = domain.WithRunVariables(execCtx, e)
+
+	var result *domain.NodeResult
+	var timedOut bool
+	for attempt := 1; ; attempt++ {
+		release, slotErr := e.acquireSlots(ctx, nodeType)
+		if slotErr != nil {
+			result, timedOut, err = nil, errors.Is(slotErr, context.DeadlineExceeded), slotErr
+			break
+		}
+
+		startedAt := time.Now()
+		result, timedOut, err = e.runExecutor(execCtx, executor, jsonData, nodeTimeout)
+		release()
+
+		if maxAttempts > 1 {
+			record := attemptRecord(attempt, startedAt, result, err, timedOut)
+			event.Attempts = append(event.Attempts, record)
+			if notifyErr := e.notifyNodeAttempt(ctx, event, record); notifyErr != nil {
+				log.Printf("⚠️ Failed to record attempt: %v", notifyErr)
+			}
+		}
+
+		failed := err != nil || result.Status == "failed"
+		if !failed || attempt >= maxAttempts || ctx.Err() != nil {
+			break
+		}
+		if !policy.ShouldRetry(classifyFailure(err, failureMessage(result, err), timedOut)) {
+			break
+		}
+		if sleepContext(ctx, policy.Delay(attempt)) != nil {
+			break
+		}
+	}
+
+	// Nodes such as http_request report a cancelled request as a failed
+	// result; treat it as a timeout or cancellation where appropriate.
+	if err == nil && result.Status == "failed" {
+		if timedOut {
+			err = context.DeadlineExceeded
+		} else if isCancelled(ctx) {
+			err = ErrRunCancelled
+		}
+	}
+
+	if err != nil && !timedOut && isCancelled(ctx) {
+		e.finishNode(ctx, event, domain.NodeRunLogStatusCancelled, "", "Execution cancelled.", inputData, nil)
+		return "", ErrRunCancelled
+	}
 
 	if err != nil {
 		sanitizedErr := utils.SanitizeError(err)
-		
-		if errors.Is(timeoutCtx.Err(), context.DeadlineExceeded) {
-			sanitizedErr = "Execution timed out after 10 seconds."
+		status := domain.NodeRunLogStatusFailed
+
+		if timedOut {
+			status = domain.NodeRunLogStatusTimedOut
+			sanitizedErr = fmt.Sprintf("Execution timed out after %s.", nodeTimeout)
+			if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				sanitizedErr = fmt.Sprintf("Workflow run exceeded its maximum duration of %s.", e.Config.RunTimeout(e.Settings))
+			}
 		}
 
-		e.updateLog(ctx, logEntry.ID, domain.NodeRunLogStatusFailed, "", sanitizedErr)
-		return "", errors.New(sanitizedErr)
+		output := make(map[string]interface{})
+		if result != nil {
+			for k, v := range result.OutputData {
+				output[k] = v
+			}
+		}
+		output["error"] = sanitizedErr
+
+		e.finishNode(ctx, event, status, "", sanitizedErr, inputData, output)
+		return e.handleNodeFailure(nodeID, settings, output, errors.New(sanitizedErr))
 	}
 
 	e.mu.Lock()
@@ -265,78 +557,165 @@ func (e *WorkflowEngine) processNode(ctx context.Context, nodeID uuid.UUID) (str
 		status = domain.NodeRunLogStatusFailed
 	}
 
-	if err := e.updateLog(ctx, logEntry.ID, status, result.Log, ""); err != nil {
-		fmt.Printf("failed to update log: %v\n", err)
+	event.TriggeredHandle = result.TriggeredHandle
+	if err := e.finishNode(ctx, event, status, result.Log, "", inputData, result.OutputData); err != nil {
+		log.Printf("⚠️ Failed to update log: %v", err)
 	}
 
 	if result.Status == "failed" {
-		return "", fmt.Errorf("node execution failed")
+		output := result.OutputData
+		if _, ok := output["error"]; !ok {
+			output = make(map[string]interface{}, len(result.OutputData)+1)
+			for k, v := range result.OutputData {
+				output[k] = v
+			}
+			output["error"] = result.Log
+		}
+		return e.handleNodeFailure(nodeID, settings, output, fmt.Errorf("node execution failed"))
 	}
 
 	return result.TriggeredHandle, nil
 }
 
-func (e *WorkflowEngine) executeLoop(ctx context.Context, loopNodeID uuid.UUID) error {
-	e.mu.RLock()
-	loopOutputs := e.nodeOutputs[loopNodeID]
-	e.mu.RUnlock()
-
-	itemsInterface, ok := loopOutputs["items"]
-	if !ok {
+// pinnedOutput returns the pinned output to emit instead of executing a node,
+// or nil when the node is executed.
+func (e *WorkflowEngine) pinnedOutput(settings NodeSettings) *PinnedOutput {
+	if !e.UsePinnedOutputs || settings.Pin == nil || !settings.Pin.Enabled {
 		return nil
 	}
+	return settings.Pin
+}
 
-	items, err := toSliceInterface(itemsInterface)
-	if err != nil {
-		return fmt.Errorf("invalid items type: %v", err)
+// emitPinnedOutput settles a node with its pinned output and returns the
+// handle to continue along.
+func (e *WorkflowEngine) emitPinnedOutput(ctx context.Context, event NodeEvent, pin *PinnedOutput, inputs map[string]interface{}) string {
+	output := pin.OutputData
+	if output == nil {
+		output = make(map[string]interface{})
 	}
 
-	subNodes, subEdges := e.getSubgraph(loopNodeID, "output_item")
-	if len(subNodes) == 0 {
-		return nil 
+	e.mu.Lock()
+	e.nodeOutputs[event.NodeID] = output
+	e.mu.Unlock()
+
+	inputData := map[string]interface{}{"input": inputs}
+	event.TriggeredHandle = pin.TriggeredHandle
+	if err := e.finishNode(ctx, event, domain.NodeRunLogStatusCompleted, "Pinned output used; the node was not executed.", "", inputData, output); err != nil {
+		log.Printf("⚠️ Failed to update log: %v", err)
 	}
 
-	var wg sync.WaitGroup
-	errCh := make(chan error, len(items))
+	return pin.TriggeredHandle
+}
 
-	for i, item := range items {
-		wg.Add(1)
-		go func(index int, loopItem interface{}) {
-			defer wg.Done()
-			
-			var nodesList []domain.WorkflowNode
-			for _, n := range subNodes {
-				nodesList = append(nodesList, n)
-			}
+// handleNodeFailure applies the node's on-error policy. It returns the handle
+// to continue along when the failure is handled, or err when the failure
+// should fail the run. Handled failures expose output as the node's output.
+func (e *WorkflowEngine) handleNodeFailure(nodeID uuid.UUID, settings NodeSettings, output map[string]interface{}, err error) (string, error) {
+	var handle string
+	switch e.onErrorPolicy(nodeID, settings) {
+	case OnErrorContinueErrorOutput:
+		handle = errorHandle
+	case OnErrorContinueRegularOutput:
+		// Without a triggered handle every regular branch would run, so a
+		// branching node stops instead.
+		if e.branchCount(nodeID) > 1 {
+			return "", fmt.Errorf("%w (continue_regular_output is not supported on branching nodes)", err)
+		}
+		handle = ""
+	default:
+		return "", err
+	}
 
-			subEngine := NewWorkflowEngine(nodesList, subEdges, e.RunID, e.WorkflowID, e.LogRepo, e.RunRepo)
-			subEngine.isSubEngine = true
-			
-			subEngine.mu.Lock()
-			subEngine.nodeOutputs[loopNodeID] = map[string]interface{}{
-				"output_item": loopItem,
-				"index":       index,
-			}
-			subEngine.mu.Unlock()
+	e.mu.Lock()
+	e.nodeOutputs[nodeID] = output
+	e.mu.Unlock()
 
-			if err := subEngine.Execute(ctx); err != nil {
-				errCh <- fmt.Errorf("iteration %d failed: %w", index, err)
-			}
-		}(i, item)
+	return handle, nil
+}
+
+// onErrorPolicy returns the effective on-error policy of a node.
+func (e *WorkflowEngine) onErrorPolicy(nodeID uuid.UUID, settings NodeSettings) string {
+	switch settings.OnError {
+	case OnErrorStop, OnErrorContinueErrorOutput, OnErrorContinueRegularOutput:
+		return settings.OnError
 	}
 
-	wg.Wait()
-	close(errCh)
+	for _, edge := range e.Edges {
+		if edge.SourceNodeID == nodeID && edge.SourceHandle == errorHandle {
+			return OnErrorContinueErrorOutput
+		}
+	}
+	return OnErrorStop
+}
 
-	var errs []error
-	for err := range errCh {
-		errs = append(errs, err)
+// branchCount returns the number of distinct regular output handles a node
+// has outgoing edges on.
+func (e *WorkflowEngine) branchCount(nodeID uuid.UUID) int {
+	handles := make(map[string]bool)
+	for _, edge := range e.Edges {
+		if edge.SourceNodeID == nodeID && edge.SourceHandle != errorHandle {
+			handles[edge.SourceHandle] = true
+		}
 	}
-	if len(errs) > 0 {
-		return errors.Join(errs...)
+	return len(handles)
+}
+
+// runExecutor runs a single attempt of a node under the node timeout.
+func (e *WorkflowEngine) runExecutor(ctx context.Context, executor domain.INodeExecutor, data []byte, timeout time.Duration) (*domain.NodeResult, bool, error) {
+	timeoutCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	result, err := executor.Execute(timeoutCtx, data)
+	if err == nil && result == nil {
+		err = errors.New("node returned no result")
 	}
 
-	return nil
+	return result, errors.Is(timeoutCtx.Err(), context.DeadlineExceeded), err
+}
+
+// attemptRecord describes the outcome of one attempt of a node.
+func attemptRecord(attempt int, startedAt time.Time, result *domain.NodeResult, err error, timedOut bool) domain.NodeRunAttempt {
+	record := domain.NodeRunAttempt{
+		Attempt:    attempt,
+		Status:     domain.NodeRunLogStatusCompleted,
+		StartedAt:  startedAt,
+		FinishedAt: time.Now(),
+	}
+
+	if err != nil || result.Status == "failed" {
+		msg := failureMessage(result, err)
+		if err != nil {
+			msg = utils.SanitizeError(err)
+		}
+		record.Status = domain.NodeRunLogStatusFailed
+		record.ErrorClass = classifyFailure(err, msg, timedOut)
+		record.ErrorMsg = msg
+	}
+
+	return record
+}
+
+// acquireSlots waits until both the run and the server allow another node to
+// execute. The returned function releases the slots.
+//
+// execute_workflow nodes take no slots: they spend their time waiting on a
+// sub-workflow run whose nodes need the same slots, so holding one while
+// waiting could leave every slot with a parent and no room for its children.
+func (e *WorkflowEngine) acquireSlots(ctx context.Context, nodeType string) (func(), error) {
+	if nodeType == "execute_workflow" {
+		return func() {}, nil
+	}
+	if err := e.runSlots.Acquire(ctx); err != nil {
+		return nil, err
+	}
+	if err := e.Config.NodeSlots.Acquire(ctx); err != nil {
+		e.runSlots.Release()
+		return nil, err
+	}
+	return func() {
+		e.Config.NodeSlots.Release()
+		e.runSlots.Release()
+	}, nil
 }
 
 func (e *WorkflowEngine) getSubgraph(startNodeID uuid.UUID, startHandle string) (map[uuid.UUID]domain.WorkflowNode, []domain.WorkflowEdge) {
@@ -377,6 +756,30 @@ func (e *WorkflowEngine) getSubgraph(startNodeID uuid.UUID, startHandle string)
 }
 
 
+// triggerNodeIDKey is the run input field in which triggers record the node
+// that fired the run.
+const triggerNodeIDKey = "trigger_node_id"
+
+// triggerNodeID returns the start node that fired the run, if any.
+func (e *WorkflowEngine) triggerNodeID(startNodes []uuid.UUID) (uuid.UUID, bool) {
+	if e.isSubEngine {
+		return uuid.Nil, false
+	}
+
+	raw, _ := e.Input[triggerNodeIDKey].(string)
+	id, err := uuid.Parse(raw)
+	if err != nil {
+		return uuid.Nil, false
+	}
+
+	for _, nodeID := range startNodes {
+		if nodeID == id {
+			return id, true
+		}
+	}
+	return uuid.Nil, false
+}
+
 func (e *WorkflowEngine) findStartNodes(inDegree map[uuid.UUID]int) []uuid.UUID {
 	var start []uuid.UUID
 	for id, count := range inDegree {
@@ -387,20 +790,121 @@ func (e *WorkflowEngine) findStartNodes(inDegree map[uuid.UUID]int) []uuid.UUID
 	return start
 }
 
-func (e *WorkflowEngine) findNextNodes(nodeID uuid.UUID, triggeredHandle string) []uuid.UUID {
-	var next []uuid.UUID
+// isEdgeTaken reports whether a node that triggered the given handle
+// continues along edge. An empty handle follows every regular output.
+func isEdgeTaken(edge domain.WorkflowEdge, triggeredHandle string) bool {
+	if triggeredHandle != "" {
+		return edge.SourceHandle == triggeredHandle
+	}
+	// Error branches only run when the node explicitly reports an error.
+	return edge.SourceHandle != errorHandle
+}
+
+// loopBodyNodes returns the nodes that only run inside loop iterations. The
+// main engine settles them without logging a skip.
+func (e *WorkflowEngine) loopBodyNodes() map[uuid.UUID]bool {
+	body := make(map[uuid.UUID]bool)
+	for id, node := range e.Nodes {
+		if nodeType, _ := node.Data["type"].(string); nodeType != "loop" {
+			continue
+		}
+		subNodes, _ := e.getSubgraph(id, "output_item")
+		for subID := range subNodes {
+			body[subID] = true
+		}
+	}
+	return body
+}
+
+// checkpoint stores the settled state of a node so that an interrupted run
+// can be resumed without executing it again.
+func (e *WorkflowEngine) checkpoint(ctx context.Context, nodeID uuid.UUID, status domain.NodeRunLogStatus, triggeredHandle string) error {
+	if e.StateRepo == nil || e.isSubEngine || isAbandoned(ctx) {
+		return nil
+	}
+
+	e.mu.RLock()
+	output := e.nodeOutputs[nodeID]
+	e.mu.RUnlock()
+
+	err := e.StateRepo.Save(context.WithoutCancel(ctx), &domain.WorkflowRunNodeState{
+		RunID:           e.RunID,
+		NodeID:          nodeID,
+		Status:          status,
+		TriggeredHandle: triggeredHandle,
+		Output:          output,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to checkpoint node %s: %w", nodeID, err)
+	}
+	return nil
+}
+
+// respondNodeType is the type of the nodes that set the result of a run.
+const respondNodeType = "respond"
+
+// Output returns the result of a finished run. When respond nodes ran, the
+// result is taken from them; otherwise from the leaf nodes. A single node's
+// output is returned as is, several are keyed by node name.
+func (e *WorkflowEngine) Output() map[string]interface{} {
+	hasOutgoing := make(map[uuid.UUID]bool)
 	for _, edge := range e.Edges {
-		if edge.SourceNodeID == nodeID {
-			if triggeredHandle != "" && edge.SourceHandle != triggeredHandle {
-				continue
-			}
-			// Only consider targets that exist in the engine (important for subengines)
-			if _, exists := e.Nodes[edge.TargetNodeID]; exists {
-				next = append(next, edge.TargetNodeID)
-			}
+		hasOutgoing[edge.SourceNodeID] = true
+	}
+
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	var responses, leaves []uuid.UUID
+	for _, id := range sortedNodeIDs(e.Nodes) {
+		if _, ok := e.nodeOutputs[id]; !ok {
+			continue
 		}
+		if nodeType, _ := e.Nodes[id].Data["type"].(string); nodeType == respondNodeType {
+			responses = append(responses, id)
+		}
+		if !hasOutgoing[id] {
+			leaves = append(leaves, id)
+		}
+	}
+
+	if len(responses) > 0 {
+		return e.collectOutputs(responses)
+	}
+	return e.collectOutputs(leaves)
+}
+
+// collectOutputs returns the output of a single node, or the outputs of
+// several keyed by node name. The caller must hold e.mu.
+func (e *WorkflowEngine) collectOutputs(ids []uuid.UUID) map[string]interface{} {
+	if len(ids) == 1 {
+		return e.nodeOutputs[ids[0]]
+	}
+
+	outputs := make(map[string]interface{}, len(ids))
+	for _, id := range ids {
+		outputs[nodeName(e.Nodes[id])] = e.nodeOutputs[id]
+	}
+	return outputs
+}
+
+// skipNode records that a node did not run because none of its inputs were
+// reached.
+func (e *WorkflowEngine) skipNode(ctx context.Context, nodeID uuid.UUID) {
+	nodeType, _ := e.Nodes[nodeID].Data["type"].(string)
+	err := e.notifyNodeFinish(ctx, NodeEvent{
+		ExecutionID: uuid.New(),
+		RunID:       e.RunID,
+		WorkflowID:  e.WorkflowID,
+		NodeID:      nodeID,
+		NodeType:    nodeType,
+		Iteration:   e.iteration,
+		Status:      domain.NodeRunLogStatusSkipped,
+		Time:        time.Now(),
+	})
+	if err != nil {
+		log.Printf("⚠️ Failed to log skipped node: %v", err)
 	}
-	return next
 }
 
 func (e *WorkflowEngine) getIncomingEdges(nodeID uuid.UUID) []domain.WorkflowEdge {
@@ -413,29 +917,70 @@ func (e *WorkflowEngine) getIncomingEdges(nodeID uuid.UUID) []domain.WorkflowEdg
 	return incoming
 }
 
-func (e *WorkflowEngine) updateLog(ctx context.Context, logID uuid.UUID, status domain.NodeRunLogStatus, output, errorMsg string) error {
-	req := &domain.UpdateNodeRunLogRequest{
-		Status:    status,
-		LogOutput: output,
-		ErrorMsg:  errorMsg,
+// expressionScope builds the variables available to {{ ... }} expressions in
+// node data. Nodes can be referenced by ID, name or label; sub-engines also
+// see the outputs of their parent engines. $vars exposes the run variables.
+func (e *WorkflowEngine) expressionScope(input map[string]interface{}) expressionScope {
+	nodes := make(map[uuid.UUID]domain.WorkflowNode)
+	outputs := make(map[uuid.UUID]map[string]interface{})
+
+	var chain []*WorkflowEngine
+	for eng := e; eng != nil; eng = eng.parent {
+		chain = append([]*WorkflowEngine{eng}, chain...)
+	}
+	for _, eng := range chain {
+		eng.mu.RLock()
+		for id, node := range eng.Nodes {
+			nodes[id] = node
+		}
+		for id, output := range eng.nodeOutputs {
+			outputs[id] = output
+		}
+		eng.mu.RUnlock()
+	}
+
+	nodeScope := make(map[string]interface{})
+	for id, output := range outputs {
+		nodeScope[id.String()] = map[string]interface{}{"output": output}
+	}
+	for id, node := range nodes {
+		entry := map[string]interface{}{"output": outputs[id]}
+		nodeScope[id.String()] = entry
+		for _, key := range []string{"name", "label"} {
+			if name, ok := node.Data[key].(string); ok && name != "" {
+				nodeScope[name] = entry
+			}
+		}
+	}
+
+	return expressionScope{
+		"node":  nodeScope,
+		"input": input,
+		"run": map[string]interface{}{
+			"id":          e.RunID.String(),
+			"workflow_id": e.WorkflowID.String(),
+			"input":       e.Input,
+		},
+		"vars": e.Variables(),
 	}
-	return e.LogRepo.Update(ctx, logID, req)
 }
 
 func (e *WorkflowEngine) failRun(ctx context.Context, msg string) error {
+	err := errors.New(msg)
 	if !e.isSubEngine {
-		now := time.Now()
-		e.RunRepo.UpdateStatus(ctx, e.RunID, domain.WorkflowRunStatusFailed, &now)
+		e.notifyRunFinish(ctx, domain.WorkflowRunStatusFailed, nil, err)
 	}
-	return errors.New(msg)
+	return err
 }
 
-func (e *WorkflowEngine) logNodeError(ctx context.Context, nodeID uuid.UUID, msg string) {
-	e.LogRepo.Create(ctx, &domain.CreateNodeRunLogRequest{
-		RunID:  e.RunID,
-		NodeID: nodeID,
-		Status: domain.NodeRunLogStatusFailed,
-	})
+// nodeName returns the name, label or ID a node is referred to by.
+func nodeName(node domain.WorkflowNode) string {
+	for _, key := range []string{"name", "label"} {
+		if name, ok := node.Data[key].(string); ok && name != "" {
+			return name
+		}
+	}
+	return node.ID.String()
 }
 
 func toSliceInterface(v interface{}) ([]interface{}, error) {
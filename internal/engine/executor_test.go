@@ -17,8 +17,8 @@ type MockRunRepo struct {
 	mock.Mock
 }
 
-func (m *MockRunRepo) Create(ctx context.Context, workflowID uuid.UUID) (*domain.WorkflowRun, error) {
-	args := m.Called(ctx, workflowID)
+func (m *MockRunRepo) Create(ctx context.Context, req *domain.CreateWorkflowRunRequest) (*domain.WorkflowRun, error) {
+	args := m.Called(ctx, req)
 	return args.Get(0).(*domain.WorkflowRun), args.Error(1)
 }
 func (m *MockRunRepo) GetByID(ctx context.Context, id uuid.UUID) (*domain.WorkflowRun, error) {
@@ -33,6 +33,30 @@ func (m *MockRunRepo) ListByWorkflowID(ctx context.Context, workflowID uuid.UUID
 	args := m.Called(ctx, workflowID, limit, offset)
 	return args.Get(0).([]*domain.WorkflowRun), args.Int(1), args.Error(2)
 }
+func (m *MockRunRepo) ListByParentRunID(ctx context.Context, parentRunID uuid.UUID) ([]*domain.WorkflowRun, error) {
+	args := m.Called(ctx, parentRunID)
+	return args.Get(0).([]*domain.WorkflowRun), args.Error(1)
+}
+func (m *MockRunRepo) SetOutput(ctx context.Context, id uuid.UUID, output map[string]interface{}) error {
+	args := m.Called(ctx, id, output)
+	return args.Error(0)
+}
+func (m *MockRunRepo) RequestCancel(ctx context.Context, id uuid.UUID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+func (m *MockRunRepo) ListCancelRequested(ctx context.Context, ids []uuid.UUID) ([]uuid.UUID, error) {
+	args := m.Called(ctx, ids)
+	return args.Get(0).([]uuid.UUID), args.Error(1)
+}
+
+// newMockRunRepo returns a MockRunRepo that accepts the run output the
+// engine records once a run completes.
+func newMockRunRepo() *MockRunRepo {
+	m := new(MockRunRepo)
+	m.On("SetOutput", mock.Anything, mock.Anything, mock.Anything).Return(nil).Maybe()
+	return m
+}
 
 type MockLogRepo struct {
 	mock.Mock
@@ -54,6 +78,14 @@ func (m *MockLogRepo) Update(ctx context.Context, id uuid.UUID, req *domain.Upda
 	args := m.Called(ctx, id, req)
 	return args.Error(0)
 }
+func (m *MockLogRepo) AddAttempt(ctx context.Context, id uuid.UUID, attempt *domain.NodeRunAttempt) error {
+	args := m.Called(ctx, id, attempt)
+	return args.Error(0)
+}
+func (m *MockLogRepo) CancelUnfinished(ctx context.Context, runID uuid.UUID) error {
+	args := m.Called(ctx, runID)
+	return args.Error(0)
+}
 
 func TestWorkflowEngine_Execute_SimpleFlow(t *testing.T) {
 	// Setup
@@ -95,7 +127,7 @@ func TestWorkflowEngine_Execute_SimpleFlow(t *testing.T) {
 	}
 
 	// Mocks
-	mockRunRepo := new(MockRunRepo)
+	mockRunRepo := newMockRunRepo()
 	mockLogRepo := new(MockLogRepo)
 
 	// Expectations
@@ -153,7 +185,7 @@ func TestWorkflowEngine_Execute_ParallelBranches(t *testing.T) {
 		{ID: uuid.New(), WorkflowID: workflowID, SourceNodeID: branchBID, TargetNodeID: mergeID, SourceHandle: "output", TargetHandle: "input"},
 	}
 
-	mockRunRepo := new(MockRunRepo)
+	mockRunRepo := newMockRunRepo()
 	mockLogRepo := new(MockLogRepo)
 
 	mockRunRepo.On("UpdateStatus", mock.Anything, runID, domain.WorkflowRunStatusRunning, mock.Anything).Return(nil)
@@ -209,7 +241,7 @@ func TestWorkflowEngine_Execute_NodeFailure_NoBlockOthers(t *testing.T) {
 		{ID: uuid.New(), WorkflowID: workflowID, SourceNodeID: startID, TargetNodeID: goodID, SourceHandle: "output", TargetHandle: "input"},
 	}
 
-	mockRunRepo := new(MockRunRepo)
+	mockRunRepo := newMockRunRepo()
 	mockLogRepo := new(MockLogRepo)
 
 	mockRunRepo.On("UpdateStatus", mock.Anything, runID, domain.WorkflowRunStatusRunning, mock.Anything).Return(nil)
@@ -264,7 +296,7 @@ func TestWorkflowEngine_Execute_NoStartNodes(t *testing.T) {
 		{ID: uuid.New(), WorkflowID: workflowID, SourceNodeID: nodeBID, TargetNodeID: nodeAID, SourceHandle: "output", TargetHandle: "input"},
 	}
 
-	mockRunRepo := new(MockRunRepo)
+	mockRunRepo := newMockRunRepo()
 	mockLogRepo := new(MockLogRepo)
 
 	mockRunRepo.On("UpdateStatus", mock.Anything, runID, domain.WorkflowRunStatusRunning, mock.Anything).Return(nil)
@@ -0,0 +1,296 @@
+package engine
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// expressionScope maps root identifiers (without the leading "$") to the
+// data they expose, e.g. "node", "input", "run" and "vars".
+type expressionScope map[string]interface{}
+
+// resolveExpressions walks v and replaces every {{ ... }} expression found in
+// string values. A string that consists of a single expression keeps the
+// type of the referenced value; expressions embedded in surrounding text are
+// rendered as strings.
+//
+// Only spans whose content starts with a $ variable are expressions. Other
+// {{ ... }} spans, such as Handlebars or Mustache templates in code and email
+// bodies, are left as they are, and \{{ writes a literal {{ in front of
+// anything, including a $ variable.
+func resolveExpressions(v interface{}, scope expressionScope) (interface{}, error) {
+	switch val := v.(type) {
+	case string:
+		return resolveString(val, scope)
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(val))
+		for k, item := range val {
+			resolved, err := resolveExpressions(item, scope)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", k, err)
+			}
+			out[k] = resolved
+		}
+		return out, nil
+	case []interface{}:
+		out := make([]interface{}, len(val))
+		for i, item := range val {
+			resolved, err := resolveExpressions(item, scope)
+			if err != nil {
+				return nil, fmt.Errorf("[%d]: %w", i, err)
+			}
+			out[i] = resolved
+		}
+		return out, nil
+	default:
+		return v, nil
+	}
+}
+
+// deferredFields lists, by node type, the data fields that the engine
+// resolves itself later in the run rather than when the node starts.
+var deferredFields = map[string][]string{
+	"loop": {"break_when"},
+}
+
+// resolveNodeData resolves the expressions in a node's data. Deferred fields
+// are kept as they are.
+func resolveNodeData(data map[string]interface{}, scope expressionScope) (map[string]interface{}, error) {
+	nodeType, _ := data["type"].(string)
+	deferred := deferredFields[nodeType]
+
+	toResolve := data
+	if len(deferred) > 0 {
+		toResolve = make(map[string]interface{}, len(data))
+		for k, v := range data {
+			toResolve[k] = v
+		}
+		for _, field := range deferred {
+			delete(toResolve, field)
+		}
+	}
+
+	resolved, err := resolveExpressions(toResolve, scope)
+	if err != nil {
+		return nil, err
+	}
+
+	out, _ := resolved.(map[string]interface{})
+	if out == nil {
+		out = make(map[string]interface{})
+	}
+	for _, field := range deferred {
+		if v, ok := data[field]; ok {
+			out[field] = v
+		}
+	}
+	return out, nil
+}
+
+// isExpression reports whether the text following {{ starts an expression.
+func isExpression(s string) bool {
+	return strings.HasPrefix(strings.TrimLeft(s, " \t\n"), "$")
+}
+
+func resolveString(s string, scope expressionScope) (interface{}, error) {
+	if !strings.Contains(s, "{{") {
+		return s, nil
+	}
+
+	// A value that is exactly one expression keeps its original type.
+	trimmed := strings.TrimSpace(s)
+	if strings.HasPrefix(trimmed, "{{") && strings.HasSuffix(trimmed, "}}") &&
+		strings.Count(trimmed, "{{") == 1 && isExpression(trimmed[2:]) {
+		return evaluateExpression(trimmed[2:len(trimmed)-2], scope)
+	}
+
+	var sb strings.Builder
+	rest := s
+	for {
+		start := strings.Index(rest, "{{")
+		if start < 0 {
+			sb.WriteString(rest)
+			break
+		}
+
+		// \{{ is an escaped, literal {{.
+		if start > 0 && rest[start-1] == '\\' {
+			sb.WriteString(rest[:start-1])
+			sb.WriteString("{{")
+			rest = rest[start+2:]
+			continue
+		}
+
+		if !isExpression(rest[start+2:]) {
+			sb.WriteString(rest[:start+2])
+			rest = rest[start+2:]
+			continue
+		}
+
+		end := strings.Index(rest[start:], "}}")
+		if end < 0 {
+			return nil, fmt.Errorf("unterminated expression in %q", s)
+		}
+		end += start
+
+		sb.WriteString(rest[:start])
+		value, err := evaluateExpression(rest[start+2:end], scope)
+		if err != nil {
+			return nil, err
+		}
+		sb.WriteString(stringifyValue(value))
+		rest = rest[end+2:]
+	}
+
+	return sb.String(), nil
+}
+
+// evaluateExpression evaluates a single path expression such as
+// $node["Fetch"].output.body.items[0].
+func evaluateExpression(expr string, scope expressionScope) (interface{}, error) {
+	expr = strings.TrimSpace(expr)
+	if !strings.HasPrefix(expr, "$") {
+		return nil, fmt.Errorf("invalid expression %q: must start with a $ variable", expr)
+	}
+
+	pos := 1
+	root := readIdentifier(expr, &pos)
+	if root == "" {
+		return nil, fmt.Errorf("invalid expression %q: missing variable name", expr)
+	}
+
+	current, ok := scope[root]
+	if !ok {
+		return nil, fmt.Errorf("invalid expression %q: unknown variable $%s", expr, root)
+	}
+
+	for pos < len(expr) {
+		switch expr[pos] {
+		case ' ', '\t', '\n':
+			pos++
+		case '.':
+			pos++
+			key := readIdentifier(expr, &pos)
+			if key == "" {
+				return nil, fmt.Errorf("invalid expression %q: expected property name at %d", expr, pos)
+			}
+			current = lookupKey(current, key)
+		case '[':
+			pos++
+			key, index, err := readBracket(expr, &pos)
+			if err != nil {
+				return nil, fmt.Errorf("invalid expression %q: %w", expr, err)
+			}
+			if index != nil {
+				current = lookupIndex(current, *index)
+			} else {
+				current = lookupKey(current, key)
+			}
+		default:
+			return nil, fmt.Errorf("invalid expression %q: unexpected %q at %d", expr, expr[pos], pos)
+		}
+	}
+
+	return current, nil
+}
+
+func readIdentifier(expr string, pos *int) string {
+	start := *pos
+	for *pos < len(expr) {
+		c := expr[*pos]
+		if c == '_' || c == '-' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9') {
+			*pos++
+			continue
+		}
+		break
+	}
+	return expr[start:*pos]
+}
+
+// readBracket parses the content of a [...] accessor, returning either a
+// string key or an integer index.
+func readBracket(expr string, pos *int) (string, *int, error) {
+	if *pos >= len(expr) {
+		return "", nil, fmt.Errorf("unterminated [")
+	}
+
+	if quote := expr[*pos]; quote == '"' || quote == '\'' {
+		end := strings.IndexByte(expr[*pos+1:], quote)
+		if end < 0 {
+			return "", nil, fmt.Errorf("unterminated string")
+		}
+		key := expr[*pos+1 : *pos+1+end]
+		*pos += end + 2
+		if *pos >= len(expr) || expr[*pos] != ']' {
+			return "", nil, fmt.Errorf("expected ] at %d", *pos)
+		}
+		*pos++
+		return key, nil, nil
+	}
+
+	end := strings.IndexByte(expr[*pos:], ']')
+	if end < 0 {
+		return "", nil, fmt.Errorf("unterminated [")
+	}
+	raw := strings.TrimSpace(expr[*pos : *pos+end])
+	index, err := strconv.Atoi(raw)
+	if err != nil {
+		return "", nil, fmt.Errorf("invalid index %q", raw)
+	}
+	*pos += end + 1
+	return "", &index, nil
+}
+
+// lookupKey returns the value stored under key in any map with string keys,
+// or nil when it is missing.
+func lookupKey(v interface{}, key string) interface{} {
+	if m, ok := v.(map[string]interface{}); ok {
+		return m[key]
+	}
+
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Map || rv.Type().Key().Kind() != reflect.String {
+		return nil
+	}
+	item := rv.MapIndex(reflect.ValueOf(key).Convert(rv.Type().Key()))
+	if !item.IsValid() {
+		return nil
+	}
+	return item.Interface()
+}
+
+// lookupIndex returns the element at index in any slice or array. Negative
+// indexes count from the end.
+func lookupIndex(v interface{}, index int) interface{} {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return nil
+	}
+	if index < 0 {
+		index += rv.Len()
+	}
+	if index < 0 || index >= rv.Len() {
+		return nil
+	}
+	return rv.Index(index).Interface()
+}
+
+func stringifyValue(v interface{}) string {
+	switch val := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return val
+	case fmt.Stringer:
+		return val.String()
+	}
+
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("%v", v)
+	}
+	return string(b)
+}
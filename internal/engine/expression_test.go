@@ -0,0 +1,134 @@
+package engine
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/mr-isik/loki-backend/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestResolveExpressions(t *testing.T) {
+	scope := expressionScope{
+		"node": map[string]interface{}{
+			"Fetch": map[string]interface{}{
+				"output": map[string]interface{}{
+					"status": 200,
+					"body":   map[string]interface{}{"id": "abc", "tags": []interface{}{"x", "y"}},
+				},
+			},
+		},
+		"input": map[string]interface{}{
+			"input": map[string]interface{}{"items": []interface{}{10.0, 20.0}},
+		},
+		"run": map[string]interface{}{"id": "run-1"},
+	}
+
+	tests := []struct {
+		name     string
+		input    interface{}
+		expected interface{}
+	}{
+		{name: "Plain string", input: "hello", expected: "hello"},
+		{name: "Typed value", input: `{{ $node["Fetch"].output.status }}`, expected: 200},
+		{name: "Nested map", input: "{{ $node['Fetch'].output.body }}", expected: map[string]interface{}{"id": "abc", "tags": []interface{}{"x", "y"}}},
+		{name: "Array index", input: "{{ $input.input.items[0] }}", expected: 10.0},
+		{name: "Negative index", input: "{{ $node.Fetch.output.body.tags[-1] }}", expected: "y"},
+		{name: "Interpolation", input: "https://api/{{ $node[\"Fetch\"].output.body.id }}?run={{$run.id}}", expected: "https://api/abc?run=run-1"},
+		{name: "Interpolated object", input: "tags={{ $node.Fetch.output.body.tags }}", expected: `tags=["x","y"]`},
+		{name: "Missing path", input: "{{ $node.Fetch.output.missing.deep }}", expected: nil},
+		{name: "Nested structures", input: map[string]interface{}{"list": []interface{}{"{{ $run.id }}"}}, expected: map[string]interface{}{"list": []interface{}{"run-1"}}},
+		{name: "Template braces", input: "<p>Hello {{name}}</p>{{#each items}}{{this}}{{/each}}", expected: "<p>Hello {{name}}</p>{{#each items}}{{this}}{{/each}}"},
+		{name: "Template braces next to expression", input: "{{ greeting }} {{ $run.id }}", expected: "{{ greeting }} run-1"},
+		{name: "Unterminated braces", input: "if (a) {{ b", expected: "if (a) {{ b"},
+		{name: "Escaped expression", input: `\{{ $run.id }} is {{ $run.id }}`, expected: "{{ $run.id }} is run-1"},
+		{name: "Escaped single expression", input: `\{{ $run.id }}`, expected: "{{ $run.id }}"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := resolveExpressions(tc.input, scope)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, result)
+		})
+	}
+}
+
+func TestResolveExpressions_Errors(t *testing.T) {
+	scope := expressionScope{"run": map[string]interface{}{"id": "run-1"}}
+
+	inputs := []string{
+		"{{ $unknown.value }}",
+		"{{ $run.id",
+		"{{ $run[id] }}",
+		"{{ $run.id + 1 }}",
+	}
+
+	for _, input := range inputs {
+		_, err := resolveExpressions(input, scope)
+		assert.Error(t, err, input)
+	}
+}
+
+func TestResolveExpressions_CodeNodeWithBraces(t *testing.T) {
+	scope := expressionScope{"input": map[string]interface{}{"name": "Ada"}}
+	code := "const render = Handlebars.compile('<p>{{name}}</p>');\n" +
+		"const literal = '\\{{ $input.name }}';\n" +
+		"if (ok) {{ return render({ name: '{{ $input.name }}' }); }}"
+	data := map[string]interface{}{"type": "code_js", "code": code}
+
+	resolved, err := resolveExpressions(data, scope)
+	assert.NoError(t, err)
+	assert.Equal(t, "const render = Handlebars.compile('<p>{{name}}</p>');\n"+
+		"const literal = '{{ $input.name }}';\n"+
+		"if (ok) {{ return render({ name: 'Ada' }); }}", resolved.(map[string]interface{})["code"])
+}
+
+func TestWorkflowEngine_Execute_ResolvesExpressions(t *testing.T) {
+	runID := uuid.New()
+	workflowID := uuid.New()
+	sourceID := uuid.New()
+	targetID := uuid.New()
+
+	nodes := []domain.WorkflowNode{
+		{ID: sourceID, WorkflowID: workflowID, Data: map[string]interface{}{
+			"type": "set_data",
+			"name": "Source",
+			"data": map[string]interface{}{"user": map[string]interface{}{"id": 42}},
+		}},
+		{ID: targetID, WorkflowID: workflowID, Data: map[string]interface{}{
+			"type": "set_data",
+			"data": map[string]interface{}{
+				"user_id":    `{{ $node["Source"].output.user.id }}`,
+				"from_input": "{{ $input.input.user.id }}",
+				"label":      "run {{ $run.id }}",
+			},
+		}},
+	}
+
+	edges := []domain.WorkflowEdge{
+		{ID: uuid.New(), WorkflowID: workflowID, SourceNodeID: sourceID, TargetNodeID: targetID, SourceHandle: "output", TargetHandle: "input"},
+	}
+
+	mockRunRepo := newMockRunRepo()
+	mockLogRepo := new(MockLogRepo)
+
+	mockRunRepo.On("UpdateStatus", mock.Anything, runID, mock.Anything, mock.Anything).Return(nil)
+	mockLogRepo.On("Create", mock.Anything, mock.Anything).Return(&domain.NodeRunLog{ID: uuid.New()}, nil)
+	mockLogRepo.On("Update", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	engine := NewWorkflowEngine(nodes, edges, runID, workflowID, mockLogRepo, mockRunRepo)
+	err := engine.Execute(context.Background())
+	assert.NoError(t, err)
+
+	engine.mu.RLock()
+	output := engine.nodeOutputs[targetID]
+	engine.mu.RUnlock()
+
+	// set_data receives its config as JSON, so numbers come back as float64.
+	assert.Equal(t, 42.0, output["user_id"])
+	assert.Equal(t, 42.0, output["from_input"])
+	assert.Equal(t, "run "+runID.String(), output["label"])
+}
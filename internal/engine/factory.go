@@ -12,6 +12,9 @@ func init() {
 	// ── Trigger nodes ──────────────────────────────────────────────
 	defaultRegistry.Register("webhook", func() domain.INodeExecutor { return &nodes.WebhookNode{} })
 	defaultRegistry.Register("cron", func() domain.INodeExecutor { return &nodes.CronNode{} })
+	defaultRegistry.Register("manual_trigger", func() domain.INodeExecutor { return &nodes.ManualTriggerNode{} })
+	defaultRegistry.Register("poll_http", func() domain.INodeExecutor { return &nodes.PollHttpNode{} })
+	defaultRegistry.Register("db_postgres_listen", func() domain.INodeExecutor { return &nodes.DbPostgresListenNode{} })
 
 	// ── Action nodes ───────────────────────────────────────────────
 	defaultRegistry.Register("http_request", func() domain.INodeExecutor { return &nodes.HttpRequestNode{} })
@@ -29,6 +32,8 @@ func init() {
 	// ── Data / utility nodes ───────────────────────────────────────
 	defaultRegistry.Register("set_data", func() domain.INodeExecutor { return &nodes.SetDataNode{} })
 	defaultRegistry.Register("log", func() domain.INodeExecutor { return &nodes.LogNode{} })
+	defaultRegistry.Register(respondNodeType, func() domain.INodeExecutor { return &nodes.RespondNode{} })
+	defaultRegistry.Register(setVariableNodeType, func() domain.INodeExecutor { return &nodes.SetVariableNode{} })
 
 	// ── File nodes ─────────────────────────────────────────────────
 	defaultRegistry.Register("file_read", func() domain.INodeExecutor { return &nodes.FileReadNode{} })
@@ -0,0 +1,256 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/google/uuid"
+	"github.com/mr-isik/loki-backend/internal/domain"
+)
+
+// Loop modes. Sequential loops run one iteration at a time in item order.
+const (
+	LoopModeParallel   = "parallel"
+	LoopModeSequential = "sequential"
+)
+
+// loopRun holds the state shared by the iterations of one loop execution.
+type loopRun struct {
+	loopNodeID uuid.UUID
+	nodes      []domain.WorkflowNode
+	edges      []domain.WorkflowEdge
+	items      []interface{}
+	// breakWhen is a value such as {{ $iteration.output.done }}. It is left
+	// unresolved when the loop starts and resolved after every iteration; a
+	// truthy result stops the loop from starting further iterations.
+	breakWhen string
+
+	// results holds the output of the last node of each iteration, and
+	// finished marks the iterations that completed.
+	results  []interface{}
+	finished []bool
+	stopped  atomic.Bool
+}
+
+// executeLoop runs the loop body once per item. Items are split into batches
+// of batch_size that run one after another; within a batch at most
+// concurrency iterations run at the same time. Once the loop is done, its
+// output holds the results of the iterations that ran.
+func (e *WorkflowEngine) executeLoop(ctx context.Context, loopNodeID uuid.UUID) error {
+	e.mu.RLock()
+	loopOutputs := e.nodeOutputs[loopNodeID]
+	e.mu.RUnlock()
+
+	itemsInterface, ok := loopOutputs["items"]
+	if !ok {
+		return nil
+	}
+
+	items, err := toSliceInterface(itemsInterface)
+	if err != nil {
+		return fmt.Errorf("invalid items type: %v", err)
+	}
+
+	subNodes, subEdges := e.getSubgraph(loopNodeID, "output_item")
+	if len(subNodes) == 0 {
+		return nil
+	}
+
+	loop := &loopRun{
+		loopNodeID: loopNodeID,
+		edges:      subEdges,
+		items:      items,
+		results:    make([]interface{}, len(items)),
+		finished:   make([]bool, len(items)),
+	}
+	for _, n := range subNodes {
+		loop.nodes = append(loop.nodes, n)
+	}
+	loop.breakWhen, _ = e.Nodes[loopNodeID].Data["break_when"].(string)
+
+	concurrency := e.Config.LoopConcurrency(intOutput(loopOutputs, "concurrency"))
+	if mode, _ := loopOutputs["mode"].(string); mode == LoopModeSequential {
+		concurrency = 1
+	}
+	batchSize := intOutput(loopOutputs, "batch_size")
+	if batchSize <= 0 {
+		batchSize = len(items)
+	}
+
+	var errs []error
+	for start := 0; start < len(items) && ctx.Err() == nil && !loop.stopped.Load(); start += batchSize {
+		end := min(start+batchSize, len(items))
+		errs = append(errs, e.runIterations(ctx, loop, start, end, concurrency)...)
+	}
+
+	output := make(map[string]interface{}, len(loopOutputs)+1)
+	for k, v := range loopOutputs {
+		output[k] = v
+	}
+	output["results"] = loop.finishedResults()
+
+	e.mu.Lock()
+	e.nodeOutputs[loopNodeID] = output
+	e.mu.Unlock()
+
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+
+	return nil
+}
+
+// runIterations runs the iterations for items[start:end] with at most
+// concurrency of them in flight. No iteration is started once one has failed
+// or the break condition was met.
+func (e *WorkflowEngine) runIterations(ctx context.Context, loop *loopRun, start, end, concurrency int) []error {
+	indexes := make(chan int)
+	errCh := make(chan error, end-start)
+
+	var wg sync.WaitGroup
+	for w := 0; w < concurrency && w < end-start; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for index := range indexes {
+				if loop.stopped.Load() {
+					continue
+				}
+				if err := e.runIteration(ctx, loop, index); err != nil {
+					loop.stopped.Store(true)
+					errCh <- fmt.Errorf("iteration %d failed: %w", index, err)
+				}
+			}
+		}()
+	}
+
+feed:
+	for i := start; i < end && !loop.stopped.Load(); i++ {
+		select {
+		case indexes <- i:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(indexes)
+
+	wg.Wait()
+	close(errCh)
+
+	var errs []error
+	for err := range errCh {
+		errs = append(errs, err)
+	}
+	return errs
+}
+
+// finishedResults returns the results of the iterations that completed, in
+// item order. Parallel iterations skipped after a break leave no gaps.
+func (l *loopRun) finishedResults() []interface{} {
+	results := make([]interface{}, 0, len(l.results))
+	for i, result := range l.results {
+		if l.finished[i] {
+			results = append(results, result)
+		}
+	}
+	return results
+}
+
+// runIteration runs the loop body for a single item and evaluates the break
+// condition against its result.
+func (e *WorkflowEngine) runIteration(ctx context.Context, loop *loopRun, index int) error {
+	subEngine := e.newSubEngine(loop.nodes, loop.edges)
+	subEngine.iteration = &index
+
+	item := loop.items[index]
+	subEngine.mu.Lock()
+	subEngine.nodeOutputs[loop.loopNodeID] = map[string]interface{}{
+		"output_item": item,
+		"index":       index,
+	}
+	subEngine.mu.Unlock()
+
+	if err := subEngine.Execute(ctx); err != nil {
+		return err
+	}
+
+	subEngine.mu.RLock()
+	output := subEngine.lastOutput
+	subEngine.mu.RUnlock()
+	loop.results[index] = output
+	loop.finished[index] = true
+
+	if loop.breakWhen == "" {
+		return nil
+	}
+
+	scope := subEngine.expressionScope(nil)
+	scope["iteration"] = map[string]interface{}{
+		"index":  index,
+		"item":   item,
+		"output": output,
+	}
+	value, err := resolveString(loop.breakWhen, scope)
+	if err != nil {
+		return fmt.Errorf("failed to evaluate break_when: %w", err)
+	}
+	if isTruthy(value) {
+		loop.stopped.Store(true)
+	}
+	return nil
+}
+
+// newSubEngine creates an engine for a loop iteration. It shares the run's
+// limits and observers with its parent.
+func (e *WorkflowEngine) newSubEngine(nodes []domain.WorkflowNode, edges []domain.WorkflowEdge) *WorkflowEngine {
+	subEngine := NewWorkflowEngine(nodes, edges, e.RunID, e.WorkflowID, nil, nil)
+	subEngine.observers = e.observers
+	subEngine.isSubEngine = true
+	subEngine.parent = e
+	subEngine.Config = e.Config
+	subEngine.Settings = e.Settings
+	subEngine.Input = e.Input
+	subEngine.UsePinnedOutputs = e.UsePinnedOutputs
+	subEngine.runSlots = e.runSlots
+	return subEngine
+}
+
+// isTruthy reports whether an expression value counts as true.
+func isTruthy(v interface{}) bool {
+	switch val := v.(type) {
+	case nil:
+		return false
+	case bool:
+		return val
+	case string:
+		s := strings.TrimSpace(strings.ToLower(val))
+		return s != "" && s != "false" && s != "0"
+	case int:
+		return val != 0
+	case float64:
+		return val != 0
+	case []interface{}:
+		return len(val) > 0
+	case map[string]interface{}:
+		return len(val) > 0
+	default:
+		return true
+	}
+}
+
+// intOutput reads an integer from a node output, which holds float64 values
+// once it has been through JSON.
+func intOutput(output map[string]interface{}, key string) int {
+	switch v := output[key].(type) {
+	case int:
+		return v
+	case float64:
+		return int(v)
+	default:
+		return 0
+	}
+}
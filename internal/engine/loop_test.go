@@ -0,0 +1,123 @@
+package engine
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/mr-isik/loki-backend/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// echoNode outputs the item it receives and records the order of its calls.
+type echoNode struct {
+	mu    sync.Mutex
+	order []interface{}
+}
+
+func (n *echoNode) Execute(ctx context.Context, rawData []byte) (*domain.NodeResult, error) {
+	var data struct {
+		Input map[string]interface{} `json:"input"`
+	}
+	if err := json.Unmarshal(rawData, &data); err != nil {
+		return nil, err
+	}
+
+	item := data.Input["input"]
+	n.mu.Lock()
+	n.order = append(n.order, item)
+	n.mu.Unlock()
+
+	return &domain.NodeResult{Status: "completed", OutputData: map[string]interface{}{"value": item}}, nil
+}
+
+func runLoopWorkflow(t *testing.T, typeKey string, loopData map[string]interface{}) (*WorkflowEngine, uuid.UUID, *MockLogRepo, error) {
+	loopID := uuid.New()
+	bodyID := uuid.New()
+	loopData["type"] = "loop"
+	nodes := []domain.WorkflowNode{
+		{ID: loopID, Data: loopData},
+		{ID: bodyID, Data: map[string]interface{}{"type": typeKey}},
+	}
+	edges := []domain.WorkflowEdge{
+		{ID: uuid.New(), SourceNodeID: loopID, TargetNodeID: bodyID, SourceHandle: "output_item", TargetHandle: "input"},
+	}
+
+	runID := uuid.New()
+	mockRunRepo := newMockRunRepo()
+	mockLogRepo := new(MockLogRepo)
+	mockRunRepo.On("UpdateStatus", mock.Anything, runID, mock.Anything, mock.Anything).Return(nil)
+	mockLogRepo.On("Create", mock.Anything, mock.Anything).Return(&domain.NodeRunLog{ID: uuid.New()}, nil)
+	mockLogRepo.On("Update", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	engine := NewWorkflowEngine(nodes, edges, runID, uuid.New(), mockLogRepo, mockRunRepo)
+	err := engine.Execute(context.Background())
+	return engine, loopID, mockLogRepo, err
+}
+
+func TestWorkflowEngine_Execute_SequentialLoopResults(t *testing.T) {
+	node := &echoNode{}
+	RegisterNode("test_echo_sequential", func() domain.INodeExecutor { return node })
+
+	engine, loopID, mockLogRepo, err := runLoopWorkflow(t, "test_echo_sequential", map[string]interface{}{
+		"items": []interface{}{"a", "b", "c"},
+		"mode":  "sequential",
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{"a", "b", "c"}, node.order)
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"value": "a"},
+		map[string]interface{}{"value": "b"},
+		map[string]interface{}{"value": "c"},
+	}, engine.nodeOutputs[loopID]["results"])
+
+	for i := 0; i < 3; i++ {
+		index := i
+		mockLogRepo.AssertCalled(t, "Create", mock.Anything, mock.MatchedBy(func(req *domain.CreateNodeRunLogRequest) bool {
+			return req.Iteration != nil && *req.Iteration == index
+		}))
+	}
+}
+
+func TestWorkflowEngine_Execute_LoopBreakWhen(t *testing.T) {
+	node := &echoNode{}
+	RegisterNode("test_echo_break", func() domain.INodeExecutor { return node })
+
+	engine, loopID, _, err := runLoopWorkflow(t, "test_echo_break", map[string]interface{}{
+		"items":      []interface{}{false, true, false, false},
+		"mode":       "sequential",
+		"break_when": "{{ $iteration.output.value }}",
+	})
+
+	assert.NoError(t, err)
+	assert.Len(t, node.order, 2)
+	assert.Len(t, engine.nodeOutputs[loopID]["results"], 2)
+}
+
+func TestWorkflowEngine_Execute_LoopBreakWhenParallel(t *testing.T) {
+	node := &echoNode{}
+	RegisterNode("test_echo_break_parallel", func() domain.INodeExecutor { return node })
+
+	engine, loopID, _, err := runLoopWorkflow(t, "test_echo_break_parallel", map[string]interface{}{
+		"items":       []interface{}{false, true, false, false, false, false},
+		"concurrency": 2,
+		"break_when":  "{{ $iteration.output.value }}",
+	})
+
+	assert.NoError(t, err)
+	results := engine.nodeOutputs[loopID]["results"].([]interface{})
+	assert.Len(t, results, len(node.order))
+	assert.NotContains(t, results, nil)
+}
+
+func TestLoopRun_FinishedResults(t *testing.T) {
+	loop := &loopRun{
+		results:  []interface{}{"a", nil, "c", nil},
+		finished: []bool{true, false, true, false},
+	}
+	assert.Equal(t, []interface{}{"a", "c"}, loop.finishedResults())
+}
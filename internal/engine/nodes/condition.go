@@ -12,6 +12,10 @@ import (
 
 type ConditionNode struct{}
 
+func (n *ConditionNode) RequiredFields() []string {
+	return []string{"expression"}
+}
+
 type conditionData struct {
 	Expression string                 `json:"expression"`
 	Input      map[string]interface{} `json:"input"`
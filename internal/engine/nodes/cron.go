@@ -7,17 +7,25 @@ import (
 	"github.com/mr-isik/loki-backend/internal/domain"
 )
 
+// CronNode starts a workflow on a schedule. The scheduler fires it with the
+// fire time as the timestamp of the run input; the node outputs that payload,
+// plus the current time unless the payload sets a timestamp.
 type CronNode struct{}
 
+func (n *CronNode) RequiredFields() []string {
+	return []string{"expression"}
+}
+
+func (n *CronNode) IsTrigger() bool { return true }
+
 func (n *CronNode) Execute(ctx context.Context, rawData []byte) (*domain.NodeResult, error) {
-	// CronNode is a trigger. It usually passes the current time.
+	result, err := triggerResult(rawData, "Cron triggered")
+	if err != nil {
+		return result, err
+	}
 
-	return &domain.NodeResult{
-		Status:          "completed",
-		TriggeredHandle: "output",
-		Log:             "Cron triggered",
-		OutputData: map[string]interface{}{
-			"timestamp": time.Now().Format(time.RFC3339),
-		},
-	}, nil
+	if _, ok := result.OutputData["timestamp"]; !ok {
+		result.OutputData["timestamp"] = time.Now().Format(time.RFC3339)
+	}
+	return result, nil
 }
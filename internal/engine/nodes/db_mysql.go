@@ -12,6 +12,10 @@ import (
 
 type DbMysqlNode struct{}
 
+func (n *DbMysqlNode) RequiredFields() []string {
+	return []string{"host", "user", "dbname", "query"}
+}
+
 type dbMysqlData struct {
 	Host     string `json:"host"`
 	Port     int    `json:"port"`
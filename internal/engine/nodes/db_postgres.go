@@ -15,6 +15,10 @@ import (
 
 type DbPostgresNode struct{}
 
+func (n *DbPostgresNode) RequiredFields() []string {
+	return []string{"host", "user", "dbname", "query"}
+}
+
 // dbPool caches connection pools based on their secure DSN representation
 // avoiding repeating the SSL handshake and connection logic overhead per node execution
 var dbPool sync.Map
@@ -0,0 +1,22 @@
+package nodes
+
+import (
+	"context"
+
+	"github.com/mr-isik/loki-backend/internal/domain"
+)
+
+// DbPostgresListenNode starts a workflow for every notification sent on a
+// Postgres channel. The listener fires it with the notification as the run
+// input; the node outputs that payload.
+type DbPostgresListenNode struct{}
+
+func (n *DbPostgresListenNode) RequiredFields() []string {
+	return []string{"host", "user", "dbname", "channel"}
+}
+
+func (n *DbPostgresListenNode) IsTrigger() bool { return true }
+
+func (n *DbPostgresListenNode) Execute(ctx context.Context, rawData []byte) (*domain.NodeResult, error) {
+	return triggerResult(rawData, "Notification received")
+}
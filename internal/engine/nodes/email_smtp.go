@@ -13,6 +13,12 @@ import (
 
 type EmailSmtpNode struct{}
 
+// RequiredFields leaves out body, since html_body or attachments can carry the
+// content instead.
+func (n *EmailSmtpNode) RequiredFields() []string {
+	return []string{"host", "from", "to", "subject"}
+}
+
 type Attachment struct {
 	Filename      string `json:"filename"`
 	ContentBase64 string `json:"content_base64"`
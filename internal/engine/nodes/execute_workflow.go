@@ -0,0 +1,103 @@
+package nodes
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/mr-isik/loki-backend/internal/domain"
+)
+
+// ExecuteWorkflowNode runs another workflow of the same workspace. It needs a
+// Runner, so it is registered at startup rather than in the default registry.
+type ExecuteWorkflowNode struct {
+	Runner domain.SubWorkflowRunner
+}
+
+func (n *ExecuteWorkflowNode) RequiredFields() []string {
+	return []string{"workflow_id"}
+}
+
+type executeWorkflowData struct {
+	WorkflowID string `json:"workflow_id"`
+	// Payload is the input of the sub-workflow run. When it is empty, the
+	// node's own input is passed on.
+	Payload map[string]interface{} `json:"payload"`
+	// Wait makes the node wait for the sub-workflow to finish (default true).
+	Wait  *bool                  `json:"wait"`
+	Input map[string]interface{} `json:"input"`
+}
+
+func (n *ExecuteWorkflowNode) Execute(ctx context.Context, rawData []byte) (*domain.NodeResult, error) {
+	var data executeWorkflowData
+	if err := json.Unmarshal(rawData, &data); err != nil {
+		return &domain.NodeResult{
+			Status:     "failed",
+			Log:        fmt.Sprintf("Failed to parse input: %v", err),
+			OutputData: map[string]interface{}{"error": err.Error()},
+		}, err
+	}
+
+	workflowID, err := uuid.Parse(data.WorkflowID)
+	if err != nil {
+		return &domain.NodeResult{
+			Status:     "failed",
+			Log:        "A valid workflow_id is required",
+			OutputData: map[string]interface{}{"error": "invalid workflow_id"},
+		}, fmt.Errorf("invalid workflow_id: %w", err)
+	}
+
+	info, ok := domain.NodeRunInfoFromContext(ctx)
+	if !ok || n.Runner == nil {
+		err := errors.New("sub-workflows can only be executed within a workflow run")
+		return &domain.NodeResult{
+			Status:     "failed",
+			Log:        err.Error(),
+			OutputData: map[string]interface{}{"error": err.Error()},
+		}, err
+	}
+
+	payload := data.Payload
+	if len(payload) == 0 {
+		payload = data.Input
+	}
+	wait := data.Wait == nil || *data.Wait
+
+	result, err := n.Runner.RunSubWorkflow(ctx, &domain.SubWorkflowRequest{
+		ParentRunID:  info.RunID,
+		ParentNodeID: info.NodeID,
+		WorkflowID:   workflowID,
+		Input:        payload,
+		Wait:         wait,
+	})
+	if err != nil {
+		output := map[string]interface{}{"error": err.Error()}
+		if result != nil {
+			output["run_id"] = result.RunID.String()
+			output["status"] = string(result.Status)
+		}
+		return &domain.NodeResult{
+			Status:     "failed",
+			Log:        fmt.Sprintf("Sub-workflow failed: %v", err),
+			OutputData: output,
+		}, err
+	}
+
+	log := fmt.Sprintf("Sub-workflow run %s %s", result.RunID, result.Status)
+	if !wait {
+		log = fmt.Sprintf("Sub-workflow run %s queued", result.RunID)
+	}
+
+	return &domain.NodeResult{
+		Status:          "completed",
+		TriggeredHandle: "output_success",
+		Log:             log,
+		OutputData: map[string]interface{}{
+			"run_id": result.RunID.String(),
+			"status": string(result.Status),
+			"output": result.Output,
+		},
+	}, nil
+}
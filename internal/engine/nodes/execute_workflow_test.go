@@ -0,0 +1,94 @@
+package nodes
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/mr-isik/loki-backend/internal/domain"
+)
+
+type fakeSubWorkflowRunner struct {
+	req    *domain.SubWorkflowRequest
+	result *domain.SubWorkflowResult
+	err    error
+}
+
+func (r *fakeSubWorkflowRunner) RunSubWorkflow(ctx context.Context, req *domain.SubWorkflowRequest) (*domain.SubWorkflowResult, error) {
+	r.req = req
+	return r.result, r.err
+}
+
+func TestExecuteWorkflowNode_Execute(t *testing.T) {
+	parentRunID := uuid.New()
+	parentNodeID := uuid.New()
+	childWorkflowID := uuid.New()
+	ctx := domain.WithNodeRunInfo(context.Background(), domain.NodeRunInfo{RunID: parentRunID, NodeID: parentNodeID})
+
+	t.Run("Waits For Result", func(t *testing.T) {
+		runner := &fakeSubWorkflowRunner{result: &domain.SubWorkflowResult{
+			RunID:  uuid.New(),
+			Status: domain.WorkflowRunStatusCompleted,
+			Output: map[string]interface{}{"score": 42},
+		}}
+		node := &ExecuteWorkflowNode{Runner: runner}
+
+		input, _ := json.Marshal(map[string]interface{}{
+			"workflow_id": childWorkflowID.String(),
+			"input":       map[string]interface{}{"input": "upstream"},
+		})
+		result, err := node.Execute(ctx, input)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		if result.TriggeredHandle != "output_success" {
+			t.Errorf("Expected handle output_success, got %s", result.TriggeredHandle)
+		}
+		if runner.req.ParentRunID != parentRunID || runner.req.ParentNodeID != parentNodeID || runner.req.WorkflowID != childWorkflowID || !runner.req.Wait {
+			t.Errorf("Unexpected request %+v", runner.req)
+		}
+		if runner.req.Input["input"] != "upstream" {
+			t.Errorf("Expected the node input to be passed on, got %v", runner.req.Input)
+		}
+		if result.OutputData["output"].(map[string]interface{})["score"] != 42 {
+			t.Errorf("Expected sub-workflow output, got %v", result.OutputData)
+		}
+	})
+
+	t.Run("Child Failure", func(t *testing.T) {
+		runner := &fakeSubWorkflowRunner{
+			result: &domain.SubWorkflowResult{RunID: uuid.New(), Status: domain.WorkflowRunStatusFailed},
+			err:    errors.New("boom"),
+		}
+		node := &ExecuteWorkflowNode{Runner: runner}
+
+		input, _ := json.Marshal(map[string]interface{}{
+			"workflow_id": childWorkflowID.String(),
+			"payload":     map[string]interface{}{"id": 1},
+			"wait":        false,
+		})
+		result, err := node.Execute(ctx, input)
+		if err == nil {
+			t.Fatal("Expected an error")
+		}
+
+		if result.Status != "failed" || result.OutputData["status"] != "failed" {
+			t.Errorf("Expected a failed result, got %v", result.OutputData)
+		}
+		if runner.req.Wait || runner.req.Input["id"] != float64(1) {
+			t.Errorf("Unexpected request %+v", runner.req)
+		}
+	})
+
+	t.Run("Outside A Run", func(t *testing.T) {
+		node := &ExecuteWorkflowNode{Runner: &fakeSubWorkflowRunner{}}
+
+		input, _ := json.Marshal(map[string]interface{}{"workflow_id": childWorkflowID.String()})
+		if _, err := node.Execute(context.Background(), input); err == nil {
+			t.Error("Expected an error without run information")
+		}
+	})
+}
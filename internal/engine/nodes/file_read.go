@@ -13,6 +13,10 @@ import (
 
 type FileReadNode struct{}
 
+func (n *FileReadNode) RequiredFields() []string {
+	return []string{"path"}
+}
+
 type fileReadData struct {
 	Path string `json:"path"`
 }
@@ -13,6 +13,10 @@ import (
 
 type FileWriteNode struct{}
 
+func (n *FileWriteNode) RequiredFields() []string {
+	return []string{"path"}
+}
+
 type fileWriteData struct {
 	Path    string `json:"path"`
 	Content string `json:"content"`
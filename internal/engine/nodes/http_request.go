@@ -15,6 +15,10 @@ import (
 
 type HttpRequestNode struct{}
 
+func (n *HttpRequestNode) RequiredFields() []string {
+	return []string{"url"}
+}
+
 type httpData struct {
 	URL         string            `json:"url"`
 	Method      string            `json:"method"`
@@ -12,6 +12,10 @@ import (
 
 type CodeJsNode struct{}
 
+func (n *CodeJsNode) RequiredFields() []string {
+	return []string{"code"}
+}
+
 type codeJsData struct {
 	Code  string                 `json:"code"`
 	Input map[string]interface{} `json:"input"`
@@ -10,8 +10,19 @@ import (
 
 type LoopNode struct{}
 
+// RequiredFields leaves out mode, concurrency and batch_size, which have defaults.
+func (n *LoopNode) RequiredFields() []string {
+	return []string{"items"}
+}
+
 type loopData struct {
 	Items interface{} `json:"items"`
+	// Mode is "parallel" (default) or "sequential", which runs one iteration at a time in order.
+	Mode string `json:"mode"`
+	// Concurrency limits the iterations running at the same time (0 uses the server default).
+	Concurrency int `json:"concurrency"`
+	// BatchSize splits the items into batches that run one after another (0 runs a single batch).
+	BatchSize int `json:"batch_size"`
 }
 
 func (n *LoopNode) Execute(ctx context.Context, rawData []byte) (*domain.NodeResult, error) {
@@ -45,6 +56,27 @@ func (n *LoopNode) Execute(ctx context.Context, rawData []byte) (*domain.NodeRes
 	// The LoopNode returns the list of items in `OutputData`.
 	// The ENGINE sees "output_item" handle and the list, and spawns execution for each item.
 
+	if data.Mode == "" {
+		data.Mode = "parallel"
+	}
+	if data.Mode != "parallel" && data.Mode != "sequential" {
+		err := fmt.Errorf("unsupported loop mode %q", data.Mode)
+		return &domain.NodeResult{
+			Status:     "failed",
+			Log:        err.Error(),
+			OutputData: map[string]interface{}{"error": err.Error()},
+		}, err
+	}
+
+	if data.Concurrency < 0 || data.BatchSize < 0 {
+		err := fmt.Errorf("concurrency and batch_size must not be negative")
+		return &domain.NodeResult{
+			Status:     "failed",
+			Log:        err.Error(),
+			OutputData: map[string]interface{}{"error": err.Error()},
+		}, err
+	}
+
 	// Let's normalize the input to a slice.
 	items, err := toSlice(data.Items)
 	if err != nil {
@@ -60,7 +92,10 @@ func (n *LoopNode) Execute(ctx context.Context, rawData []byte) (*domain.NodeRes
 		TriggeredHandle: "output_item", // The engine should probably handle this special case
 		Log:             fmt.Sprintf("Looping over %d items", len(items)),
 		OutputData: map[string]interface{}{
-			"items": items,
+			"items":       items,
+			"mode":        data.Mode,
+			"concurrency": data.Concurrency,
+			"batch_size":  data.BatchSize,
 		},
 	}, nil
 }
@@ -47,4 +47,34 @@ func TestLoopNode_Execute(t *testing.T) {
 			t.Errorf("Expected 2 items, got %d", len(items))
 		}
 	})
+
+	t.Run("Concurrency And Batch Size", func(t *testing.T) {
+		input := map[string]interface{}{
+			"items":       []interface{}{1, 2, 3},
+			"concurrency": 2,
+			"batch_size":  2,
+		}
+		inputBytes, _ := json.Marshal(input)
+
+		result, err := node.Execute(ctx, inputBytes)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		if result.OutputData["concurrency"] != 2 || result.OutputData["batch_size"] != 2 {
+			t.Errorf("Expected concurrency and batch_size in output, got %v", result.OutputData)
+		}
+	})
+
+	t.Run("Negative Concurrency", func(t *testing.T) {
+		input := map[string]interface{}{
+			"items":       []interface{}{1},
+			"concurrency": -1,
+		}
+		inputBytes, _ := json.Marshal(input)
+
+		if _, err := node.Execute(ctx, inputBytes); err == nil {
+			t.Error("Expected an error for negative concurrency")
+		}
+	})
 }
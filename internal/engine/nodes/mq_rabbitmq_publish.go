@@ -11,6 +11,10 @@ import (
 
 type MqRabbitmqPublishNode struct{}
 
+func (n *MqRabbitmqPublishNode) RequiredFields() []string {
+	return []string{"url", "message"}
+}
+
 type rabbitmqData struct {
 	URL        string `json:"url"`
 	Queue      string `json:"queue"`
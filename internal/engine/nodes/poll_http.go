@@ -0,0 +1,22 @@
+package nodes
+
+import (
+	"context"
+
+	"github.com/mr-isik/loki-backend/internal/domain"
+)
+
+// PollHttpNode starts a workflow for every new item returned by an HTTP
+// endpoint. The poller fires it with the item as the run input; the node
+// outputs that payload.
+type PollHttpNode struct{}
+
+func (n *PollHttpNode) RequiredFields() []string {
+	return []string{"url"}
+}
+
+func (n *PollHttpNode) IsTrigger() bool { return true }
+
+func (n *PollHttpNode) Execute(ctx context.Context, rawData []byte) (*domain.NodeResult, error) {
+	return triggerResult(rawData, "Poll triggered")
+}
@@ -0,0 +1,49 @@
+package nodes
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/mr-isik/loki-backend/internal/domain"
+)
+
+// RespondNode sets the result of a run, which synchronous runs return to
+// the caller instead of the outputs of the leaf nodes.
+type RespondNode struct{}
+
+type respondData struct {
+	// Body is the result of the run. When it is empty, the data arriving at
+	// the node's input is used.
+	Body  map[string]interface{} `json:"body"`
+	Input map[string]interface{} `json:"input"`
+}
+
+func (n *RespondNode) Execute(ctx context.Context, rawData []byte) (*domain.NodeResult, error) {
+	var data respondData
+	if err := json.Unmarshal(rawData, &data); err != nil {
+		return &domain.NodeResult{
+			Status:     "failed",
+			Log:        fmt.Sprintf("Failed to parse input: %v", err),
+			OutputData: map[string]interface{}{"error": err.Error()},
+		}, err
+	}
+
+	body := data.Body
+	if len(body) == 0 {
+		body = data.Input
+		if input, ok := data.Input["input"].(map[string]interface{}); ok {
+			body = input
+		}
+	}
+	if body == nil {
+		body = map[string]interface{}{}
+	}
+
+	return &domain.NodeResult{
+		Status:          "completed",
+		TriggeredHandle: "output",
+		Log:             fmt.Sprintf("Responded with %d keys", len(body)),
+		OutputData:      body,
+	}, nil
+}
@@ -0,0 +1,117 @@
+package nodes
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/mr-isik/loki-backend/internal/domain"
+)
+
+// Operations of the set_variable node.
+const (
+	SetVariableOperationSet       = "set"
+	SetVariableOperationIncrement = "increment"
+	SetVariableOperationAppend    = "append"
+)
+
+// SetVariableNode writes a run variable, which every later node can read as
+// $vars.<name> without being connected to it.
+type SetVariableNode struct{}
+
+func (n *SetVariableNode) RequiredFields() []string {
+	return []string{"name"}
+}
+
+type setVariableData struct {
+	Name string `json:"name"`
+	// Operation is set (default), increment or append.
+	Operation string      `json:"operation"`
+	Value     interface{} `json:"value"`
+}
+
+func (n *SetVariableNode) Execute(ctx context.Context, rawData []byte) (*domain.NodeResult, error) {
+	var data setVariableData
+	if err := json.Unmarshal(rawData, &data); err != nil {
+		return &domain.NodeResult{
+			Status:     "failed",
+			Log:        fmt.Sprintf("Failed to parse input: %v", err),
+			OutputData: map[string]interface{}{"error": err.Error()},
+		}, err
+	}
+
+	fail := func(err error) (*domain.NodeResult, error) {
+		return &domain.NodeResult{
+			Status:     "failed",
+			Log:        err.Error(),
+			OutputData: map[string]interface{}{"error": err.Error()},
+		}, err
+	}
+
+	if data.Name == "" {
+		return fail(errors.New("name is required"))
+	}
+	vars, ok := domain.RunVariablesFromContext(ctx)
+	if !ok {
+		return fail(errors.New("variables can only be set within a workflow run"))
+	}
+
+	var update func(current interface{}, exists bool) (interface{}, error)
+	switch data.Operation {
+	case "", SetVariableOperationSet:
+		update = func(interface{}, bool) (interface{}, error) {
+			return data.Value, nil
+		}
+	case SetVariableOperationIncrement:
+		update = func(current interface{}, exists bool) (interface{}, error) {
+			step := 1.0
+			if data.Value != nil {
+				v, ok := data.Value.(float64)
+				if !ok {
+					return nil, fmt.Errorf("increment value must be a number, got %T", data.Value)
+				}
+				step = v
+			}
+			if !exists || current == nil {
+				return step, nil
+			}
+			n, ok := current.(float64)
+			if !ok {
+				return nil, fmt.Errorf("variable %q is not a number", data.Name)
+			}
+			return n + step, nil
+		}
+	case SetVariableOperationAppend:
+		update = func(current interface{}, exists bool) (interface{}, error) {
+			if !exists || current == nil {
+				return []interface{}{data.Value}, nil
+			}
+			list, ok := current.([]interface{})
+			if !ok {
+				return nil, fmt.Errorf("variable %q is not a list", data.Name)
+			}
+			// Copy so that readers holding the old list never see it change.
+			appended := make([]interface{}, len(list), len(list)+1)
+			copy(appended, list)
+			return append(appended, data.Value), nil
+		}
+	default:
+		return fail(fmt.Errorf("unknown operation %q", data.Operation))
+	}
+
+	value, err := vars.UpdateVariable(data.Name, update)
+	if err != nil {
+		return fail(err)
+	}
+
+	return &domain.NodeResult{
+		Status:          "completed",
+		TriggeredHandle: "output",
+		Log:             fmt.Sprintf("Variable %q updated", data.Name),
+		OutputData: map[string]interface{}{
+			"name":  data.Name,
+			"value": value,
+		},
+	}, nil
+}
@@ -13,6 +13,10 @@ import (
 
 type ShellCommandNode struct{}
 
+func (n *ShellCommandNode) RequiredFields() []string {
+	return []string{"command"}
+}
+
 type shellData struct {
 	Command string   `json:"command"`
 	Args    []string `json:"args"`
@@ -12,6 +12,10 @@ import (
 
 type SlackNode struct{}
 
+func (n *SlackNode) RequiredFields() []string {
+	return []string{"webhook_url", "message"}
+}
+
 type slackData struct {
 	WebhookURL string `json:"webhook_url"`
 	Message    string `json:"message"`
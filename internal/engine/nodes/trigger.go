@@ -0,0 +1,60 @@
+package nodes
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/mr-isik/loki-backend/internal/domain"
+)
+
+type triggerData struct {
+	// Trigger is the input payload of the run, set by the engine.
+	Trigger map[string]interface{} `json:"trigger"`
+}
+
+// parseTrigger returns a copy of the run input passed to a trigger node.
+func parseTrigger(rawData []byte) (map[string]interface{}, error) {
+	output := make(map[string]interface{})
+	if len(rawData) == 0 {
+		return output, nil
+	}
+
+	var data triggerData
+	if err := json.Unmarshal(rawData, &data); err != nil {
+		return nil, err
+	}
+	for k, v := range data.Trigger {
+		output[k] = v
+	}
+	return output, nil
+}
+
+// triggerResult builds the result of a trigger node whose output is the run input.
+func triggerResult(rawData []byte, log string) (*domain.NodeResult, error) {
+	output, err := parseTrigger(rawData)
+	if err != nil {
+		return &domain.NodeResult{
+			Status:     "failed",
+			Log:        fmt.Sprintf("Failed to parse input: %v", err),
+			OutputData: map[string]interface{}{"error": err.Error()},
+		}, err
+	}
+
+	return &domain.NodeResult{
+		Status:          "completed",
+		TriggeredHandle: "output",
+		Log:             log,
+		OutputData:      output,
+	}, nil
+}
+
+// ManualTriggerNode starts a workflow run from the API. It outputs the
+// payload the run was started with.
+type ManualTriggerNode struct{}
+
+func (n *ManualTriggerNode) IsTrigger() bool { return true }
+
+func (n *ManualTriggerNode) Execute(ctx context.Context, rawData []byte) (*domain.NodeResult, error) {
+	return triggerResult(rawData, "Manual trigger")
+}
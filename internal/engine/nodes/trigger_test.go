@@ -9,7 +9,7 @@ func TestWebhookNode_Execute(t *testing.T) {
 	node := &WebhookNode{}
 	ctx := context.Background()
 
-	input := []byte(`{"foo":"bar"}`)
+	input := []byte(`{"trigger":{"foo":"bar"},"input":{}}`)
 	result, err := node.Execute(ctx, input)
 	if err != nil {
 		t.Fatalf("Expected no error, got %v", err)
@@ -19,9 +19,28 @@ func TestWebhookNode_Execute(t *testing.T) {
 		t.Errorf("Expected status completed, got %s", result.Status)
 	}
 
-	payload := result.OutputData["payload"].(string)
-	if payload != `{"foo":"bar"}` {
-		t.Errorf("Expected payload %s, got %s", `{"foo":"bar"}`, payload)
+	if result.OutputData["foo"] != "bar" {
+		t.Errorf("Expected foo to be bar, got %v", result.OutputData["foo"])
+	}
+	if _, ok := result.OutputData["input"]; ok {
+		t.Error("Expected only the trigger payload in output")
+	}
+}
+
+func TestManualTriggerNode_Execute(t *testing.T) {
+	node := &ManualTriggerNode{}
+	ctx := context.Background()
+
+	result, err := node.Execute(ctx, []byte(`{"trigger":null}`))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if result.TriggeredHandle != "output" {
+		t.Errorf("Expected handle output, got %s", result.TriggeredHandle)
+	}
+	if result.OutputData == nil || len(result.OutputData) != 0 {
+		t.Errorf("Expected empty output, got %v", result.OutputData)
 	}
 }
 
@@ -42,3 +61,49 @@ func TestCronNode_Execute(t *testing.T) {
 		t.Error("Expected timestamp in output")
 	}
 }
+
+func TestCronNode_Execute_KeepsTimestamp(t *testing.T) {
+	node := &CronNode{}
+
+	result, err := node.Execute(context.Background(), []byte(`{"trigger":{"timestamp":"2024-01-01T09:00:00Z"}}`))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if result.OutputData["timestamp"] != "2024-01-01T09:00:00Z" {
+		t.Errorf("Expected the scheduled timestamp, got %v", result.OutputData["timestamp"])
+	}
+}
+
+func TestPollHttpNode_Execute(t *testing.T) {
+	node := &PollHttpNode{}
+	ctx := context.Background()
+
+	input := []byte(`{"trigger":{"item":{"id":3},"item_key":"id:3"},"input":{}}`)
+	result, err := node.Execute(ctx, input)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if result.TriggeredHandle != "output" {
+		t.Errorf("Expected handle output, got %s", result.TriggeredHandle)
+	}
+	if result.OutputData["item_key"] != "id:3" {
+		t.Errorf("Expected item_key id:3, got %v", result.OutputData["item_key"])
+	}
+}
+
+func TestDbPostgresListenNode_Execute(t *testing.T) {
+	node := &DbPostgresListenNode{}
+	ctx := context.Background()
+
+	input := []byte(`{"trigger":{"channel":"events","payload":{"id":1}},"input":{}}`)
+	result, err := node.Execute(ctx, input)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if result.OutputData["channel"] != "events" {
+		t.Errorf("Expected channel events, got %v", result.OutputData["channel"])
+	}
+}
@@ -4,7 +4,10 @@ import (
 	"context"
 	"encoding/json"
 	"path/filepath"
+	"reflect"
 	"testing"
+
+	"github.com/mr-isik/loki-backend/internal/domain"
 )
 
 func TestLogNode_Execute(t *testing.T) {
@@ -89,3 +92,74 @@ func TestFileReadWriteNode_Execute(t *testing.T) {
 		}
 	})
 }
+
+func TestRespondNode_Execute(t *testing.T) {
+	node := &RespondNode{}
+	ctx := context.Background()
+
+	t.Run("Body", func(t *testing.T) {
+		result, err := node.Execute(ctx, []byte(`{"body":{"ok":true},"input":{"input":{"ignored":1}}}`))
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if result.OutputData["ok"] != true || len(result.OutputData) != 1 {
+			t.Errorf("Expected the body as output, got %v", result.OutputData)
+		}
+	})
+
+	t.Run("Input", func(t *testing.T) {
+		result, err := node.Execute(ctx, []byte(`{"input":{"input":{"total":3}}}`))
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if result.OutputData["total"] != float64(3) {
+			t.Errorf("Expected the input as output, got %v", result.OutputData)
+		}
+	})
+}
+
+// mapVariables is a RunVariables backed by a plain map.
+type mapVariables map[string]interface{}
+
+func (m mapVariables) UpdateVariable(name string, fn func(current interface{}, exists bool) (interface{}, error)) (interface{}, error) {
+	current, exists := m[name]
+	value, err := fn(current, exists)
+	if err != nil {
+		return nil, err
+	}
+	m[name] = value
+	return value, nil
+}
+
+func TestSetVariableNode_Execute(t *testing.T) {
+	node := &SetVariableNode{}
+	vars := mapVariables{}
+	ctx := domain.WithRunVariables(context.Background(), vars)
+
+	steps := []struct {
+		data string
+		want interface{}
+	}{
+		{`{"name":"count","operation":"increment"}`, float64(1)},
+		{`{"name":"count","operation":"increment","value":2}`, float64(3)},
+		{`{"name":"flag","value":true}`, true},
+		{`{"name":"seen","operation":"append","value":"a"}`, []interface{}{"a"}},
+		{`{"name":"seen","operation":"append","value":"b"}`, []interface{}{"a", "b"}},
+	}
+	for _, step := range steps {
+		result, err := node.Execute(ctx, []byte(step.data))
+		if err != nil {
+			t.Fatalf("%s: expected no error, got %v", step.data, err)
+		}
+		if !reflect.DeepEqual(result.OutputData["value"], step.want) {
+			t.Errorf("%s: expected %v, got %v", step.data, step.want, result.OutputData["value"])
+		}
+	}
+
+	if _, err := node.Execute(ctx, []byte(`{"name":"flag","operation":"increment"}`)); err == nil {
+		t.Error("Expected an error when incrementing a non-number")
+	}
+	if _, err := node.Execute(context.Background(), []byte(`{"name":"count"}`)); err == nil {
+		t.Error("Expected an error outside of a workflow run")
+	}
+}
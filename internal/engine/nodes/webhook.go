@@ -6,27 +6,17 @@ import (
 	"github.com/mr-isik/loki-backend/internal/domain"
 )
 
+// WebhookNode starts a workflow from an HTTP request. It outputs the payload
+// the run was started with.
 type WebhookNode struct{}
 
-func (n *WebhookNode) Execute(ctx context.Context, rawData []byte) (*domain.NodeResult, error) {
-	// WebhookNode is usually a trigger. When executed (e.g. manually or by the system passing initial data),
-	// it just passes the data through.
-
-	// In a real scenario, rawData might contain the webhook payload.
-	// We'll just parse it as generic map if possible, or pass as is.
-
-	// Since we don't know the structure, we just pass it to OutputData.
-	// If rawData is JSON, we could try to unmarshal it, but for now let's assume rawData IS the payload
-	// or we just return it as "payload".
+func (n *WebhookNode) IsTrigger() bool { return true }
 
-	// Let's try to unmarshal to map[string]interface{} to be nicer, but fallback to string.
+// RequiredFields makes webhooks choose their authentication explicitly.
+func (n *WebhookNode) RequiredFields() []string {
+	return []string{"auth.type"}
+}
 
-	return &domain.NodeResult{
-		Status:          "completed",
-		TriggeredHandle: "output",
-		Log:             "Webhook triggered",
-		OutputData: map[string]interface{}{
-			"payload": string(rawData), // Simple pass-through for now
-		},
-	}, nil
+func (n *WebhookNode) Execute(ctx context.Context, rawData []byte) (*domain.NodeResult, error) {
+	return triggerResult(rawData, "Webhook triggered")
 }
@@ -0,0 +1,283 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mr-isik/loki-backend/internal/domain"
+)
+
+// RunObserver receives the lifecycle events of a run. Observers are called
+// synchronously, from the goroutines executing the nodes, so they must be
+// safe for concurrent use and should return quickly.
+//
+// Errors returned from OnRunStart and OnNodeStart stop the run or node;
+// errors from the finish events are reported but do not change the outcome.
+type RunObserver interface {
+	OnRunStart(ctx context.Context, event RunEvent) error
+	OnNodeStart(ctx context.Context, event NodeEvent) error
+	OnNodeFinish(ctx context.Context, event NodeEvent) error
+	OnRunFinish(ctx context.Context, event RunEvent) error
+}
+
+// AttemptObserver is implemented by observers that record the attempts of
+// nodes with a retry policy as each one ends, rather than only once the node
+// has finished, so that earlier attempts survive a crash or a lost lease.
+type AttemptObserver interface {
+	OnNodeAttempt(ctx context.Context, event NodeEvent, attempt domain.NodeRunAttempt) error
+}
+
+// RunEvent describes a run that started or finished. Loop iterations do not
+// produce run events.
+type RunEvent struct {
+	RunID      uuid.UUID
+	WorkflowID uuid.UUID
+	Status     domain.WorkflowRunStatus
+	// Resumed marks a run continued from its checkpoints.
+	Resumed bool
+	// Output is the result of a completed run.
+	Output map[string]interface{}
+	// Err is the reason a run failed.
+	Err  error
+	Time time.Time
+}
+
+// NodeEvent describes a node that started or finished executing. Skipped
+// nodes only produce a finish event.
+type NodeEvent struct {
+	// ExecutionID identifies one execution of a node, so that observers can
+	// match its start and finish events.
+	ExecutionID uuid.UUID
+	RunID       uuid.UUID
+	WorkflowID  uuid.UUID
+	NodeID      uuid.UUID
+	NodeType    string
+	// Iteration is the loop iteration index of nodes that run inside a loop.
+	Iteration *int
+	Pinned    bool
+	Status    domain.NodeRunLogStatus
+	// The fields below are set on finish events. InputData and OutputData
+	// are redacted and limited to Config.MaxLogPayloadBytes.
+	TriggeredHandle string
+	Log             string
+	ErrorMsg        string
+	InputData       map[string]interface{}
+	OutputData      map[string]interface{}
+	// Attempts holds every attempt of a node that has a retry policy. Each
+	// attempt is also reported to AttemptObservers when it ends.
+	Attempts []domain.NodeRunAttempt
+	Time     time.Time
+}
+
+// AddObserver registers an observer for the events of the run.
+func (e *WorkflowEngine) AddObserver(observer RunObserver) {
+	e.observers = append(e.observers, observer)
+}
+
+func (e *WorkflowEngine) notifyRunStart(ctx context.Context, resumed bool) error {
+	event := RunEvent{
+		RunID:      e.RunID,
+		WorkflowID: e.WorkflowID,
+		Status:     domain.WorkflowRunStatusRunning,
+		Resumed:    resumed,
+		Time:       time.Now(),
+	}
+	for _, observer := range e.observers {
+		if err := observer.OnRunStart(ctx, event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// notifyRunFinish reports the outcome of a run. Observers are notified even
+// when the run context has been cancelled or timed out, but not when the run
+// was abandoned after losing its lease.
+func (e *WorkflowEngine) notifyRunFinish(ctx context.Context, status domain.WorkflowRunStatus, output map[string]interface{}, runErr error) error {
+	if isAbandoned(ctx) {
+		return nil
+	}
+	event := RunEvent{
+		RunID:      e.RunID,
+		WorkflowID: e.WorkflowID,
+		Status:     status,
+		Output:     output,
+		Err:        runErr,
+		Time:       time.Now(),
+	}
+	var errs []error
+	for _, observer := range e.observers {
+		if err := observer.OnRunFinish(context.WithoutCancel(ctx), event); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// startNode reports that a node started executing and returns the event
+// that finishNode completes.
+func (e *WorkflowEngine) startNode(ctx context.Context, node domain.WorkflowNode, pinned bool) (NodeEvent, error) {
+	nodeType, _ := node.Data["type"].(string)
+	event := NodeEvent{
+		ExecutionID: uuid.New(),
+		RunID:       e.RunID,
+		WorkflowID:  e.WorkflowID,
+		NodeID:      node.ID,
+		NodeType:    nodeType,
+		Iteration:   e.iteration,
+		Pinned:      pinned,
+		Status:      domain.NodeRunLogStatusRunning,
+		Time:        time.Now(),
+	}
+	for _, observer := range e.observers {
+		if err := observer.OnNodeStart(ctx, event); err != nil {
+			return event, err
+		}
+	}
+	return event, nil
+}
+
+// finishNode reports the outcome of a node started with startNode, together
+// with its input and output payloads, redacted and limited to the configured
+// size.
+func (e *WorkflowEngine) finishNode(ctx context.Context, event NodeEvent, status domain.NodeRunLogStatus, log, errorMsg string, inputData, outputData map[string]interface{}) error {
+	event.Status = status
+	event.Log = log
+	event.ErrorMsg = errorMsg
+	event.InputData = LimitPayload(RedactPayload(inputData), e.Config.MaxLogPayloadBytes)
+	event.OutputData = LimitPayload(RedactPayload(outputData), e.Config.MaxLogPayloadBytes)
+	event.Time = time.Now()
+	return e.notifyNodeFinish(ctx, event)
+}
+
+// notifyNodeAttempt reports an attempt of a node that has a retry policy to
+// the observers that record attempts.
+func (e *WorkflowEngine) notifyNodeAttempt(ctx context.Context, event NodeEvent, attempt domain.NodeRunAttempt) error {
+	if isAbandoned(ctx) {
+		return nil
+	}
+	var errs []error
+	for _, observer := range e.observers {
+		if recorder, ok := observer.(AttemptObserver); ok {
+			if err := recorder.OnNodeAttempt(context.WithoutCancel(ctx), event, attempt); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// notifyNodeFinish delivers a finish event. Finished nodes must be reported
+// even when the run context has been cancelled or timed out, unless the run
+// was abandoned.
+func (e *WorkflowEngine) notifyNodeFinish(ctx context.Context, event NodeEvent) error {
+	if isAbandoned(ctx) {
+		return nil
+	}
+	var errs []error
+	for _, observer := range e.observers {
+		if err := observer.OnNodeFinish(context.WithoutCancel(ctx), event); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// RepositoryObserver records runs in the database: it keeps the run status
+// and output in the run repository and writes a node run log for every node.
+type RepositoryObserver struct {
+	logRepo domain.NodeRunLogRepository
+	runRepo domain.WorkflowRunRepository
+
+	// logIDs maps the execution IDs of running nodes to their log entries.
+	logIDs sync.Map
+}
+
+// NewRepositoryObserver creates an observer that records runs through the
+// given repositories.
+func NewRepositoryObserver(logRepo domain.NodeRunLogRepository, runRepo domain.WorkflowRunRepository) *RepositoryObserver {
+	return &RepositoryObserver{logRepo: logRepo, runRepo: runRepo}
+}
+
+func (o *RepositoryObserver) OnRunStart(ctx context.Context, event RunEvent) error {
+	// Logs left running belong to attempts that were interrupted.
+	if event.Resumed {
+		if err := o.logRepo.CancelUnfinished(ctx, event.RunID); err != nil {
+			return fmt.Errorf("failed to close interrupted logs: %w", err)
+		}
+	}
+	return o.runRepo.UpdateStatus(ctx, event.RunID, domain.WorkflowRunStatusRunning, nil)
+}
+
+func (o *RepositoryObserver) OnNodeStart(ctx context.Context, event NodeEvent) error {
+	logEntry, err := o.logRepo.Create(ctx, &domain.CreateNodeRunLogRequest{
+		RunID:     event.RunID,
+		NodeID:    event.NodeID,
+		Status:    event.Status,
+		Iteration: event.Iteration,
+		Pinned:    event.Pinned,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create log: %w", err)
+	}
+	o.logIDs.Store(event.ExecutionID, logEntry.ID)
+	return nil
+}
+
+func (o *RepositoryObserver) OnNodeFinish(ctx context.Context, event NodeEvent) error {
+	value, started := o.logIDs.LoadAndDelete(event.ExecutionID)
+	if !started {
+		// Skipped nodes are logged in their final state right away.
+		_, err := o.logRepo.Create(ctx, &domain.CreateNodeRunLogRequest{
+			RunID:     event.RunID,
+			NodeID:    event.NodeID,
+			Status:    event.Status,
+			Iteration: event.Iteration,
+		})
+		return err
+	}
+	logID := value.(uuid.UUID)
+
+	// Attempts were recorded by OnNodeAttempt as they ended.
+	return o.logRepo.Update(ctx, logID, &domain.UpdateNodeRunLogRequest{
+		Status:     event.Status,
+		LogOutput:  event.Log,
+		ErrorMsg:   event.ErrorMsg,
+		InputData:  event.InputData,
+		OutputData: event.OutputData,
+	})
+}
+
+func (o *RepositoryObserver) OnNodeAttempt(ctx context.Context, event NodeEvent, attempt domain.NodeRunAttempt) error {
+	value, ok := o.logIDs.Load(event.ExecutionID)
+	if !ok {
+		return fmt.Errorf("no log for execution %s", event.ExecutionID)
+	}
+	if err := o.logRepo.AddAttempt(ctx, value.(uuid.UUID), &attempt); err != nil {
+		return fmt.Errorf("failed to record attempt %d: %w", attempt.Attempt, err)
+	}
+	return nil
+}
+
+func (o *RepositoryObserver) OnRunFinish(ctx context.Context, event RunEvent) error {
+	switch event.Status {
+	case domain.WorkflowRunStatusCompleted:
+		// The output is stored first, so that whoever sees the run completed
+		// can read its result.
+		if err := o.runRepo.SetOutput(ctx, event.RunID, event.Output); err != nil {
+			log.Printf("⚠️ Failed to store output of run %s: %v", event.RunID, err)
+		}
+	case domain.WorkflowRunStatusCancelled:
+		if err := o.logRepo.CancelUnfinished(ctx, event.RunID); err != nil {
+			log.Printf("⚠️ Failed to cancel node logs: %v", err)
+		}
+	}
+
+	finishedAt := event.Time
+	return o.runRepo.UpdateStatus(ctx, event.RunID, event.Status, &finishedAt)
+}
@@ -0,0 +1,110 @@
+package engine
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/mr-isik/loki-backend/internal/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+// recordingObserver keeps the events of a run in memory.
+type recordingObserver struct {
+	mu           sync.Mutex
+	runEvents    []RunEvent
+	nodeStarts   []NodeEvent
+	nodeFinishes []NodeEvent
+}
+
+func (o *recordingObserver) OnRunStart(ctx context.Context, event RunEvent) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.runEvents = append(o.runEvents, event)
+	return nil
+}
+
+func (o *recordingObserver) OnNodeStart(ctx context.Context, event NodeEvent) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.nodeStarts = append(o.nodeStarts, event)
+	return nil
+}
+
+func (o *recordingObserver) OnNodeFinish(ctx context.Context, event NodeEvent) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.nodeFinishes = append(o.nodeFinishes, event)
+	return nil
+}
+
+func (o *recordingObserver) OnRunFinish(ctx context.Context, event RunEvent) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.runEvents = append(o.runEvents, event)
+	return nil
+}
+
+func TestWorkflowEngine_Observers(t *testing.T) {
+	// Topology: First -> Second, without any repository.
+	runID := uuid.New()
+	firstID := uuid.New()
+	secondID := uuid.New()
+	nodes := []domain.WorkflowNode{
+		{ID: firstID, Data: map[string]interface{}{"type": "set_data", "data": map[string]interface{}{"step": 1}}},
+		{ID: secondID, Data: map[string]interface{}{"type": "set_data", "data": map[string]interface{}{"step": 2}}},
+	}
+	edges := []domain.WorkflowEdge{
+		{ID: uuid.New(), SourceNodeID: firstID, TargetNodeID: secondID, SourceHandle: "output", TargetHandle: "input"},
+	}
+
+	observer := &recordingObserver{}
+	engine := NewWorkflowEngine(nodes, edges, runID, uuid.New(), nil, nil)
+	engine.AddObserver(observer)
+	assert.NoError(t, engine.Execute(context.Background()))
+
+	if assert.Len(t, observer.runEvents, 2) {
+		assert.Equal(t, domain.WorkflowRunStatusRunning, observer.runEvents[0].Status)
+		assert.Equal(t, domain.WorkflowRunStatusCompleted, observer.runEvents[1].Status)
+		assert.Equal(t, 2.0, observer.runEvents[1].Output["step"])
+	}
+
+	if assert.Len(t, observer.nodeStarts, 2) && assert.Len(t, observer.nodeFinishes, 2) {
+		assert.Equal(t, firstID, observer.nodeStarts[0].NodeID)
+		for i, finish := range observer.nodeFinishes {
+			assert.Equal(t, observer.nodeStarts[i].ExecutionID, finish.ExecutionID)
+			assert.Equal(t, domain.NodeRunLogStatusCompleted, finish.Status)
+			assert.Equal(t, "output", finish.TriggeredHandle)
+			assert.Equal(t, "set_data", finish.NodeType)
+		}
+		assert.Equal(t, 2.0, observer.nodeFinishes[1].OutputData["step"])
+	}
+}
+
+func TestWorkflowEngine_ObserversNeverSeeSecrets(t *testing.T) {
+	nodeID := uuid.New()
+	nodes := []domain.WorkflowNode{
+		{ID: nodeID, Data: map[string]interface{}{
+			"type":     "set_data",
+			"password": "hunter2",
+			"data":     map[string]interface{}{"headers": map[string]interface{}{"Authorization": "Bearer hunter2"}},
+		}},
+	}
+
+	observer := &recordingObserver{}
+	engine := NewWorkflowEngine(nodes, nil, uuid.New(), uuid.New(), nil, nil)
+	engine.AddObserver(observer)
+	assert.NoError(t, engine.Execute(context.Background()))
+
+	if assert.Len(t, observer.nodeFinishes, 1) {
+		finish := observer.nodeFinishes[0]
+		assert.Equal(t, RedactedValue, finish.InputData["password"])
+		encoded, err := json.Marshal([]interface{}{finish.InputData, finish.OutputData})
+		assert.NoError(t, err)
+		assert.NotContains(t, string(encoded), "hunter2")
+	}
+	// The node itself still works with the real values.
+	assert.Equal(t, "Bearer hunter2", engine.nodeOutputs[nodeID]["headers"].(map[string]interface{})["Authorization"])
+}
@@ -0,0 +1,128 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/mr-isik/loki-backend/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// runOnErrorWorkflow runs a failing node wired to a success branch and an
+// error branch, and returns the engine together with the branch node IDs.
+func runOnErrorWorkflow(t *testing.T, typeKey string, settings map[string]interface{}, withErrorEdge bool) (*WorkflowEngine, uuid.UUID, uuid.UUID, error) {
+	calls := int32(0)
+	RegisterNode(typeKey, func() domain.INodeExecutor {
+		return &flakyNode{calls: &calls, failUntil: 1, err: errors.New("boom")}
+	})
+
+	runID := uuid.New()
+	workflowID := uuid.New()
+	failingID := uuid.New()
+	successID := uuid.New()
+	errorID := uuid.New()
+
+	nodes := []domain.WorkflowNode{
+		{ID: failingID, WorkflowID: workflowID, Data: map[string]interface{}{"type": typeKey, "settings": settings}},
+		{ID: successID, WorkflowID: workflowID, Data: map[string]interface{}{"type": "set_data", "data": map[string]interface{}{"branch": "success"}}},
+		{ID: errorID, WorkflowID: workflowID, Data: map[string]interface{}{"type": "set_data", "data": map[string]interface{}{"error": "{{ $input.input }}"}}},
+	}
+
+	edges := []domain.WorkflowEdge{
+		{ID: uuid.New(), WorkflowID: workflowID, SourceNodeID: failingID, TargetNodeID: successID, SourceHandle: "output_success", TargetHandle: "input"},
+	}
+	if withErrorEdge {
+		edges = append(edges, domain.WorkflowEdge{ID: uuid.New(), WorkflowID: workflowID, SourceNodeID: failingID, TargetNodeID: errorID, SourceHandle: "output_error", TargetHandle: "input"})
+	}
+
+	mockRunRepo := newMockRunRepo()
+	mockLogRepo := new(MockLogRepo)
+	mockRunRepo.On("UpdateStatus", mock.Anything, runID, mock.Anything, mock.Anything).Return(nil)
+	mockLogRepo.On("Create", mock.Anything, mock.Anything).Return(&domain.NodeRunLog{ID: uuid.New()}, nil)
+	mockLogRepo.On("Update", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	engine := NewWorkflowEngine(nodes, edges, runID, workflowID, mockLogRepo, mockRunRepo)
+	err := engine.Execute(context.Background())
+
+	return engine, successID, errorID, err
+}
+
+func TestWorkflowEngine_OnError_DefaultsToErrorOutput(t *testing.T) {
+	engine, successID, errorID, err := runOnErrorWorkflow(t, "test_on_error_default", nil, true)
+	assert.NoError(t, err)
+
+	engine.mu.RLock()
+	defer engine.mu.RUnlock()
+	assert.NotContains(t, engine.nodeOutputs, successID)
+	assert.Equal(t, map[string]interface{}{"error": "boom"}, engine.nodeOutputs[errorID]["error"])
+}
+
+func TestWorkflowEngine_OnError_DefaultsToStop(t *testing.T) {
+	engine, successID, _, err := runOnErrorWorkflow(t, "test_on_error_unhandled", nil, false)
+	assert.Error(t, err)
+
+	engine.mu.RLock()
+	defer engine.mu.RUnlock()
+	assert.NotContains(t, engine.nodeOutputs, successID)
+}
+
+func TestWorkflowEngine_OnError_Stop(t *testing.T) {
+	engine, _, errorID, err := runOnErrorWorkflow(t, "test_on_error_stop", map[string]interface{}{"on_error": OnErrorStop}, true)
+	assert.Error(t, err)
+
+	engine.mu.RLock()
+	defer engine.mu.RUnlock()
+	assert.NotContains(t, engine.nodeOutputs, errorID)
+}
+
+func TestWorkflowEngine_OnError_ContinueRegularOutput(t *testing.T) {
+	engine, successID, errorID, err := runOnErrorWorkflow(t, "test_on_error_regular", map[string]interface{}{"on_error": OnErrorContinueRegularOutput}, true)
+	assert.NoError(t, err)
+
+	engine.mu.RLock()
+	defer engine.mu.RUnlock()
+	assert.Equal(t, "success", engine.nodeOutputs[successID]["branch"])
+	assert.NotContains(t, engine.nodeOutputs, errorID)
+}
+
+func TestWorkflowEngine_OnError_ContinueRegularOutputOnBranchingNode(t *testing.T) {
+	calls := int32(0)
+	RegisterNode("test_on_error_branching", func() domain.INodeExecutor {
+		return &flakyNode{calls: &calls, failUntil: 1, err: errors.New("boom")}
+	})
+
+	runID := uuid.New()
+	workflowID := uuid.New()
+	failingID := uuid.New()
+	trueID := uuid.New()
+	falseID := uuid.New()
+
+	nodes := []domain.WorkflowNode{
+		{ID: failingID, WorkflowID: workflowID, Data: map[string]interface{}{"type": "test_on_error_branching", "settings": map[string]interface{}{"on_error": OnErrorContinueRegularOutput}}},
+		{ID: trueID, WorkflowID: workflowID, Data: map[string]interface{}{"type": "set_data", "data": map[string]interface{}{"branch": "true"}}},
+		{ID: falseID, WorkflowID: workflowID, Data: map[string]interface{}{"type": "set_data", "data": map[string]interface{}{"branch": "false"}}},
+	}
+	edges := []domain.WorkflowEdge{
+		{ID: uuid.New(), WorkflowID: workflowID, SourceNodeID: failingID, TargetNodeID: trueID, SourceHandle: "output_true", TargetHandle: "input"},
+		{ID: uuid.New(), WorkflowID: workflowID, SourceNodeID: failingID, TargetNodeID: falseID, SourceHandle: "output_false", TargetHandle: "input"},
+	}
+
+	mockRunRepo := newMockRunRepo()
+	mockLogRepo := new(MockLogRepo)
+	mockRunRepo.On("UpdateStatus", mock.Anything, runID, mock.Anything, mock.Anything).Return(nil)
+	mockLogRepo.On("Create", mock.Anything, mock.Anything).Return(&domain.NodeRunLog{ID: uuid.New()}, nil)
+	mockLogRepo.On("Update", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	engine := NewWorkflowEngine(nodes, edges, runID, workflowID, mockLogRepo, mockRunRepo)
+	err := engine.Execute(context.Background())
+
+	// Neither branch may run, since the failed node picked none of them.
+	assert.Error(t, err)
+	engine.mu.RLock()
+	defer engine.mu.RUnlock()
+	assert.NotContains(t, engine.nodeOutputs, trueID)
+	assert.NotContains(t, engine.nodeOutputs, falseID)
+}
@@ -0,0 +1,72 @@
+package engine
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/mr-isik/loki-backend/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func runOutputWorkflow(t *testing.T, nodes []domain.WorkflowNode, edges []domain.WorkflowEdge) *WorkflowEngine {
+	runID := uuid.New()
+	mockRunRepo := newMockRunRepo()
+	mockLogRepo := new(MockLogRepo)
+	mockRunRepo.On("UpdateStatus", mock.Anything, runID, mock.Anything, mock.Anything).Return(nil)
+	mockLogRepo.On("Create", mock.Anything, mock.Anything).Return(&domain.NodeRunLog{ID: uuid.New()}, nil)
+	mockLogRepo.On("Update", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	engine := NewWorkflowEngine(nodes, edges, runID, uuid.New(), mockLogRepo, mockRunRepo)
+	assert.NoError(t, engine.Execute(context.Background()))
+	return engine
+}
+
+func TestWorkflowEngine_Output_SingleLeaf(t *testing.T) {
+	startID := uuid.New()
+	leafID := uuid.New()
+	nodes := []domain.WorkflowNode{
+		{ID: startID, Data: map[string]interface{}{"type": "set_data", "data": map[string]interface{}{"step": 1}}},
+		{ID: leafID, Data: map[string]interface{}{"type": "set_data", "data": map[string]interface{}{"step": 2}}},
+	}
+	edges := []domain.WorkflowEdge{
+		{ID: uuid.New(), SourceNodeID: startID, TargetNodeID: leafID, SourceHandle: "output", TargetHandle: "input"},
+	}
+
+	engine := runOutputWorkflow(t, nodes, edges)
+
+	assert.Equal(t, engine.nodeOutputs[leafID], engine.Output())
+}
+
+func TestWorkflowEngine_Output_SeveralLeaves(t *testing.T) {
+	nodes := []domain.WorkflowNode{
+		{ID: uuid.New(), Data: map[string]interface{}{"type": "set_data", "name": "first", "data": map[string]interface{}{"n": 1}}},
+		{ID: uuid.New(), Data: map[string]interface{}{"type": "set_data", "name": "second", "data": map[string]interface{}{"n": 2}}},
+	}
+
+	engine := runOutputWorkflow(t, nodes, nil)
+
+	output := engine.Output()
+	assert.Contains(t, output, "first")
+	assert.Contains(t, output, "second")
+}
+
+func TestWorkflowEngine_Output_RespondNode(t *testing.T) {
+	startID := uuid.New()
+	respondID := uuid.New()
+	leafID := uuid.New()
+	nodes := []domain.WorkflowNode{
+		{ID: startID, Data: map[string]interface{}{"type": "set_data", "data": map[string]interface{}{"total": 3}}},
+		{ID: respondID, Data: map[string]interface{}{"type": "respond", "body": map[string]interface{}{"total": "{{ $input.input.total }}"}}},
+		{ID: leafID, Data: map[string]interface{}{"type": "log", "message": "done", "level": "info"}},
+	}
+	edges := []domain.WorkflowEdge{
+		{ID: uuid.New(), SourceNodeID: startID, TargetNodeID: respondID, SourceHandle: "output", TargetHandle: "input"},
+		{ID: uuid.New(), SourceNodeID: respondID, TargetNodeID: leafID, SourceHandle: "output", TargetHandle: "input"},
+	}
+
+	engine := runOutputWorkflow(t, nodes, edges)
+
+	assert.Equal(t, map[string]interface{}{"total": float64(3)}, engine.Output())
+}
@@ -0,0 +1,86 @@
+package engine
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/mr-isik/loki-backend/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func pinnedWorkflow() ([]domain.WorkflowNode, []domain.WorkflowEdge, uuid.UUID, uuid.UUID) {
+	// Topology: Pinned -> Next. Pinned has a type no executor is registered
+	// for, so it fails unless its pinned output is used.
+	pinnedID := uuid.New()
+	nextID := uuid.New()
+	nodes := []domain.WorkflowNode{
+		{ID: pinnedID, Data: map[string]interface{}{
+			"type": "paid_api",
+			"name": "Pinned",
+			"settings": map[string]interface{}{
+				"pin": map[string]interface{}{
+					"enabled":          true,
+					"output_data":      map[string]interface{}{"answer": 42},
+					"triggered_handle": "output_success",
+				},
+			},
+		}},
+		{ID: nextID, Data: map[string]interface{}{
+			"type": "set_data",
+			"data": map[string]interface{}{"answer": `{{ $node["Pinned"].output.answer }}`},
+		}},
+	}
+	edges := []domain.WorkflowEdge{
+		{ID: uuid.New(), SourceNodeID: pinnedID, TargetNodeID: nextID, SourceHandle: "output_success", TargetHandle: "input"},
+	}
+	return nodes, edges, pinnedID, nextID
+}
+
+func TestWorkflowEngine_PinnedOutput(t *testing.T) {
+	runID := uuid.New()
+	nodes, edges, pinnedID, nextID := pinnedWorkflow()
+
+	mockRunRepo := newMockRunRepo()
+	mockLogRepo := new(MockLogRepo)
+	mockRunRepo.On("UpdateStatus", mock.Anything, runID, mock.Anything, mock.Anything).Return(nil)
+	mockLogRepo.On("Create", mock.Anything, mock.Anything).Return(&domain.NodeRunLog{ID: uuid.New()}, nil)
+	mockLogRepo.On("Update", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	engine := NewWorkflowEngine(nodes, edges, runID, uuid.New(), mockLogRepo, mockRunRepo)
+	engine.UsePinnedOutputs = true
+	assert.NoError(t, engine.Execute(context.Background()))
+
+	engine.mu.RLock()
+	assert.Equal(t, 42.0, engine.nodeOutputs[nextID]["answer"])
+	engine.mu.RUnlock()
+
+	mockLogRepo.AssertCalled(t, "Create", mock.Anything, mock.MatchedBy(func(req *domain.CreateNodeRunLogRequest) bool {
+		return req.NodeID == pinnedID && req.Pinned
+	}))
+	mockLogRepo.AssertNotCalled(t, "Create", mock.Anything, mock.MatchedBy(func(req *domain.CreateNodeRunLogRequest) bool {
+		return req.NodeID == nextID && req.Pinned
+	}))
+	mockRunRepo.AssertCalled(t, "UpdateStatus", mock.Anything, runID, domain.WorkflowRunStatusCompleted, mock.Anything)
+}
+
+func TestWorkflowEngine_PinnedOutputIgnored(t *testing.T) {
+	runID := uuid.New()
+	nodes, edges, pinnedID, _ := pinnedWorkflow()
+
+	mockRunRepo := newMockRunRepo()
+	mockLogRepo := new(MockLogRepo)
+	mockRunRepo.On("UpdateStatus", mock.Anything, runID, mock.Anything, mock.Anything).Return(nil)
+	mockLogRepo.On("Create", mock.Anything, mock.Anything).Return(&domain.NodeRunLog{ID: uuid.New()}, nil)
+	mockLogRepo.On("Update", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	// Without UsePinnedOutputs the node is executed and fails.
+	engine := NewWorkflowEngine(nodes, edges, runID, uuid.New(), mockLogRepo, mockRunRepo)
+	assert.Error(t, engine.Execute(context.Background()))
+
+	mockLogRepo.AssertCalled(t, "Create", mock.Anything, mock.MatchedBy(func(req *domain.CreateNodeRunLogRequest) bool {
+		return req.NodeID == pinnedID && !req.Pinned
+	}))
+	mockRunRepo.AssertCalled(t, "UpdateStatus", mock.Anything, runID, domain.WorkflowRunStatusFailed, mock.Anything)
+}
@@ -0,0 +1,50 @@
+package engine
+
+import (
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/mr-isik/loki-backend/internal/domain"
+)
+
+// RerunStates returns the checkpoints that let a new run continue from
+// fromNodeID: the states the source run recorded for every node that
+// fromNodeID does not lead to, moved to runID. Resuming the new run restores
+// those nodes and executes the chosen node and everything after it.
+func RerunStates(nodes []domain.WorkflowNode, edges []domain.WorkflowEdge, states []*domain.WorkflowRunNodeState, fromNodeID, runID uuid.UUID) ([]*domain.WorkflowRunNodeState, error) {
+	graph := NewWorkflowEngine(nodes, edges, runID, uuid.Nil, nil, nil)
+	if _, ok := graph.Nodes[fromNodeID]; !ok {
+		return nil, fmt.Errorf("%w: node %s is not part of the workflow", domain.ErrInvalidRerunNode, fromNodeID)
+	}
+	// Loop bodies run within their loop, which is restored as a whole.
+	if graph.loopBodyNodes()[fromNodeID] {
+		return nil, fmt.Errorf("%w: node %s runs inside a loop; rerun from the loop instead", domain.ErrInvalidRerunNode, fromNodeID)
+	}
+
+	downstream := map[uuid.UUID]bool{fromNodeID: true}
+	queue := []uuid.UUID{fromNodeID}
+	for len(queue) > 0 {
+		curr := queue[0]
+		queue = queue[1:]
+		for _, edge := range edges {
+			if edge.SourceNodeID == curr && !downstream[edge.TargetNodeID] {
+				downstream[edge.TargetNodeID] = true
+				queue = append(queue, edge.TargetNodeID)
+			}
+		}
+	}
+
+	var seeded []*domain.WorkflowRunNodeState
+	for _, state := range states {
+		if downstream[state.NodeID] {
+			continue
+		}
+		if _, ok := graph.Nodes[state.NodeID]; !ok {
+			continue
+		}
+		seed := *state
+		seed.RunID = runID
+		seeded = append(seeded, &seed)
+	}
+	return seeded, nil
+}
@@ -0,0 +1,69 @@
+package engine
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/mr-isik/loki-backend/internal/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRerunStates(t *testing.T) {
+	// Topology: A -> B -> C, A -> D. Rerunning from B keeps A and D.
+	sourceRunID := uuid.New()
+	runID := uuid.New()
+	aID, bID, cID, dID := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+
+	nodes := []domain.WorkflowNode{
+		{ID: aID, Data: map[string]interface{}{"type": "set_data"}},
+		{ID: bID, Data: map[string]interface{}{"type": "set_data"}},
+		{ID: cID, Data: map[string]interface{}{"type": "set_data"}},
+		{ID: dID, Data: map[string]interface{}{"type": "set_data"}},
+	}
+	edges := []domain.WorkflowEdge{
+		{ID: uuid.New(), SourceNodeID: aID, TargetNodeID: bID, SourceHandle: "output", TargetHandle: "input"},
+		{ID: uuid.New(), SourceNodeID: bID, TargetNodeID: cID, SourceHandle: "output", TargetHandle: "input"},
+		{ID: uuid.New(), SourceNodeID: aID, TargetNodeID: dID, SourceHandle: "output", TargetHandle: "input"},
+	}
+	states := []*domain.WorkflowRunNodeState{
+		{RunID: sourceRunID, NodeID: aID, Status: domain.NodeRunLogStatusCompleted, TriggeredHandle: "output", Output: map[string]interface{}{"a": 1}},
+		{RunID: sourceRunID, NodeID: bID, Status: domain.NodeRunLogStatusCompleted, TriggeredHandle: "output"},
+		{RunID: sourceRunID, NodeID: cID, Status: domain.NodeRunLogStatusCompleted, TriggeredHandle: "output"},
+		{RunID: sourceRunID, NodeID: dID, Status: domain.NodeRunLogStatusCompleted, TriggeredHandle: "output"},
+		// A node deleted since the source run.
+		{RunID: sourceRunID, NodeID: uuid.New(), Status: domain.NodeRunLogStatusCompleted},
+	}
+
+	seeded, err := RerunStates(nodes, edges, states, bID, runID)
+	assert.NoError(t, err)
+
+	seededIDs := make(map[uuid.UUID]bool)
+	for _, state := range seeded {
+		assert.Equal(t, runID, state.RunID)
+		seededIDs[state.NodeID] = true
+	}
+	assert.Equal(t, map[uuid.UUID]bool{aID: true, dID: true}, seededIDs)
+	// The source states are left untouched.
+	assert.Equal(t, sourceRunID, states[0].RunID)
+}
+
+func TestRerunStates_InvalidNode(t *testing.T) {
+	loopID := uuid.New()
+	bodyID := uuid.New()
+	nodes := []domain.WorkflowNode{
+		{ID: loopID, Data: map[string]interface{}{"type": "loop"}},
+		{ID: bodyID, Data: map[string]interface{}{"type": "set_data"}},
+	}
+	edges := []domain.WorkflowEdge{
+		{ID: uuid.New(), SourceNodeID: loopID, TargetNodeID: bodyID, SourceHandle: "output_item", TargetHandle: "input"},
+	}
+
+	_, err := RerunStates(nodes, edges, nil, uuid.New(), uuid.New())
+	assert.ErrorIs(t, err, domain.ErrInvalidRerunNode)
+
+	_, err = RerunStates(nodes, edges, nil, bodyID, uuid.New())
+	assert.ErrorIs(t, err, domain.ErrInvalidRerunNode)
+
+	_, err = RerunStates(nodes, edges, nil, loopID, uuid.New())
+	assert.NoError(t, err)
+}
@@ -0,0 +1,97 @@
+package engine
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/mr-isik/loki-backend/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockStateRepo struct {
+	mock.Mock
+}
+
+func (m *MockStateRepo) Save(ctx context.Context, state *domain.WorkflowRunNodeState) error {
+	args := m.Called(ctx, state)
+	return args.Error(0)
+}
+func (m *MockStateRepo) GetByRunID(ctx context.Context, runID uuid.UUID) ([]*domain.WorkflowRunNodeState, error) {
+	args := m.Called(ctx, runID)
+	return args.Get(0).([]*domain.WorkflowRunNodeState), args.Error(1)
+}
+
+func TestWorkflowEngine_Execute_Checkpoints(t *testing.T) {
+	runID := uuid.New()
+	workflowID := uuid.New()
+	nodeID := uuid.New()
+
+	nodes := []domain.WorkflowNode{
+		{ID: nodeID, WorkflowID: workflowID, Data: map[string]interface{}{"type": "set_data", "data": map[string]interface{}{"a": 1}}},
+	}
+
+	mockRunRepo := newMockRunRepo()
+	mockLogRepo := new(MockLogRepo)
+	mockStateRepo := new(MockStateRepo)
+	mockRunRepo.On("UpdateStatus", mock.Anything, runID, mock.Anything, mock.Anything).Return(nil)
+	mockLogRepo.On("Create", mock.Anything, mock.Anything).Return(&domain.NodeRunLog{ID: uuid.New()}, nil)
+	mockLogRepo.On("Update", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	mockStateRepo.On("Save", mock.Anything, mock.Anything).Return(nil)
+
+	engine := NewWorkflowEngine(nodes, nil, runID, workflowID, mockLogRepo, mockRunRepo)
+	engine.StateRepo = mockStateRepo
+	assert.NoError(t, engine.Execute(context.Background()))
+
+	mockStateRepo.AssertCalled(t, "Save", mock.Anything, mock.MatchedBy(func(state *domain.WorkflowRunNodeState) bool {
+		return state.RunID == runID && state.NodeID == nodeID &&
+			state.Status == domain.NodeRunLogStatusCompleted && state.Output["a"] == 1.0
+	}))
+}
+
+func TestWorkflowEngine_Resume(t *testing.T) {
+	// Topology: First -> Second. First settled before the run was interrupted.
+	runID := uuid.New()
+	workflowID := uuid.New()
+	firstID := uuid.New()
+	secondID := uuid.New()
+
+	nodes := []domain.WorkflowNode{
+		{ID: firstID, WorkflowID: workflowID, Data: map[string]interface{}{"type": "set_data", "name": "First"}},
+		{ID: secondID, WorkflowID: workflowID, Data: map[string]interface{}{
+			"type": "set_data",
+			"data": map[string]interface{}{"copied": `{{ $node["First"].output.value }}`},
+		}},
+	}
+	edges := []domain.WorkflowEdge{
+		{ID: uuid.New(), WorkflowID: workflowID, SourceNodeID: firstID, TargetNodeID: secondID, SourceHandle: "output", TargetHandle: "input"},
+	}
+
+	mockRunRepo := newMockRunRepo()
+	mockLogRepo := new(MockLogRepo)
+	mockStateRepo := new(MockStateRepo)
+	mockRunRepo.On("UpdateStatus", mock.Anything, runID, mock.Anything, mock.Anything).Return(nil)
+	mockLogRepo.On("Create", mock.Anything, mock.Anything).Return(&domain.NodeRunLog{ID: uuid.New()}, nil)
+	mockLogRepo.On("Update", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	mockLogRepo.On("CancelUnfinished", mock.Anything, runID).Return(nil)
+	mockStateRepo.On("Save", mock.Anything, mock.Anything).Return(nil)
+	mockStateRepo.On("GetByRunID", mock.Anything, runID).Return([]*domain.WorkflowRunNodeState{
+		{RunID: runID, NodeID: firstID, Status: domain.NodeRunLogStatusCompleted, TriggeredHandle: "output", Output: map[string]interface{}{"value": "restored"}},
+	}, nil)
+
+	engine := NewWorkflowEngine(nodes, edges, runID, workflowID, mockLogRepo, mockRunRepo)
+	engine.StateRepo = mockStateRepo
+	assert.NoError(t, engine.Resume(context.Background()))
+
+	engine.mu.RLock()
+	assert.Equal(t, "restored", engine.nodeOutputs[secondID]["copied"])
+	engine.mu.RUnlock()
+
+	// Only the second node runs again.
+	mockLogRepo.AssertNotCalled(t, "Create", mock.Anything, mock.MatchedBy(func(req *domain.CreateNodeRunLogRequest) bool {
+		return req.NodeID == firstID
+	}))
+	mockLogRepo.AssertCalled(t, "CancelUnfinished", mock.Anything, runID)
+	mockRunRepo.AssertCalled(t, "UpdateStatus", mock.Anything, runID, domain.WorkflowRunStatusCompleted, mock.Anything)
+}
@@ -0,0 +1,149 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/mr-isik/loki-backend/internal/domain"
+)
+
+// Error classes used to decide whether a failed attempt should be retried.
+const (
+	ErrorClassTimeout = "timeout"
+	ErrorClassNetwork = "network"
+	ErrorClassError   = "error"
+)
+
+const (
+	BackoffFixed       = "fixed"
+	BackoffExponential = "exponential"
+
+	defaultRetryDelay = time.Second
+	maxRetryAttempts  = 10
+)
+
+// RetryPolicy describes how often and how fast a failing node is retried.
+type RetryPolicy struct {
+	MaxAttempts int      `json:"max_attempts"`
+	Backoff     string   `json:"backoff"`
+	DelayMs     int      `json:"delay_ms"`
+	MaxDelayMs  int      `json:"max_delay_ms"`
+	Jitter      float64  `json:"jitter"`
+	RetryOn     []string `json:"retry_on"`
+}
+
+// Attempts returns the total number of attempts allowed, including the first one.
+func (p *RetryPolicy) Attempts() int {
+	if p == nil || p.MaxAttempts < 1 {
+		return 1
+	}
+	if p.MaxAttempts > maxRetryAttempts {
+		return maxRetryAttempts
+	}
+	return p.MaxAttempts
+}
+
+// ShouldRetry reports whether a failure of the given class is retryable.
+// An empty RetryOn list retries transient failures, i.e. timeouts and
+// network errors, but not errors such as invalid input or 4xx responses.
+func (p *RetryPolicy) ShouldRetry(errorClass string) bool {
+	if len(p.RetryOn) == 0 {
+		return errorClass == ErrorClassTimeout || errorClass == ErrorClassNetwork
+	}
+	for _, class := range p.RetryOn {
+		if class == errorClass {
+			return true
+		}
+	}
+	return false
+}
+
+// Delay returns how long to wait after the given (1-based) failed attempt.
+func (p *RetryPolicy) Delay(attempt int) time.Duration {
+	delay := defaultRetryDelay
+	if p.DelayMs > 0 {
+		delay = time.Duration(p.DelayMs) * time.Millisecond
+	}
+
+	if p.Backoff == BackoffExponential {
+		for i := 1; i < attempt && delay < time.Hour; i++ {
+			delay *= 2
+		}
+	}
+
+	if p.MaxDelayMs > 0 {
+		if maxDelay := time.Duration(p.MaxDelayMs) * time.Millisecond; delay > maxDelay {
+			delay = maxDelay
+		}
+	}
+
+	if p.Jitter > 0 {
+		jitter := p.Jitter
+		if jitter > 1 {
+			jitter = 1
+		}
+		delay -= time.Duration(rand.Float64() * jitter * float64(delay))
+	}
+
+	return delay
+}
+
+// classifyFailure maps a failed attempt to one of the error classes.
+func classifyFailure(err error, errMsg string, timedOut bool) string {
+	if timedOut || errors.Is(err, context.DeadlineExceeded) {
+		return ErrorClassTimeout
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return ErrorClassNetwork
+	}
+
+	msg := strings.ToLower(errMsg)
+	for _, marker := range []string{
+		"connection refused",
+		"connection reset",
+		"no such host",
+		"network is unreachable",
+		"i/o timeout",
+		"tls handshake",
+	} {
+		if strings.Contains(msg, marker) {
+			return ErrorClassNetwork
+		}
+	}
+
+	return ErrorClassError
+}
+
+// failureMessage extracts a readable error message from a failed attempt.
+func failureMessage(result *domain.NodeResult, err error) string {
+	if err != nil {
+		return err.Error()
+	}
+	if result != nil {
+		if msg, ok := result.OutputData["error"].(string); ok && msg != "" {
+			return msg
+		}
+		return result.Log
+	}
+	return ""
+}
+
+// sleepContext waits for d or until ctx is done.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
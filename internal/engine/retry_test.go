@@ -0,0 +1,195 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mr-isik/loki-backend/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// flakyNode fails until it has been called failUntil times.
+type flakyNode struct {
+	calls     *int32
+	failUntil int32
+	err       error
+}
+
+func (n *flakyNode) Execute(ctx context.Context, rawData []byte) (*domain.NodeResult, error) {
+	call := atomic.AddInt32(n.calls, 1)
+	if call <= n.failUntil {
+		return &domain.NodeResult{
+			Status:          "failed",
+			TriggeredHandle: "output_error",
+			Log:             "upstream unavailable",
+			OutputData:      map[string]interface{}{"error": n.err.Error()},
+		}, nil
+	}
+	return &domain.NodeResult{
+		Status:          "completed",
+		TriggeredHandle: "output_success",
+		OutputData:      map[string]interface{}{"call": call},
+	}, nil
+}
+
+func TestRetryPolicy_Delay(t *testing.T) {
+	fixed := &RetryPolicy{Backoff: BackoffFixed, DelayMs: 100}
+	assert.Equal(t, 100*time.Millisecond, fixed.Delay(1))
+	assert.Equal(t, 100*time.Millisecond, fixed.Delay(3))
+
+	exponential := &RetryPolicy{Backoff: BackoffExponential, DelayMs: 100, MaxDelayMs: 300}
+	assert.Equal(t, 100*time.Millisecond, exponential.Delay(1))
+	assert.Equal(t, 200*time.Millisecond, exponential.Delay(2))
+	assert.Equal(t, 300*time.Millisecond, exponential.Delay(3))
+
+	jittered := &RetryPolicy{DelayMs: 100, Jitter: 0.5}
+	for i := 0; i < 10; i++ {
+		d := jittered.Delay(1)
+		assert.True(t, d > 50*time.Millisecond && d <= 100*time.Millisecond, "delay %v out of range", d)
+	}
+}
+
+func TestClassifyFailure(t *testing.T) {
+	assert.Equal(t, ErrorClassTimeout, classifyFailure(context.DeadlineExceeded, "", false))
+	assert.Equal(t, ErrorClassTimeout, classifyFailure(nil, "", true))
+	assert.Equal(t, ErrorClassNetwork, classifyFailure(nil, "dial tcp 127.0.0.1:80: connect: connection refused", false))
+	assert.Equal(t, ErrorClassError, classifyFailure(errors.New("syntax error"), "syntax error", false))
+	assert.Equal(t, ErrorClassNetwork, classifyFailure(fmt.Errorf("read body: %w", io.ErrUnexpectedEOF), "", false))
+	assert.Equal(t, ErrorClassError, classifyFailure(errors.New("unexpected eof in config"), "unexpected eof in config", false))
+}
+
+func TestRetryPolicy_ShouldRetryDefaultsToTransient(t *testing.T) {
+	policy := &RetryPolicy{}
+	assert.True(t, policy.ShouldRetry(ErrorClassTimeout))
+	assert.True(t, policy.ShouldRetry(ErrorClassNetwork))
+	assert.False(t, policy.ShouldRetry(ErrorClassError))
+}
+
+func runFlakyWorkflow(t *testing.T, typeKey string, node *flakyNode, settings map[string]interface{}) (*MockLogRepo, error) {
+	RegisterNode(typeKey, func() domain.INodeExecutor { return node })
+
+	runID := uuid.New()
+	workflowID := uuid.New()
+	nodes := []domain.WorkflowNode{
+		{ID: uuid.New(), WorkflowID: workflowID, Data: map[string]interface{}{"type": typeKey, "settings": settings}},
+	}
+
+	mockRunRepo := newMockRunRepo()
+	mockLogRepo := new(MockLogRepo)
+	mockRunRepo.On("UpdateStatus", mock.Anything, runID, mock.Anything, mock.Anything).Return(nil)
+	mockLogRepo.On("Create", mock.Anything, mock.Anything).Return(&domain.NodeRunLog{ID: uuid.New()}, nil)
+	mockLogRepo.On("Update", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	mockLogRepo.On("AddAttempt", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	engine := NewWorkflowEngine(nodes, nil, runID, workflowID, mockLogRepo, mockRunRepo)
+	return mockLogRepo, engine.Execute(context.Background())
+}
+
+func TestWorkflowEngine_Execute_RetriesFlakyNode(t *testing.T) {
+	var calls int32
+	node := &flakyNode{calls: &calls, failUntil: 2, err: errors.New("connection refused")}
+
+	mockLogRepo, err := runFlakyWorkflow(t, "test_flaky_retry", node, map[string]interface{}{
+		"retry": map[string]interface{}{"max_attempts": 3, "backoff": "exponential", "delay_ms": 1},
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, int32(3), atomic.LoadInt32(&calls))
+	mockLogRepo.AssertNumberOfCalls(t, "AddAttempt", 3)
+	mockLogRepo.AssertCalled(t, "AddAttempt", mock.Anything, mock.Anything, mock.MatchedBy(func(a *domain.NodeRunAttempt) bool {
+		return a.Attempt == 1 && a.Status == domain.NodeRunLogStatusFailed && a.ErrorClass == ErrorClassNetwork
+	}))
+	mockLogRepo.AssertCalled(t, "AddAttempt", mock.Anything, mock.Anything, mock.MatchedBy(func(a *domain.NodeRunAttempt) bool {
+		return a.Attempt == 3 && a.Status == domain.NodeRunLogStatusCompleted
+	}))
+}
+
+func TestWorkflowEngine_Execute_RetryExhausted(t *testing.T) {
+	var calls int32
+	node := &flakyNode{calls: &calls, failUntil: 5, err: errors.New("connection refused")}
+
+	mockLogRepo, err := runFlakyWorkflow(t, "test_flaky_exhausted", node, map[string]interface{}{
+		"retry": map[string]interface{}{"max_attempts": 2, "delay_ms": 1},
+	})
+
+	assert.Error(t, err)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+	mockLogRepo.AssertNumberOfCalls(t, "AddAttempt", 2)
+}
+
+func TestWorkflowEngine_Execute_RetryOnlyMatchingClass(t *testing.T) {
+	var calls int32
+	node := &flakyNode{calls: &calls, failUntil: 1, err: errors.New("invalid query")}
+
+	_, err := runFlakyWorkflow(t, "test_flaky_class", node, map[string]interface{}{
+		"retry": map[string]interface{}{"max_attempts": 3, "delay_ms": 1, "retry_on": []string{ErrorClassNetwork}},
+	})
+
+	assert.Error(t, err)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+}
+
+func TestWorkflowEngine_Execute_RetryDefaultSkipsErrors(t *testing.T) {
+	var calls int32
+	node := &flakyNode{calls: &calls, failUntil: 1, err: errors.New("invalid query")}
+
+	_, err := runFlakyWorkflow(t, "test_flaky_default", node, map[string]interface{}{
+		"retry": map[string]interface{}{"max_attempts": 3, "delay_ms": 1},
+	})
+
+	assert.Error(t, err)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+}
+
+// leaseLosingNode fails its first attempt and loses the run's lease during
+// the second one.
+type leaseLosingNode struct {
+	calls  int32
+	cancel context.CancelCauseFunc
+}
+
+func (n *leaseLosingNode) Execute(ctx context.Context, rawData []byte) (*domain.NodeResult, error) {
+	if atomic.AddInt32(&n.calls, 1) == 1 {
+		return nil, errors.New("connection refused")
+	}
+	n.cancel(ErrLeaseLost)
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func TestWorkflowEngine_Execute_AttemptsRecordedAsTheyEnd(t *testing.T) {
+	ctx, cancel := context.WithCancelCause(context.Background())
+	defer cancel(nil)
+	node := &leaseLosingNode{cancel: cancel}
+	RegisterNode("test_flaky_lease", func() domain.INodeExecutor { return node })
+
+	runID := uuid.New()
+	nodes := []domain.WorkflowNode{
+		{ID: uuid.New(), Data: map[string]interface{}{"type": "test_flaky_lease", "settings": map[string]interface{}{
+			"retry": map[string]interface{}{"max_attempts": 3, "delay_ms": 1},
+		}}},
+	}
+
+	mockRunRepo := newMockRunRepo()
+	mockLogRepo := new(MockLogRepo)
+	mockRunRepo.On("UpdateStatus", mock.Anything, runID, mock.Anything, mock.Anything).Return(nil)
+	mockLogRepo.On("Create", mock.Anything, mock.Anything).Return(&domain.NodeRunLog{ID: uuid.New()}, nil)
+	mockLogRepo.On("AddAttempt", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	engine := NewWorkflowEngine(nodes, nil, runID, uuid.New(), mockLogRepo, mockRunRepo)
+	assert.ErrorIs(t, engine.Execute(ctx), ErrLeaseLost)
+
+	// The node never finished, but its first attempt was recorded.
+	mockLogRepo.AssertNumberOfCalls(t, "AddAttempt", 1)
+	mockLogRepo.AssertCalled(t, "AddAttempt", mock.Anything, mock.Anything, mock.MatchedBy(func(a *domain.NodeRunAttempt) bool {
+		return a.Attempt == 1 && a.ErrorClass == ErrorClassNetwork
+	}))
+	mockLogRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything, mock.Anything)
+}
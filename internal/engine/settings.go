@@ -0,0 +1,59 @@
+package engine
+
+import (
+	"encoding/json"
+
+	"github.com/mr-isik/loki-backend/internal/domain"
+)
+
+// NodeSettings holds engine-level options stored under the "settings" key of
+// a node's data. They control how the engine runs the node rather than what
+// the node itself does.
+type NodeSettings struct {
+	Retry          *RetryPolicy  `json:"retry,omitempty"`
+	TimeoutSeconds int           `json:"timeout_seconds,omitempty"`
+	OnError        string        `json:"on_error,omitempty"`
+	Pin            *PinnedOutput `json:"pin,omitempty"`
+}
+
+// PinnedOutput is output data saved on a node during development. While it
+// is enabled, the engine emits it instead of executing the node, so that
+// downstream nodes can be built without calling paid APIs or waiting on slow
+// nodes every time.
+type PinnedOutput struct {
+	Enabled    bool                   `json:"enabled"`
+	OutputData map[string]interface{} `json:"output_data"`
+	// TriggeredHandle is the output to continue along. An empty handle
+	// follows every regular output.
+	TriggeredHandle string `json:"triggered_handle,omitempty"`
+}
+
+// On-error policies. When a node leaves OnError empty, the engine continues
+// along its error output if one is connected and stops the run otherwise.
+const (
+	OnErrorStop                  = "stop"
+	OnErrorContinueErrorOutput   = "continue_error_output"
+	OnErrorContinueRegularOutput = "continue_regular_output"
+)
+
+// errorHandle is the source handle nodes use for their error output.
+const errorHandle = "output_error"
+
+// parseNodeSettings decodes the settings of a node. Malformed settings are
+// ignored so that a bad value never blocks execution.
+func parseNodeSettings(node domain.WorkflowNode) NodeSettings {
+	var settings NodeSettings
+
+	raw, ok := node.Data["settings"]
+	if !ok || raw == nil {
+		return settings
+	}
+
+	b, err := json.Marshal(raw)
+	if err != nil {
+		return settings
+	}
+	_ = json.Unmarshal(b, &settings)
+
+	return settings
+}
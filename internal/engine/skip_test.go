@@ -0,0 +1,124 @@
+package engine
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/mr-isik/loki-backend/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// branchNode always takes the output_true branch.
+type branchNode struct{}
+
+func (n *branchNode) Execute(ctx context.Context, rawData []byte) (*domain.NodeResult, error) {
+	return &domain.NodeResult{
+		Status:          "completed",
+		TriggeredHandle: "output_true",
+		OutputData:      map[string]interface{}{"result": true},
+	}, nil
+}
+
+func TestWorkflowEngine_Execute_SkipsUntakenBranch(t *testing.T) {
+	// Topology:
+	//        Branch
+	//   true /    \ false
+	//     Yes      No
+	//       \      |
+	//        \   After
+	//         \   /
+	//         Merge
+	//           |
+	//          End
+	RegisterNode("test_branch", func() domain.INodeExecutor { return &branchNode{} })
+
+	runID := uuid.New()
+	workflowID := uuid.New()
+	branchID := uuid.New()
+	yesID := uuid.New()
+	noID := uuid.New()
+	afterID := uuid.New()
+	mergeID := uuid.New()
+	endID := uuid.New()
+
+	setData := func(id uuid.UUID) domain.WorkflowNode {
+		return domain.WorkflowNode{ID: id, WorkflowID: workflowID, Data: map[string]interface{}{"type": "set_data", "data": map[string]interface{}{"id": id.String()}}}
+	}
+	nodes := []domain.WorkflowNode{
+		{ID: branchID, WorkflowID: workflowID, Data: map[string]interface{}{"type": "test_branch"}},
+		setData(yesID),
+		setData(noID),
+		setData(afterID),
+		{ID: mergeID, WorkflowID: workflowID, Data: map[string]interface{}{"type": "merge"}},
+		setData(endID),
+	}
+
+	edge := func(source, target uuid.UUID, handle string) domain.WorkflowEdge {
+		return domain.WorkflowEdge{ID: uuid.New(), WorkflowID: workflowID, SourceNodeID: source, TargetNodeID: target, SourceHandle: handle, TargetHandle: "input"}
+	}
+	edges := []domain.WorkflowEdge{
+		edge(branchID, yesID, "output_true"),
+		edge(branchID, noID, "output_false"),
+		edge(noID, afterID, "output"),
+		edge(yesID, mergeID, "output"),
+		edge(afterID, mergeID, "output"),
+		edge(mergeID, endID, "output"),
+	}
+
+	mockRunRepo := newMockRunRepo()
+	mockLogRepo := new(MockLogRepo)
+	mockRunRepo.On("UpdateStatus", mock.Anything, runID, mock.Anything, mock.Anything).Return(nil)
+	mockLogRepo.On("Create", mock.Anything, mock.Anything).Return(&domain.NodeRunLog{ID: uuid.New()}, nil)
+	mockLogRepo.On("Update", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	engine := NewWorkflowEngine(nodes, edges, runID, workflowID, mockLogRepo, mockRunRepo)
+	err := engine.Execute(context.Background())
+	assert.NoError(t, err)
+
+	engine.mu.RLock()
+	assert.Contains(t, engine.nodeOutputs, mergeID)
+	assert.Contains(t, engine.nodeOutputs, endID)
+	assert.NotContains(t, engine.nodeOutputs, noID)
+	assert.NotContains(t, engine.nodeOutputs, afterID)
+	engine.mu.RUnlock()
+
+	for _, id := range []uuid.UUID{noID, afterID} {
+		nodeID := id
+		mockLogRepo.AssertCalled(t, "Create", mock.Anything, mock.MatchedBy(func(req *domain.CreateNodeRunLogRequest) bool {
+			return req.NodeID == nodeID && req.Status == domain.NodeRunLogStatusSkipped
+		}))
+	}
+	mockRunRepo.AssertCalled(t, "UpdateStatus", mock.Anything, runID, domain.WorkflowRunStatusCompleted, mock.Anything)
+}
+
+func TestWorkflowEngine_Execute_SkipsDownstreamOfFailure(t *testing.T) {
+	runID := uuid.New()
+	workflowID := uuid.New()
+	badID := uuid.New()
+	nextID := uuid.New()
+
+	nodes := []domain.WorkflowNode{
+		{ID: badID, WorkflowID: workflowID, Data: map[string]interface{}{"type": "nonexistent_node_type"}},
+		{ID: nextID, WorkflowID: workflowID, Data: map[string]interface{}{"type": "set_data"}},
+	}
+	edges := []domain.WorkflowEdge{
+		{ID: uuid.New(), WorkflowID: workflowID, SourceNodeID: badID, TargetNodeID: nextID, SourceHandle: "output", TargetHandle: "input"},
+	}
+
+	mockRunRepo := newMockRunRepo()
+	mockLogRepo := new(MockLogRepo)
+	mockRunRepo.On("UpdateStatus", mock.Anything, runID, mock.Anything, mock.Anything).Return(nil)
+	mockLogRepo.On("Create", mock.Anything, mock.Anything).Return(&domain.NodeRunLog{ID: uuid.New()}, nil)
+	mockLogRepo.On("Update", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	engine := NewWorkflowEngine(nodes, edges, runID, workflowID, mockLogRepo, mockRunRepo)
+	err := engine.Execute(context.Background())
+	assert.Error(t, err)
+
+	mockLogRepo.AssertCalled(t, "Create", mock.Anything, mock.MatchedBy(func(req *domain.CreateNodeRunLogRequest) bool {
+		return req.NodeID == nextID && req.Status == domain.NodeRunLogStatusSkipped
+	}))
+	mockRunRepo.AssertCalled(t, "UpdateStatus", mock.Anything, runID, domain.WorkflowRunStatusFailed, mock.Anything)
+}
@@ -0,0 +1,121 @@
+package engine
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/mr-isik/loki-backend/internal/domain"
+	"github.com/mr-isik/loki-backend/internal/engine/utils"
+)
+
+// TestNodeResult is the outcome of executing a single node with ExecuteNode.
+type TestNodeResult struct {
+	Status          domain.NodeRunLogStatus
+	TriggeredHandle string
+	Log             string
+	ErrorMsg        string
+	OutputData      map[string]interface{}
+}
+
+// ExecuteNode runs a single node outside of a workflow run, so that its
+// configuration can be tried out. input takes the place of the data that
+// upstream nodes would pass; trigger nodes receive it as the run input. The
+// node's retry and on-error settings do not apply.
+func ExecuteNode(ctx context.Context, config Config, node domain.WorkflowNode, input map[string]interface{}) *TestNodeResult {
+	if input == nil {
+		input = make(map[string]interface{})
+	}
+	fail := func(err error) *TestNodeResult {
+		msg := utils.SanitizeError(err)
+		return &TestNodeResult{
+			Status:     domain.NodeRunLogStatusFailed,
+			ErrorMsg:   msg,
+			OutputData: map[string]interface{}{"error": msg},
+		}
+	}
+
+	nodeType, _ := node.Data["type"].(string)
+	executor, err := NewNodeExecutor(nodeType)
+	if err != nil {
+		return fail(err)
+	}
+
+	scope := expressionScope{
+		"node":  map[string]interface{}{},
+		"input": input,
+		"run": map[string]interface{}{
+			"workflow_id": node.WorkflowID.String(),
+			"input":       input,
+		},
+		"vars": map[string]interface{}{},
+	}
+	resolvedData, err := resolveNodeData(node.Data, scope)
+	if err != nil {
+		return fail(fmt.Errorf("failed to resolve expressions: %w", err))
+	}
+
+	inputData := make(map[string]interface{})
+	for k, v := range resolvedData {
+		inputData[k] = v
+	}
+	inputData["input"] = input
+	if _, ok := executor.(domain.ITriggerNode); ok {
+		inputData["trigger"] = input
+	}
+
+	jsonData, err := json.Marshal(inputData)
+	if err != nil {
+		return fail(err)
+	}
+
+	if err := config.NodeSlots.Acquire(ctx); err != nil {
+		return fail(err)
+	}
+	defer config.NodeSlots.Release()
+
+	timeout := config.NodeTimeout(parseNodeSettings(node))
+	timeoutCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	// Variables written by the node only live for the test.
+	scratch := NewWorkflowEngine(nil, nil, uuid.Nil, node.WorkflowID, nil, nil)
+	timeoutCtx = domain.WithRunVariables(timeoutCtx, scratch)
+
+	result, err := executor.Execute(timeoutCtx, jsonData)
+	if errors.Is(timeoutCtx.Err(), context.DeadlineExceeded) {
+		msg := fmt.Sprintf("Execution timed out after %s.", timeout)
+		return &TestNodeResult{
+			Status:     domain.NodeRunLogStatusTimedOut,
+			ErrorMsg:   msg,
+			OutputData: map[string]interface{}{"error": msg},
+		}
+	}
+	if err == nil && result == nil {
+		err = errors.New("node returned no result")
+	}
+	if err != nil {
+		failed := fail(err)
+		if result != nil {
+			failed.Log = result.Log
+			for k, v := range result.OutputData {
+				if k != "error" {
+					failed.OutputData[k] = v
+				}
+			}
+		}
+		return failed
+	}
+
+	status := domain.NodeRunLogStatusCompleted
+	if result.Status == "failed" {
+		status = domain.NodeRunLogStatusFailed
+	}
+	return &TestNodeResult{
+		Status:          status,
+		TriggeredHandle: result.TriggeredHandle,
+		Log:             result.Log,
+		OutputData:      result.OutputData,
+	}
+}
@@ -0,0 +1,46 @@
+package engine
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/mr-isik/loki-backend/internal/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExecuteNode(t *testing.T) {
+	node := domain.WorkflowNode{
+		ID: uuid.New(),
+		Data: map[string]interface{}{
+			"type": "set_data",
+			"data": map[string]interface{}{"greeting": "hello {{ $input.input.name }}"},
+		},
+	}
+
+	result := ExecuteNode(context.Background(), DefaultConfig(), node, map[string]interface{}{
+		"input": map[string]interface{}{"name": "ada"},
+	})
+
+	assert.Equal(t, domain.NodeRunLogStatusCompleted, result.Status)
+	assert.Equal(t, "output", result.TriggeredHandle)
+	assert.Equal(t, "hello ada", result.OutputData["greeting"])
+}
+
+func TestExecuteNode_Trigger(t *testing.T) {
+	node := domain.WorkflowNode{ID: uuid.New(), Data: map[string]interface{}{"type": "webhook"}}
+
+	result := ExecuteNode(context.Background(), DefaultConfig(), node, map[string]interface{}{"order": 7})
+
+	assert.Equal(t, domain.NodeRunLogStatusCompleted, result.Status)
+	assert.Equal(t, float64(7), result.OutputData["order"])
+}
+
+func TestExecuteNode_UnknownType(t *testing.T) {
+	node := domain.WorkflowNode{ID: uuid.New(), Data: map[string]interface{}{"type": "missing"}}
+
+	result := ExecuteNode(context.Background(), DefaultConfig(), node, nil)
+
+	assert.Equal(t, domain.NodeRunLogStatusFailed, result.Status)
+	assert.NotEmpty(t, result.ErrorMsg)
+}
@@ -0,0 +1,92 @@
+package engine
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mr-isik/loki-backend/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// slowNode blocks until its context is done.
+type slowNode struct{}
+
+func (n *slowNode) Execute(ctx context.Context, rawData []byte) (*domain.NodeResult, error) {
+	<-ctx.Done()
+	return &domain.NodeResult{Status: "failed", OutputData: map[string]interface{}{"error": ctx.Err().Error()}}, ctx.Err()
+}
+
+func TestConfig_NodeTimeout(t *testing.T) {
+	cfg := Config{DefaultNodeTimeout: 10 * time.Second, MaxNodeTimeout: time.Minute}
+
+	assert.Equal(t, 10*time.Second, cfg.NodeTimeout(NodeSettings{}))
+	assert.Equal(t, 30*time.Second, cfg.NodeTimeout(NodeSettings{TimeoutSeconds: 30}))
+	assert.Equal(t, time.Minute, cfg.NodeTimeout(NodeSettings{TimeoutSeconds: 3600}))
+}
+
+func TestConfig_RunTimeout(t *testing.T) {
+	cfg := Config{MaxRunDuration: time.Hour}
+
+	assert.Equal(t, time.Hour, cfg.RunTimeout(domain.WorkflowSettings{}))
+	assert.Equal(t, time.Minute, cfg.RunTimeout(domain.WorkflowSettings{TimeoutSeconds: 60}))
+	assert.Equal(t, time.Hour, cfg.RunTimeout(domain.WorkflowSettings{TimeoutSeconds: 7200}))
+	assert.Equal(t, time.Minute, Config{}.RunTimeout(domain.WorkflowSettings{TimeoutSeconds: 60}))
+}
+
+func TestWorkflowEngine_Execute_NodeTimeout(t *testing.T) {
+	RegisterNode("test_slow", func() domain.INodeExecutor { return &slowNode{} })
+
+	runID := uuid.New()
+	workflowID := uuid.New()
+	nodes := []domain.WorkflowNode{
+		{ID: uuid.New(), WorkflowID: workflowID, Data: map[string]interface{}{"type": "test_slow"}},
+	}
+
+	mockRunRepo := newMockRunRepo()
+	mockLogRepo := new(MockLogRepo)
+	mockRunRepo.On("UpdateStatus", mock.Anything, runID, mock.Anything, mock.Anything).Return(nil)
+	mockLogRepo.On("Create", mock.Anything, mock.Anything).Return(&domain.NodeRunLog{ID: uuid.New()}, nil)
+	mockLogRepo.On("Update", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	engine := NewWorkflowEngine(nodes, nil, runID, workflowID, mockLogRepo, mockRunRepo)
+	engine.Config.DefaultNodeTimeout = 20 * time.Millisecond
+
+	err := engine.Execute(context.Background())
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "timed out after 20ms")
+	mockLogRepo.AssertCalled(t, "Update", mock.Anything, mock.Anything, mock.MatchedBy(func(req *domain.UpdateNodeRunLogRequest) bool {
+		return req.Status == domain.NodeRunLogStatusTimedOut
+	}))
+	mockRunRepo.AssertCalled(t, "UpdateStatus", mock.Anything, runID, domain.WorkflowRunStatusFailed, mock.Anything)
+}
+
+func TestWorkflowEngine_Execute_RunTimeout(t *testing.T) {
+	RegisterNode("test_slow", func() domain.INodeExecutor { return &slowNode{} })
+
+	runID := uuid.New()
+	workflowID := uuid.New()
+	nodes := []domain.WorkflowNode{
+		{ID: uuid.New(), WorkflowID: workflowID, Data: map[string]interface{}{"type": "test_slow"}},
+	}
+
+	mockRunRepo := newMockRunRepo()
+	mockLogRepo := new(MockLogRepo)
+	mockRunRepo.On("UpdateStatus", mock.Anything, runID, mock.Anything, mock.Anything).Return(nil)
+	mockLogRepo.On("Create", mock.Anything, mock.Anything).Return(&domain.NodeRunLog{ID: uuid.New()}, nil)
+	mockLogRepo.On("Update", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	engine := NewWorkflowEngine(nodes, nil, runID, workflowID, mockLogRepo, mockRunRepo)
+	engine.Config = Config{DefaultNodeTimeout: time.Minute, MaxRunDuration: 20 * time.Millisecond}
+
+	err := engine.Execute(context.Background())
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "maximum duration")
+	mockLogRepo.AssertCalled(t, "Update", mock.Anything, mock.Anything, mock.MatchedBy(func(req *domain.UpdateNodeRunLogRequest) bool {
+		return req.Status == domain.NodeRunLogStatusTimedOut
+	}))
+}
@@ -0,0 +1,86 @@
+package engine
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/mr-isik/loki-backend/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestWorkflowEngine_RunInput(t *testing.T) {
+	runID := uuid.New()
+	triggerID := uuid.New()
+	nextID := uuid.New()
+	nodes := []domain.WorkflowNode{
+		{ID: triggerID, Data: map[string]interface{}{"type": "manual_trigger"}},
+		{ID: nextID, Data: map[string]interface{}{
+			"type": "set_data",
+			"data": map[string]interface{}{
+				"order":    "{{ $input.input.order_id }}",
+				"customer": "{{ $run.input.customer }}",
+			},
+		}},
+	}
+	edges := []domain.WorkflowEdge{
+		{ID: uuid.New(), SourceNodeID: triggerID, TargetNodeID: nextID, SourceHandle: "output", TargetHandle: "input"},
+	}
+
+	mockRunRepo := newMockRunRepo()
+	mockLogRepo := new(MockLogRepo)
+	mockRunRepo.On("UpdateStatus", mock.Anything, runID, mock.Anything, mock.Anything).Return(nil)
+	mockLogRepo.On("Create", mock.Anything, mock.Anything).Return(&domain.NodeRunLog{ID: uuid.New()}, nil)
+	mockLogRepo.On("Update", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	engine := NewWorkflowEngine(nodes, edges, runID, uuid.New(), mockLogRepo, mockRunRepo)
+	engine.Input = map[string]interface{}{"order_id": "A-1", "customer": "ada"}
+	assert.NoError(t, engine.Execute(context.Background()))
+
+	assert.Equal(t, engine.Input, engine.nodeOutputs[triggerID])
+	assert.Equal(t, "A-1", engine.nodeOutputs[nextID]["order"])
+	assert.Equal(t, "ada", engine.nodeOutputs[nextID]["customer"])
+}
+
+func TestWorkflowEngine_StartsAtFiringTrigger(t *testing.T) {
+	// Topology: Cron -> OnCron -> Shared <- OnHook <- Hook
+	cronID := uuid.New()
+	onCronID := uuid.New()
+	hookID := uuid.New()
+	onHookID := uuid.New()
+	sharedID := uuid.New()
+	nodes := []domain.WorkflowNode{
+		{ID: cronID, Data: map[string]interface{}{"type": "manual_trigger"}},
+		{ID: onCronID, Data: map[string]interface{}{"type": "set_data", "data": map[string]interface{}{"via": "cron"}}},
+		{ID: hookID, Data: map[string]interface{}{"type": "manual_trigger"}},
+		{ID: onHookID, Data: map[string]interface{}{"type": "set_data", "data": map[string]interface{}{"via": "hook"}}},
+		{ID: sharedID, Data: map[string]interface{}{"type": "set_data", "data": map[string]interface{}{"done": true}}},
+	}
+	edges := []domain.WorkflowEdge{
+		{ID: uuid.New(), SourceNodeID: cronID, TargetNodeID: onCronID, SourceHandle: "output", TargetHandle: "input"},
+		{ID: uuid.New(), SourceNodeID: hookID, TargetNodeID: onHookID, SourceHandle: "output", TargetHandle: "input"},
+		{ID: uuid.New(), SourceNodeID: onCronID, TargetNodeID: sharedID, SourceHandle: "output", TargetHandle: "input"},
+		{ID: uuid.New(), SourceNodeID: onHookID, TargetNodeID: sharedID, SourceHandle: "output", TargetHandle: "input"},
+	}
+
+	observer := &recordingObserver{}
+	engine := NewWorkflowEngine(nodes, edges, uuid.New(), uuid.New(), nil, nil)
+	engine.AddObserver(observer)
+	engine.Input = map[string]interface{}{"trigger_node_id": cronID.String()}
+	assert.NoError(t, engine.Execute(context.Background()))
+
+	assert.Contains(t, engine.nodeOutputs, cronID)
+	assert.Equal(t, "cron", engine.nodeOutputs[onCronID]["via"])
+	assert.Equal(t, true, engine.nodeOutputs[sharedID]["done"])
+	assert.NotContains(t, engine.nodeOutputs, hookID)
+	assert.NotContains(t, engine.nodeOutputs, onHookID)
+
+	skipped := map[uuid.UUID]bool{}
+	for _, event := range observer.nodeFinishes {
+		if event.Status == domain.NodeRunLogStatusSkipped {
+			skipped[event.NodeID] = true
+		}
+	}
+	assert.Equal(t, map[uuid.UUID]bool{hookID: true, onHookID: true}, skipped)
+}
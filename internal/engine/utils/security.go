@@ -78,7 +78,7 @@ func SanitizeError(err error) string {
 		return ""
 	}
 	if errors.Is(err, context.DeadlineExceeded) {
-		return "Execution timed out after 10 seconds."
+		return "Execution timed out."
 	}
 
 	errMsg := err.Error()
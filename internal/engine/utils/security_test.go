@@ -75,7 +75,7 @@ func TestSanitizeError(t *testing.T) {
 		{
 			name:     "Timeout",
 			input:    context.DeadlineExceeded,
-			expected: "Execution timed out after 10 seconds.",
+			expected: "Execution timed out.",
 		},
 		{
 			name:     "Internal Docker Error",
@@ -0,0 +1,353 @@
+package engine
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/mr-isik/loki-backend/internal/domain"
+)
+
+// triggerCategory is the node template category of trigger nodes.
+const triggerCategory = "trigger"
+
+// ValidateGraph runs the static checks on a workflow graph. templates maps
+// template IDs to the templates the nodes were created from; nodes whose
+// template is unknown skip the handle checks.
+func ValidateGraph(nodes []domain.WorkflowNode, edges []domain.WorkflowEdge, templates map[uuid.UUID]*domain.NodeTemplate) *domain.WorkflowValidationResult {
+	result := &domain.WorkflowValidationResult{
+		Valid:    true,
+		Errors:   []domain.ValidationIssue{},
+		Warnings: []domain.ValidationIssue{},
+	}
+
+	nodeMap := make(map[uuid.UUID]domain.WorkflowNode, len(nodes))
+	for _, node := range nodes {
+		nodeMap[node.ID] = node
+	}
+
+	for _, node := range nodes {
+		validateNode(result, node, templates)
+	}
+
+	// Only edges between existing nodes take part in the graph checks.
+	var validEdges []domain.WorkflowEdge
+	for _, edge := range edges {
+		if validateEdge(result, edge, nodeMap, templates) {
+			validEdges = append(validEdges, edge)
+		}
+	}
+
+	for _, nodeID := range findCycleNodes(nodeMap, validEdges) {
+		result.Add(domain.ValidationIssue{
+			Severity: domain.ValidationSeverityError,
+			Code:     domain.ValidationCodeCycle,
+			Message:  "Node is part of a cycle",
+			NodeID:   uuidPtr(nodeID),
+		})
+	}
+
+	for _, nodeID := range findUnreachableNodes(nodeMap, validEdges, templates) {
+		result.Add(domain.ValidationIssue{
+			Severity: domain.ValidationSeverityWarning,
+			Code:     domain.ValidationCodeUnreachable,
+			Message:  "Node cannot be reached from any trigger and will run as a start node",
+			NodeID:   uuidPtr(nodeID),
+		})
+	}
+
+	return result
+}
+
+// validateNode checks the type and the required configuration of a node.
+func validateNode(result *domain.WorkflowValidationResult, node domain.WorkflowNode, templates map[uuid.UUID]*domain.NodeTemplate) {
+	nodeType, _ := node.Data["type"].(string)
+	if nodeType == "" {
+		result.Add(domain.ValidationIssue{
+			Severity: domain.ValidationSeverityError,
+			Code:     domain.ValidationCodeUnknownType,
+			Message:  "Node has no type",
+			NodeID:   uuidPtr(node.ID),
+			Field:    "type",
+		})
+		return
+	}
+
+	if template, ok := templates[node.TemplateID]; !ok {
+		result.Add(domain.ValidationIssue{
+			Severity: domain.ValidationSeverityWarning,
+			Code:     domain.ValidationCodeUnknownTemplate,
+			Message:  "Node template not found; handles cannot be checked",
+			NodeID:   uuidPtr(node.ID),
+		})
+	} else {
+		if template.TypeKey != nodeType {
+			result.Add(domain.ValidationIssue{
+				Severity: domain.ValidationSeverityWarning,
+				Code:     domain.ValidationCodeUnknownTemplate,
+				Message:  fmt.Sprintf("Node type %q does not match its template type %q", nodeType, template.TypeKey),
+				NodeID:   uuidPtr(node.ID),
+				Field:    "type",
+			})
+		}
+		// A failed node has no branch to pick, so continuing along the
+		// regular output would run every branch of a branching node.
+		if parseNodeSettings(node).OnError == OnErrorContinueRegularOutput && len(regularHandles(template.Outputs)) > 1 {
+			result.Add(domain.ValidationIssue{
+				Severity: domain.ValidationSeverityError,
+				Code:     domain.ValidationCodeInvalidSetting,
+				Message:  "Branching nodes cannot continue along the regular output on error",
+				NodeID:   uuidPtr(node.ID),
+				Field:    "settings.on_error",
+			})
+		}
+	}
+
+	executor, err := defaultRegistry.Get(nodeType)
+	if err != nil {
+		result.Add(domain.ValidationIssue{
+			Severity: domain.ValidationSeverityError,
+			Code:     domain.ValidationCodeUnknownType,
+			Message:  fmt.Sprintf("Unknown node type %q", nodeType),
+			NodeID:   uuidPtr(node.ID),
+			Field:    "type",
+		})
+		return
+	}
+
+	validator, ok := executor.(domain.INodeConfigValidator)
+	if !ok {
+		return
+	}
+	for _, field := range validator.RequiredFields() {
+		if isEmptyValue(fieldValue(node.Data, field)) {
+			result.Add(domain.ValidationIssue{
+				Severity: domain.ValidationSeverityError,
+				Code:     domain.ValidationCodeMissingConfig,
+				Message:  fmt.Sprintf("Field %q is required", field),
+				NodeID:   uuidPtr(node.ID),
+				Field:    field,
+			})
+		}
+	}
+}
+
+// validateEdge checks that an edge connects existing nodes through declared
+// handles. It reports whether both ends of the edge exist.
+func validateEdge(result *domain.WorkflowValidationResult, edge domain.WorkflowEdge, nodes map[uuid.UUID]domain.WorkflowNode, templates map[uuid.UUID]*domain.NodeTemplate) bool {
+	source, sourceOk := nodes[edge.SourceNodeID]
+	target, targetOk := nodes[edge.TargetNodeID]
+
+	if !sourceOk || !targetOk {
+		issue := domain.ValidationIssue{
+			Severity: domain.ValidationSeverityError,
+			Code:     domain.ValidationCodeMissingNode,
+			EdgeID:   uuidPtr(edge.ID),
+		}
+		switch {
+		case !sourceOk && !targetOk:
+			issue.Message = "Edge connects two nodes that do not exist"
+		case !sourceOk:
+			issue.Message = fmt.Sprintf("Edge source node %s does not exist", edge.SourceNodeID)
+			issue.NodeID = uuidPtr(edge.TargetNodeID)
+		default:
+			issue.Message = fmt.Sprintf("Edge target node %s does not exist", edge.TargetNodeID)
+			issue.NodeID = uuidPtr(edge.SourceNodeID)
+		}
+		result.Add(issue)
+		return false
+	}
+
+	// Every node may route failures to its error output.
+	if template, ok := templates[source.TemplateID]; ok && edge.SourceHandle != errorHandle && !hasHandle(template.Outputs, edge.SourceHandle) {
+		result.Add(domain.ValidationIssue{
+			Severity: domain.ValidationSeverityError,
+			Code:     domain.ValidationCodeUnknownHandle,
+			Message:  fmt.Sprintf("Output handle %q is not declared by %s", edge.SourceHandle, template.Name),
+			NodeID:   uuidPtr(source.ID),
+			EdgeID:   uuidPtr(edge.ID),
+		})
+	}
+	if template, ok := templates[target.TemplateID]; ok && !hasHandle(template.Inputs, edge.TargetHandle) {
+		result.Add(domain.ValidationIssue{
+			Severity: domain.ValidationSeverityError,
+			Code:     domain.ValidationCodeUnknownHandle,
+			Message:  fmt.Sprintf("Input handle %q is not declared by %s", edge.TargetHandle, template.Name),
+			NodeID:   uuidPtr(target.ID),
+			EdgeID:   uuidPtr(edge.ID),
+		})
+	}
+
+	return true
+}
+
+// findCycleNodes returns the nodes that are part of a cycle, using Tarjan's
+// strongly connected components algorithm.
+func findCycleNodes(nodes map[uuid.UUID]domain.WorkflowNode, edges []domain.WorkflowEdge) []uuid.UUID {
+	successors := make(map[uuid.UUID][]uuid.UUID)
+	selfLoops := make(map[uuid.UUID]bool)
+	for _, edge := range edges {
+		successors[edge.SourceNodeID] = append(successors[edge.SourceNodeID], edge.TargetNodeID)
+		if edge.SourceNodeID == edge.TargetNodeID {
+			selfLoops[edge.SourceNodeID] = true
+		}
+	}
+
+	index := 0
+	indexes := make(map[uuid.UUID]int)
+	lowLinks := make(map[uuid.UUID]int)
+	onStack := make(map[uuid.UUID]bool)
+	var stack []uuid.UUID
+	var cyclic []uuid.UUID
+
+	var visit func(id uuid.UUID)
+	visit = func(id uuid.UUID) {
+		indexes[id] = index
+		lowLinks[id] = index
+		index++
+		stack = append(stack, id)
+		onStack[id] = true
+
+		for _, next := range successors[id] {
+			if _, seen := indexes[next]; !seen {
+				visit(next)
+				lowLinks[id] = min(lowLinks[id], lowLinks[next])
+			} else if onStack[next] {
+				lowLinks[id] = min(lowLinks[id], indexes[next])
+			}
+		}
+
+		if lowLinks[id] != indexes[id] {
+			return
+		}
+
+		var component []uuid.UUID
+		for {
+			top := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			onStack[top] = false
+			component = append(component, top)
+			if top == id {
+				break
+			}
+		}
+		if len(component) > 1 || selfLoops[id] {
+			cyclic = append(cyclic, component...)
+		}
+	}
+
+	for _, id := range sortedNodeIDs(nodes) {
+		if _, seen := indexes[id]; !seen {
+			visit(id)
+		}
+	}
+
+	return cyclic
+}
+
+// findUnreachableNodes returns the nodes that no trigger node leads to. A
+// workflow without trigger nodes has no unreachable nodes.
+func findUnreachableNodes(nodes map[uuid.UUID]domain.WorkflowNode, edges []domain.WorkflowEdge, templates map[uuid.UUID]*domain.NodeTemplate) []uuid.UUID {
+	var queue []uuid.UUID
+	reached := make(map[uuid.UUID]bool)
+	for _, id := range sortedNodeIDs(nodes) {
+		if template, ok := templates[nodes[id].TemplateID]; ok && template.Category == triggerCategory {
+			reached[id] = true
+			queue = append(queue, id)
+		}
+	}
+	if len(queue) == 0 {
+		return nil
+	}
+
+	for len(queue) > 0 {
+		curr := queue[0]
+		queue = queue[1:]
+		for _, edge := range edges {
+			if edge.SourceNodeID == curr && !reached[edge.TargetNodeID] {
+				reached[edge.TargetNodeID] = true
+				queue = append(queue, edge.TargetNodeID)
+			}
+		}
+	}
+
+	var unreachable []uuid.UUID
+	for _, id := range sortedNodeIDs(nodes) {
+		if !reached[id] {
+			unreachable = append(unreachable, id)
+		}
+	}
+	return unreachable
+}
+
+// hasCycle reports whether the engine's graph contains a cycle.
+func (e *WorkflowEngine) hasCycle() bool {
+	return len(findCycleNodes(e.Nodes, e.Edges)) > 0
+}
+
+// hasHandle reports whether a template handle list declares id.
+func hasHandle(handles []map[string]interface{}, id string) bool {
+	for _, handle := range handles {
+		if handleID, _ := handle["id"].(string); handleID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// regularHandles returns the IDs of the declared handles other than the
+// error output.
+func regularHandles(handles []map[string]interface{}) []string {
+	var ids []string
+	for _, handle := range handles {
+		if id, _ := handle["id"].(string); id != "" && id != errorHandle {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// fieldValue returns a field of node data. Fields of nested objects are
+// separated by dots, as in auth.type.
+func fieldValue(data map[string]interface{}, field string) interface{} {
+	var value interface{} = data
+	for _, key := range strings.Split(field, ".") {
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		value = object[key]
+	}
+	return value
+}
+
+func isEmptyValue(v interface{}) bool {
+	switch val := v.(type) {
+	case nil:
+		return true
+	case string:
+		return val == ""
+	case []interface{}:
+		return len(val) == 0
+	case map[string]interface{}:
+		return len(val) == 0
+	default:
+		return false
+	}
+}
+
+// sortedNodeIDs returns the node IDs in a stable order so that validation
+// results do not change between calls.
+func sortedNodeIDs(nodes map[uuid.UUID]domain.WorkflowNode) []uuid.UUID {
+	ids := make([]uuid.UUID, 0, len(nodes))
+	for id := range nodes {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i].String() < ids[j].String() })
+	return ids
+}
+
+func uuidPtr(id uuid.UUID) *uuid.UUID {
+	return &id
+}
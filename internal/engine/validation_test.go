@@ -0,0 +1,166 @@
+package engine
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/mr-isik/loki-backend/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func validationTemplates() (map[uuid.UUID]*domain.NodeTemplate, uuid.UUID, uuid.UUID) {
+	webhookID := uuid.New()
+	httpID := uuid.New()
+	return map[uuid.UUID]*domain.NodeTemplate{
+		webhookID: {
+			ID: webhookID, Name: "Webhook", TypeKey: "webhook", Category: "trigger",
+			Outputs: []map[string]interface{}{{"id": "output"}},
+		},
+		httpID: {
+			ID: httpID, Name: "HTTP Request", TypeKey: "http_request", Category: "integration",
+			Inputs:  []map[string]interface{}{{"id": "input"}},
+			Outputs: []map[string]interface{}{{"id": "output_success"}},
+		},
+	}, webhookID, httpID
+}
+
+func issueCodes(issues []domain.ValidationIssue) []string {
+	codes := make([]string, len(issues))
+	for i, issue := range issues {
+		codes[i] = issue.Code
+	}
+	return codes
+}
+
+func TestValidateGraph_Valid(t *testing.T) {
+	templates, webhookID, httpID := validationTemplates()
+	trigger := domain.WorkflowNode{ID: uuid.New(), TemplateID: webhookID, Data: map[string]interface{}{"type": "webhook", "auth": map[string]interface{}{"type": "none"}}}
+	request := domain.WorkflowNode{ID: uuid.New(), TemplateID: httpID, Data: map[string]interface{}{"type": "http_request", "url": "https://example.com"}}
+	edges := []domain.WorkflowEdge{
+		{ID: uuid.New(), SourceNodeID: trigger.ID, TargetNodeID: request.ID, SourceHandle: "output", TargetHandle: "input"},
+	}
+
+	result := ValidateGraph([]domain.WorkflowNode{trigger, request}, edges, templates)
+
+	assert.True(t, result.Valid)
+	assert.Empty(t, result.Errors)
+	assert.Empty(t, result.Warnings)
+}
+
+func TestValidateGraph_Errors(t *testing.T) {
+	templates, webhookID, httpID := validationTemplates()
+	trigger := domain.WorkflowNode{ID: uuid.New(), TemplateID: webhookID, Data: map[string]interface{}{"type": "webhook", "auth": map[string]interface{}{"type": "none"}}}
+	first := domain.WorkflowNode{ID: uuid.New(), TemplateID: httpID, Data: map[string]interface{}{"type": "http_request", "url": "https://example.com"}}
+	second := domain.WorkflowNode{ID: uuid.New(), TemplateID: httpID, Data: map[string]interface{}{"type": "http_request"}}
+	unknown := domain.WorkflowNode{ID: uuid.New(), TemplateID: httpID, Data: map[string]interface{}{"type": "does_not_exist"}}
+
+	edges := []domain.WorkflowEdge{
+		{ID: uuid.New(), SourceNodeID: trigger.ID, TargetNodeID: first.ID, SourceHandle: "output", TargetHandle: "input"},
+		{ID: uuid.New(), SourceNodeID: first.ID, TargetNodeID: second.ID, SourceHandle: "output_success", TargetHandle: "input"},
+		{ID: uuid.New(), SourceNodeID: second.ID, TargetNodeID: first.ID, SourceHandle: "output_missing", TargetHandle: "input"},
+		{ID: uuid.New(), SourceNodeID: second.ID, TargetNodeID: uuid.New(), SourceHandle: "output_success", TargetHandle: "input"},
+	}
+
+	result := ValidateGraph([]domain.WorkflowNode{trigger, first, second, unknown}, edges, templates)
+
+	assert.False(t, result.Valid)
+	codes := issueCodes(result.Errors)
+	assert.Contains(t, codes, domain.ValidationCodeCycle)
+	assert.Contains(t, codes, domain.ValidationCodeUnknownHandle)
+	assert.Contains(t, codes, domain.ValidationCodeMissingNode)
+	assert.Contains(t, codes, domain.ValidationCodeMissingConfig)
+	assert.Contains(t, codes, domain.ValidationCodeUnknownType)
+	assert.Contains(t, issueCodes(result.Warnings), domain.ValidationCodeUnreachable)
+
+	for _, issue := range result.Errors {
+		if issue.Code == domain.ValidationCodeMissingConfig {
+			assert.Equal(t, second.ID, *issue.NodeID)
+			assert.Equal(t, "url", issue.Field)
+		}
+	}
+}
+
+func TestValidateGraph_WebhookRequiresAuthType(t *testing.T) {
+	templates, webhookID, _ := validationTemplates()
+	unset := domain.WorkflowNode{ID: uuid.New(), TemplateID: webhookID, Data: map[string]interface{}{"type": "webhook"}}
+	empty := domain.WorkflowNode{ID: uuid.New(), TemplateID: webhookID, Data: map[string]interface{}{"type": "webhook", "auth": map[string]interface{}{"token": "t"}}}
+
+	result := ValidateGraph([]domain.WorkflowNode{unset, empty}, nil, templates)
+
+	var flagged []uuid.UUID
+	for _, issue := range result.Errors {
+		if issue.Code == domain.ValidationCodeMissingConfig {
+			flagged = append(flagged, *issue.NodeID)
+			assert.Equal(t, "auth.type", issue.Field)
+		}
+	}
+	assert.ElementsMatch(t, []uuid.UUID{unset.ID, empty.ID}, flagged)
+}
+
+func TestValidateGraph_ErrorHandleAlwaysAllowed(t *testing.T) {
+	templates, _, httpID := validationTemplates()
+	source := domain.WorkflowNode{ID: uuid.New(), TemplateID: httpID, Data: map[string]interface{}{"type": "http_request", "url": "https://example.com"}}
+	target := domain.WorkflowNode{ID: uuid.New(), TemplateID: httpID, Data: map[string]interface{}{"type": "http_request", "url": "https://example.com"}}
+	edges := []domain.WorkflowEdge{
+		{ID: uuid.New(), SourceNodeID: source.ID, TargetNodeID: target.ID, SourceHandle: errorHandle, TargetHandle: "input"},
+	}
+
+	result := ValidateGraph([]domain.WorkflowNode{source, target}, edges, templates)
+
+	assert.True(t, result.Valid)
+}
+
+func TestValidateGraph_ContinueRegularOutputOnBranchingNode(t *testing.T) {
+	templates, _, httpID := validationTemplates()
+	conditionID := uuid.New()
+	templates[conditionID] = &domain.NodeTemplate{
+		ID: conditionID, Name: "Condition", TypeKey: "condition", Category: "control",
+		Inputs:  []map[string]interface{}{{"id": "input"}},
+		Outputs: []map[string]interface{}{{"id": "output_true"}, {"id": "output_false"}},
+	}
+	settings := map[string]interface{}{"on_error": OnErrorContinueRegularOutput}
+	condition := domain.WorkflowNode{ID: uuid.New(), TemplateID: conditionID, Data: map[string]interface{}{"type": "condition", "settings": settings}}
+	request := domain.WorkflowNode{ID: uuid.New(), TemplateID: httpID, Data: map[string]interface{}{"type": "http_request", "url": "https://example.com", "settings": settings}}
+
+	result := ValidateGraph([]domain.WorkflowNode{condition, request}, nil, templates)
+
+	var flagged []uuid.UUID
+	for _, issue := range result.Errors {
+		if issue.Code == domain.ValidationCodeInvalidSetting {
+			flagged = append(flagged, *issue.NodeID)
+			assert.Equal(t, "settings.on_error", issue.Field)
+		}
+	}
+	assert.Equal(t, []uuid.UUID{condition.ID}, flagged)
+}
+
+func TestWorkflowEngine_Execute_Cycle(t *testing.T) {
+	runID := uuid.New()
+	startID := uuid.New()
+	nodeAID := uuid.New()
+	nodeBID := uuid.New()
+
+	nodes := []domain.WorkflowNode{
+		{ID: startID, Data: map[string]interface{}{"type": "set_data"}},
+		{ID: nodeAID, Data: map[string]interface{}{"type": "set_data"}},
+		{ID: nodeBID, Data: map[string]interface{}{"type": "set_data"}},
+	}
+	edges := []domain.WorkflowEdge{
+		{ID: uuid.New(), SourceNodeID: startID, TargetNodeID: nodeAID, SourceHandle: "output", TargetHandle: "input"},
+		{ID: uuid.New(), SourceNodeID: nodeAID, TargetNodeID: nodeBID, SourceHandle: "output", TargetHandle: "input"},
+		{ID: uuid.New(), SourceNodeID: nodeBID, TargetNodeID: nodeAID, SourceHandle: "output", TargetHandle: "input"},
+	}
+
+	mockRunRepo := newMockRunRepo()
+	mockLogRepo := new(MockLogRepo)
+	mockRunRepo.On("UpdateStatus", mock.Anything, runID, mock.Anything, mock.Anything).Return(nil)
+
+	engine := NewWorkflowEngine(nodes, edges, runID, uuid.New(), mockLogRepo, mockRunRepo)
+	err := engine.Execute(context.Background())
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "cycle")
+	mockRunRepo.AssertCalled(t, "UpdateStatus", mock.Anything, runID, domain.WorkflowRunStatusFailed, mock.Anything)
+}
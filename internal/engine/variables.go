@@ -0,0 +1,62 @@
+package engine
+
+import (
+	"github.com/mr-isik/loki-backend/internal/domain"
+)
+
+// setVariableNodeType is the type key of the node that writes run variables.
+const setVariableNodeType = "set_variable"
+
+// UpdateVariable implements domain.RunVariables. Loop iterations share the
+// variables of the run they belong to, so updates go to the root engine.
+func (e *WorkflowEngine) UpdateVariable(name string, fn func(current interface{}, exists bool) (interface{}, error)) (interface{}, error) {
+	root := e.rootEngine()
+
+	root.mu.Lock()
+	defer root.mu.Unlock()
+
+	current, exists := root.variables[name]
+	value, err := fn(current, exists)
+	if err != nil {
+		return nil, err
+	}
+	root.variables[name] = value
+	return value, nil
+}
+
+// Variables returns a copy of the run variables.
+func (e *WorkflowEngine) Variables() map[string]interface{} {
+	root := e.rootEngine()
+
+	root.mu.RLock()
+	defer root.mu.RUnlock()
+
+	vars := make(map[string]interface{}, len(root.variables))
+	for name, value := range root.variables {
+		vars[name] = value
+	}
+	return vars
+}
+
+// restoreVariable applies the write of a set_variable node restored from a
+// checkpoint again, since variables themselves are not checkpointed.
+func (e *WorkflowEngine) restoreVariable(node domain.WorkflowNode, output map[string]interface{}) {
+	if nodeType, _ := node.Data["type"].(string); nodeType != setVariableNodeType {
+		return
+	}
+	name, _ := output["name"].(string)
+	if name == "" {
+		return
+	}
+	e.UpdateVariable(name, func(interface{}, bool) (interface{}, error) {
+		return output["value"], nil
+	})
+}
+
+// rootEngine returns the engine of the run a loop sub-engine belongs to.
+func (e *WorkflowEngine) rootEngine() *WorkflowEngine {
+	for e.parent != nil {
+		e = e.parent
+	}
+	return e
+}
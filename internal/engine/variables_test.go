@@ -0,0 +1,90 @@
+package engine
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/mr-isik/loki-backend/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestWorkflowEngine_RunVariables(t *testing.T) {
+	// Topology: Start -> (Inc x10 in parallel) -> Read. Read is not connected
+	// to a node that outputs the counter; it reads it through $vars.
+	runID := uuid.New()
+	startID := uuid.New()
+	readID := uuid.New()
+
+	nodes := []domain.WorkflowNode{
+		{ID: startID, Data: map[string]interface{}{"type": "set_data"}},
+		{ID: readID, Data: map[string]interface{}{
+			"type": "set_data",
+			"data": map[string]interface{}{"count": "{{ $vars.count }}"},
+		}},
+	}
+	var edges []domain.WorkflowEdge
+	for i := 0; i < 10; i++ {
+		incID := uuid.New()
+		nodes = append(nodes, domain.WorkflowNode{ID: incID, Data: map[string]interface{}{
+			"type": "set_variable", "name": "count", "operation": "increment",
+		}})
+		edges = append(edges,
+			domain.WorkflowEdge{ID: uuid.New(), SourceNodeID: startID, TargetNodeID: incID, SourceHandle: "output", TargetHandle: "input"},
+			domain.WorkflowEdge{ID: uuid.New(), SourceNodeID: incID, TargetNodeID: readID, SourceHandle: "output", TargetHandle: "input"},
+		)
+	}
+
+	mockRunRepo := newMockRunRepo()
+	mockLogRepo := new(MockLogRepo)
+	mockRunRepo.On("UpdateStatus", mock.Anything, runID, mock.Anything, mock.Anything).Return(nil)
+	mockLogRepo.On("Create", mock.Anything, mock.Anything).Return(&domain.NodeRunLog{ID: uuid.New()}, nil)
+	mockLogRepo.On("Update", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	engine := NewWorkflowEngine(nodes, edges, runID, uuid.New(), mockLogRepo, mockRunRepo)
+	assert.NoError(t, engine.Execute(context.Background()))
+
+	assert.Equal(t, 10.0, engine.Variables()["count"])
+	engine.mu.RLock()
+	assert.Equal(t, 10.0, engine.nodeOutputs[readID]["count"])
+	engine.mu.RUnlock()
+}
+
+func TestWorkflowEngine_Resume_RestoresVariables(t *testing.T) {
+	runID := uuid.New()
+	setID := uuid.New()
+	readID := uuid.New()
+
+	nodes := []domain.WorkflowNode{
+		{ID: setID, Data: map[string]interface{}{"type": "set_variable", "name": "token", "value": "fresh"}},
+		{ID: readID, Data: map[string]interface{}{
+			"type": "set_data",
+			"data": map[string]interface{}{"token": "{{ $vars.token }}"},
+		}},
+	}
+	edges := []domain.WorkflowEdge{
+		{ID: uuid.New(), SourceNodeID: setID, TargetNodeID: readID, SourceHandle: "output", TargetHandle: "input"},
+	}
+
+	mockRunRepo := newMockRunRepo()
+	mockLogRepo := new(MockLogRepo)
+	mockStateRepo := new(MockStateRepo)
+	mockRunRepo.On("UpdateStatus", mock.Anything, runID, mock.Anything, mock.Anything).Return(nil)
+	mockLogRepo.On("Create", mock.Anything, mock.Anything).Return(&domain.NodeRunLog{ID: uuid.New()}, nil)
+	mockLogRepo.On("Update", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	mockLogRepo.On("CancelUnfinished", mock.Anything, runID).Return(nil)
+	mockStateRepo.On("Save", mock.Anything, mock.Anything).Return(nil)
+	mockStateRepo.On("GetByRunID", mock.Anything, runID).Return([]*domain.WorkflowRunNodeState{
+		{RunID: runID, NodeID: setID, Status: domain.NodeRunLogStatusCompleted, TriggeredHandle: "output",
+			Output: map[string]interface{}{"name": "token", "value": "restored"}},
+	}, nil)
+
+	engine := NewWorkflowEngine(nodes, edges, runID, uuid.New(), mockLogRepo, mockRunRepo)
+	engine.StateRepo = mockStateRepo
+	assert.NoError(t, engine.Resume(context.Background()))
+
+	engine.mu.RLock()
+	assert.Equal(t, "restored", engine.nodeOutputs[readID]["token"])
+	engine.mu.RUnlock()
+}
@@ -1,7 +1,16 @@
 package handler
 
+import "github.com/mr-isik/loki-backend/internal/domain"
+
 // ErrorResponse represents an error response
 type ErrorResponse struct {
 	Error   string `json:"error"`
 	Message string `json:"message,omitempty"`
 }
+
+// ValidationErrorResponse is returned when a workflow graph fails validation
+type ValidationErrorResponse struct {
+	Error      string                           `json:"error"`
+	Message    string                           `json:"message,omitempty"`
+	Validation *domain.WorkflowValidationResult `json:"validation"`
+}
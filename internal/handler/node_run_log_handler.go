@@ -109,6 +109,7 @@ func (h *NodeRunLogHandler) GetNodeRunLog(c *fiber.Ctx) error {
 // @Success 200 {object} domain.PaginatedResponse "Returns paginated logs"
 // @Failure 400 {object} ErrorResponse
 // @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
 // @Failure 500 {object} ErrorResponse
 // @Router /workflow-runs/{run_id}/logs [get]
 func (h *NodeRunLogHandler) GetNodeRunLogsByRunID(c *fiber.Ctx) error {
@@ -121,8 +122,16 @@ func (h *NodeRunLogHandler) GetNodeRunLogsByRunID(c *fiber.Ctx) error {
 		})
 	}
 
-	logs, err := h.service.GetNodeRunLogsByRunID(c.Context(), runID)
+	userID := c.Locals("userID").(uuid.UUID)
+
+	logs, err := h.service.GetNodeRunLogsByRunID(c.Context(), runID, userID)
 	if err != nil {
+		if errors.Is(err, domain.ErrWorkflowRunNotFound) || errors.Is(err, domain.ErrNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(ErrorResponse{
+				Error:   "not_found",
+				Message: "Workflow run not found",
+			})
+		}
 		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
 			Error:   "internal_error",
 			Message: "Failed to retrieve node run logs",
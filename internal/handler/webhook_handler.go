@@ -0,0 +1,121 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/mr-isik/loki-backend/internal/domain"
+	"github.com/mr-isik/loki-backend/internal/trigger"
+)
+
+type WebhookHandler struct {
+	service    domain.WebhookService
+	runService domain.WorkflowRunService
+}
+
+// NewWebhookHandler creates a new webhook handler
+func NewWebhookHandler(service domain.WebhookService, runService domain.WorkflowRunService) *WebhookHandler {
+	return &WebhookHandler{
+		service:    service,
+		runService: runService,
+	}
+}
+
+// HandleWebhook handles inbound webhook requests
+// @Summary Trigger a workflow webhook
+// @Description Start a run of a published workflow from its webhook node. The request must match the node's method and path and pass its authentication (header token, basic auth or an HMAC signature). The run input carries the parsed body, query, headers and raw body. In the on_received response mode the run is returned with 202 right away; in the respond_node mode the request waits for the run and returns its output, which a respond node can set.
+// @Tags Webhooks
+// @Accept json
+// @Produce json
+// @Param workflow_id path string true "Workflow ID (UUID)"
+// @Param path path string false "Path configured on the webhook node"
+// @Success 200 {object} map[string]interface{}
+// @Success 202 {object} domain.WorkflowRunResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 405 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /hooks/{workflow_id}/{path} [post]
+func (h *WebhookHandler) HandleWebhook(c *fiber.Ctx) error {
+	workflowID, err := uuid.Parse(c.Params("workflow_id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
+			Error:   "invalid_workflow_id",
+			Message: "Invalid workflow ID",
+		})
+	}
+
+	headers := make(map[string]string)
+	for name, values := range c.GetReqHeaders() {
+		headers[strings.ToLower(name)] = strings.Join(values, ", ")
+	}
+
+	result, err := h.service.HandleWebhook(c.Context(), &domain.WebhookRequest{
+		WorkflowID: workflowID,
+		Method:     c.Method(),
+		Path:       c.Params("*"),
+		Headers:    headers,
+		Query:      c.Queries(),
+		Body:       append([]byte(nil), c.Body()...),
+	})
+	if err != nil {
+		if errors.Is(err, domain.ErrWebhookNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(ErrorResponse{
+				Error:   "not_found",
+				Message: "Webhook not found",
+			})
+		}
+		if errors.Is(err, domain.ErrWebhookMethodNotAllowed) {
+			return c.Status(fiber.StatusMethodNotAllowed).JSON(ErrorResponse{
+				Error:   "method_not_allowed",
+				Message: "The webhook does not accept this method",
+			})
+		}
+		if errors.Is(err, domain.ErrWebhookUnauthorized) {
+			return c.Status(fiber.StatusUnauthorized).JSON(ErrorResponse{
+				Error:   "unauthorized",
+				Message: "Invalid webhook credentials or signature",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+			Error:   "internal_error",
+			Message: "Failed to start workflow run",
+		})
+	}
+
+	if result.ResponseMode != trigger.WebhookRespondOnFinish {
+		return c.Status(fiber.StatusAccepted).JSON(result.Run)
+	}
+
+	// Wait for the run and answer with its result. The run keeps going in
+	// the background when the timeout expires.
+	ctx, cancel := context.WithTimeout(c.Context(), defaultSyncRunTimeout)
+	defer cancel()
+
+	run, finished, err := h.runService.WaitForRun(ctx, result.Run.ID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+			Error:   "internal_error",
+			Message: "Failed to wait for workflow run",
+		})
+	}
+	if !finished {
+		return c.Status(fiber.StatusAccepted).JSON(run)
+	}
+	if run.Status != domain.WorkflowRunStatusCompleted {
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+			Error:   "run_" + string(run.Status),
+			Message: "The workflow run did not complete",
+		})
+	}
+
+	output := run.OutputData
+	if output == nil {
+		output = map[string]interface{}{}
+	}
+	return c.JSON(output)
+}
@@ -2,39 +2,34 @@ package handler
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
 	"github.com/mr-isik/loki-backend/internal/domain"
-	"github.com/mr-isik/loki-backend/internal/engine"
 )
 
 type WorkflowHandler struct {
-	service     domain.WorkflowService
-	nodeService domain.WorkflowNodeService
-	edgeService domain.WorkflowEdgeService
-	runService  domain.WorkflowRunService
-	logRepo     domain.NodeRunLogRepository
-	runRepo     domain.WorkflowRunRepository
+	service          domain.WorkflowService
+	runService       domain.WorkflowRunService
+	executionService domain.WorkflowExecutionService
+	scheduleService  domain.WorkflowScheduleService
 }
 
 // NewWorkflowHandler creates a new workflow handler
 func NewWorkflowHandler(
 	service domain.WorkflowService,
-	nodeService domain.WorkflowNodeService,
-	edgeService domain.WorkflowEdgeService,
 	runService domain.WorkflowRunService,
-	logRepo domain.NodeRunLogRepository,
-	runRepo domain.WorkflowRunRepository,
+	executionService domain.WorkflowExecutionService,
+	scheduleService domain.WorkflowScheduleService,
 ) *WorkflowHandler {
 	return &WorkflowHandler{
-		service:     service,
-		nodeService: nodeService,
-		edgeService: edgeService,
-		runService:  runService,
-		logRepo:     logRepo,
-		runRepo:     runRepo,
+		service:          service,
+		runService:       runService,
+		executionService: executionService,
+		scheduleService:  scheduleService,
 	}
 }
 
@@ -138,6 +133,61 @@ func (h *WorkflowHandler) GetWorkflow(c *fiber.Ctx) error {
 	return c.JSON(workflow)
 }
 
+// GetWorkflowSchedule handles retrieving the cron schedule of a workflow
+// @Summary Get workflow schedule
+// @Description List the cron triggers of a workflow with their next fire times. Only published workflows are scheduled.
+// @Tags Workflows
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Workflow ID (UUID)"
+// @Param count query int false "Number of upcoming fire times per trigger (max 100)" default(5)
+// @Success 200 {object} domain.WorkflowScheduleResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /workflows/{id}/schedule [get]
+func (h *WorkflowHandler) GetWorkflowSchedule(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uuid.UUID)
+
+	idParam := c.Params("id")
+	id, err := uuid.Parse(idParam)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
+			Error:   "invalid_id",
+			Message: "Invalid workflow ID format",
+		})
+	}
+
+	count := c.QueryInt("count", 5)
+	if count < 1 || count > 100 {
+		count = 5
+	}
+
+	schedule, err := h.scheduleService.GetWorkflowSchedule(c.Context(), id, userID, count)
+	if err != nil {
+		if errors.Is(err, domain.ErrWorkflowNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(ErrorResponse{
+				Error:   "not_found",
+				Message: "Workflow not found",
+			})
+		}
+		if errors.Is(err, domain.ErrUnauthorized) {
+			return c.Status(fiber.StatusForbidden).JSON(ErrorResponse{
+				Error:   "forbidden",
+				Message: "You don't have access to this workflow",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+			Error:   "internal_error",
+			Message: "Failed to get workflow schedule",
+		})
+	}
+
+	return c.JSON(schedule)
+}
+
 // GetWorkspaceWorkflows handles retrieving all workflows in a workspace
 // @Summary Get workspace workflows
 // @Description Retrieve all workflows in a workspace with pagination
@@ -312,6 +362,7 @@ func (h *WorkflowHandler) DeleteWorkflow(c *fiber.Ctx) error {
 // @Failure 401 {object} ErrorResponse
 // @Failure 403 {object} ErrorResponse
 // @Failure 404 {object} ErrorResponse
+// @Failure 422 {object} ValidationErrorResponse
 // @Failure 500 {object} ErrorResponse
 // @Router /workflows/{id}/publish [post]
 func (h *WorkflowHandler) PublishWorkflow(c *fiber.Ctx) error {
@@ -328,6 +379,10 @@ func (h *WorkflowHandler) PublishWorkflow(c *fiber.Ctx) error {
 
 	err = h.service.PublishWorkflow(c.Context(), id, userID)
 	if err != nil {
+		var validationErr *domain.WorkflowValidationError
+		if errors.As(err, &validationErr) {
+			return invalidWorkflow(c, validationErr.Result)
+		}
 		if errors.Is(err, domain.ErrWorkflowNotFound) {
 			return c.Status(fiber.StatusNotFound).JSON(ErrorResponse{
 				Error:   "not_found",
@@ -400,15 +455,21 @@ func (h *WorkflowHandler) ArchiveWorkflow(c *fiber.Ctx) error {
 
 // RunWorkflow handles executing a workflow
 // @Summary Run workflow
-// @Description Execute a workflow immediately
+// @Description Execute a workflow immediately. The optional JSON body is the run's input payload; trigger nodes output it and expressions read it as $run.input. With wait=true the request blocks until the run finishes or the timeout expires, and returns the run with its output.
 // @Tags Workflows
+// @Accept json
 // @Produce json
 // @Security BearerAuth
 // @Param id path string true "Workflow ID (UUID)"
+// @Param payload body object false "Run input payload"
+// @Param wait query bool false "Wait for the run to finish and return its result"
+// @Param timeout query int false "Seconds to wait in synchronous mode (default 30, max 300)"
 // @Success 200 {object} domain.WorkflowRunResponse
+// @Success 202 {object} domain.WorkflowRunResponse "Still running when the timeout expired"
 // @Failure 400 {object} ErrorResponse
 // @Failure 401 {object} ErrorResponse
 // @Failure 404 {object} ErrorResponse
+// @Failure 422 {object} ValidationErrorResponse
 // @Failure 500 {object} ErrorResponse
 // @Router /workflows/{id}/run [post]
 func (h *WorkflowHandler) RunWorkflow(c *fiber.Ctx) error {
@@ -423,8 +484,96 @@ func (h *WorkflowHandler) RunWorkflow(c *fiber.Ctx) error {
 		})
 	}
 
-	// 1. Check access
-	_, err = h.service.GetWorkflow(c.Context(), workflowID, userID)
+	var input map[string]interface{}
+	if len(c.Body()) > 0 {
+		if err := json.Unmarshal(c.Body(), &input); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
+				Error:   "invalid_request",
+				Message: "Run input must be a JSON object",
+			})
+		}
+	}
+
+	return h.startRun(c, workflowID, userID, func(ctx context.Context) (*domain.WorkflowRunResponse, error) {
+		return h.runService.StartWorkflowRun(ctx, workflowID, input)
+	})
+}
+
+// RerunWorkflowRun handles starting a workflow again with the input of a past run
+// @Summary Rerun workflow run
+// @Description Start a new run of the workflow with the input payload of an earlier run. With from_node, the new run continues from that node: nodes it does not lead to are restored from the outputs of the earlier run instead of executed again.
+// @Tags Workflow Runs
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Workflow Run ID (UUID)"
+// @Param from_node query string false "Node ID (UUID) to continue from"
+// @Param wait query bool false "Wait for the run to finish and return its result"
+// @Param timeout query int false "Seconds to wait in synchronous mode (default 30, max 300)"
+// @Success 200 {object} domain.WorkflowRunResponse
+// @Success 202 {object} domain.WorkflowRunResponse "Still running when the timeout expired"
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 422 {object} ValidationErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /workflow-runs/{id}/rerun [post]
+func (h *WorkflowHandler) RerunWorkflowRun(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uuid.UUID)
+
+	idParam := c.Params("id")
+	runID, err := uuid.Parse(idParam)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
+			Error:   "invalid_id",
+			Message: "Invalid workflow run ID",
+		})
+	}
+
+	var fromNodeID *uuid.UUID
+	if fromNode := c.Query("from_node"); fromNode != "" {
+		id, err := uuid.Parse(fromNode)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
+				Error:   "invalid_request",
+				Message: "Invalid from_node ID",
+			})
+		}
+		fromNodeID = &id
+	}
+
+	run, err := h.runService.GetWorkflowRun(c.Context(), runID)
+	if err != nil {
+		if errors.Is(err, domain.ErrWorkflowRunNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(ErrorResponse{
+				Error:   "not_found",
+				Message: "Workflow run not found",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+			Error:   "internal_error",
+			Message: "Failed to retrieve workflow run",
+		})
+	}
+
+	return h.startRun(c, run.WorkflowID, userID, func(ctx context.Context) (*domain.WorkflowRunResponse, error) {
+		return h.executionService.CreateRerun(ctx, run.ID, fromNodeID)
+	})
+}
+
+// Synchronous runs wait for defaultSyncRunTimeout unless the request asks for
+// another timeout, which is capped at maxSyncRunTimeout.
+const (
+	defaultSyncRunTimeout = 30 * time.Second
+	maxSyncRunTimeout     = 5 * time.Minute
+)
+
+// startRun validates a workflow, creates a run with createRun and queues it
+// for a worker. The response is sent while the run is in progress, unless the
+// wait query parameter asks for its result.
+func (h *WorkflowHandler) startRun(c *fiber.Ctx, workflowID, userID uuid.UUID, createRun func(ctx context.Context) (*domain.WorkflowRunResponse, error)) error {
+	// 1. Check access and validate the graph
+	validation, err := h.service.ValidateWorkflow(c.Context(), workflowID, userID)
 	if err != nil {
 		if errors.Is(err, domain.ErrWorkflowNotFound) {
 			return c.Status(fiber.StatusNotFound).JSON(ErrorResponse{
@@ -443,98 +592,121 @@ func (h *WorkflowHandler) RunWorkflow(c *fiber.Ctx) error {
 			Message: "Failed to check workflow access",
 		})
 	}
+	if !validation.Valid {
+		return invalidWorkflow(c, validation)
+	}
 
 	// 2. Create Run
-	runResponse, err := h.runService.StartWorkflowRun(c.Context(), workflowID)
+	runResponse, err := createRun(c.Context())
 	if err != nil {
+		if errors.Is(err, domain.ErrInvalidRerunNode) {
+			return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
+				Error:   "invalid_from_node",
+				Message: err.Error(),
+			})
+		}
+		if errors.Is(err, domain.ErrWorkflowRunNotFinished) {
+			return c.Status(fiber.StatusConflict).JSON(ErrorResponse{
+				Error:   "run_not_finished",
+				Message: "Only finished runs can be rerun from a node",
+			})
+		}
 		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
 			Error:   "internal_error",
 			Message: "Failed to create workflow run",
 		})
 	}
 
-	// 3. Fetch Nodes and Edges
-	// We need domain structs, but services return Response structs.
-	// We might need to use Repositories directly if Services only return Responses,
-	// OR map Responses back to Domain models.
-	// Looking at the code, Services return *Response.
-	// The Engine needs domain.WorkflowNode and domain.WorkflowEdge.
-	// Let's see if we can map them or if we should use Repositories.
-	// Using Repositories in Handler is generally discouraged if Service layer exists,
-	// but for the Engine execution which is internal logic, it might be acceptable.
-	// However, `WorkflowHandler` now has `nodeService` and `edgeService`.
-	// Let's assume we can map them or the service has a method to get domain models (unlikely based on standard patterns).
-	// Actually, `WorkflowEngine` expects `[]domain.WorkflowNode`.
-	// The `WorkflowNodeResponse` is very similar to `WorkflowNode`.
-	// Let's implement a mapper here or fetch via repository if we had access.
-	// Since we injected Services, let's use them and map.
-
-	nodeResponses, err := h.nodeService.GetWorkflowNodesByWorkflowID(c.Context(), workflowID)
-	if err != nil {
+	// 3. Queue it for a worker
+	if err := h.executionService.StartRun(c.Context(), runResponse.ID); err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
 			Error:   "internal_error",
-			Message: "Failed to fetch workflow nodes",
+			Message: "Failed to start workflow run",
 		})
 	}
 
-	edgeResponses, err := h.edgeService.GetWorkflowEdgesByWorkflowID(c.Context(), workflowID)
+	if !c.QueryBool("wait") {
+		return c.JSON(runResponse)
+	}
+
+	// 4. In synchronous mode, wait for the result. The run keeps going in
+	// the background when the timeout expires.
+	timeout := defaultSyncRunTimeout
+	if seconds := c.QueryInt("timeout"); seconds > 0 {
+		timeout = min(time.Duration(seconds)*time.Second, maxSyncRunTimeout)
+	}
+
+	ctx, cancel := context.WithTimeout(c.Context(), timeout)
+	defer cancel()
+
+	run, finished, err := h.runService.WaitForRun(ctx, runResponse.ID)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
 			Error:   "internal_error",
-			Message: "Failed to fetch workflow edges",
+			Message: "Failed to wait for workflow run",
 		})
 	}
+	if !finished {
+		return c.Status(fiber.StatusAccepted).JSON(run)
+	}
+
+	return c.JSON(run)
+}
+
+// ValidateWorkflow handles validating a workflow graph
+// @Summary Validate workflow
+// @Description Run the static checks on a workflow graph and list the errors and warnings per node
+// @Tags Workflows
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Workflow ID (UUID)"
+// @Success 200 {object} domain.WorkflowValidationResult
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /workflows/{id}/validate [post]
+func (h *WorkflowHandler) ValidateWorkflow(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uuid.UUID)
 
-	// Map to Domain Models
-	var nodes []domain.WorkflowNode
-	for _, nr := range nodeResponses {
-		nodes = append(nodes, domain.WorkflowNode{
-			ID:         nr.ID,
-			WorkflowID: nr.WorkflowID,
-			TemplateID: nr.TemplateID,
-			PositionX:  nr.PositionX,
-			PositionY:  nr.PositionY,
-			Data:       nr.Data,
+	idParam := c.Params("id")
+	id, err := uuid.Parse(idParam)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
+			Error:   "invalid_id",
+			Message: "Invalid workflow ID format",
 		})
 	}
 
-	var edges []domain.WorkflowEdge
-	for _, er := range edgeResponses {
-		edges = append(edges, domain.WorkflowEdge{
-			ID:           er.ID,
-			WorkflowID:   er.WorkflowID,
-			SourceNodeID: er.SourceNodeID,
-			TargetNodeID: er.TargetNodeID,
-			SourceHandle: er.SourceHandle,
-			TargetHandle: er.TargetHandle,
+	validation, err := h.service.ValidateWorkflow(c.Context(), id, userID)
+	if err != nil {
+		if errors.Is(err, domain.ErrWorkflowNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(ErrorResponse{
+				Error:   "not_found",
+				Message: "Workflow not found",
+			})
+		}
+		if errors.Is(err, domain.ErrUnauthorized) {
+			return c.Status(fiber.StatusForbidden).JSON(ErrorResponse{
+				Error:   "forbidden",
+				Message: "You don't have access to this workflow",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+			Error:   "internal_error",
+			Message: "Failed to validate workflow",
 		})
 	}
 
-	// 4. Initialize and Run Engine
-	// Note: We are running this synchronously for now as requested/implied.
-	// In production, this should likely be a background job (goroutine or worker queue).
-	eng := engine.NewWorkflowEngine(
-		nodes,
-		edges,
-		runResponse.ID,
-		workflowID,
-		h.logRepo,
-		h.runRepo,
-	)
-
-	// Run in a goroutine to not block the response, OR run sync?
-	// "Run'ları oluşturmalı... Node'leri ... çalıştırmalı"
-	// If we run sync, the user waits. If async, we return "Running".
-	// Let's run Async so the API returns quickly.
-	go func() {
-		// Create a new context for the background execution
-		// because c.Context() will be cancelled when request ends.
-		bgCtx := context.Background()
-		if err := eng.Execute(bgCtx); err != nil {
-			// Log error (we don't have a logger injected here, maybe fmt.Println for now)
-			// The engine logs to DB, so we are good.
-		}
-	}()
+	return c.JSON(validation)
+}
 
-	return c.JSON(runResponse)
+// invalidWorkflow responds with the validation errors of a workflow graph
+func invalidWorkflow(c *fiber.Ctx, validation *domain.WorkflowValidationResult) error {
+	return c.Status(fiber.StatusUnprocessableEntity).JSON(ValidationErrorResponse{
+		Error:      "invalid_workflow",
+		Message:    "Workflow graph has errors",
+		Validation: validation,
+	})
 }
@@ -1,19 +1,23 @@
 package handler
 
 import (
+	"errors"
+
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
 	"github.com/mr-isik/loki-backend/internal/domain"
 )
 
 type WorkflowNodeHandler struct {
-	service domain.WorkflowNodeService
+	service     domain.WorkflowNodeService
+	testService domain.NodeTestService
 }
 
 // NewWorkflowNodeHandler creates a new workflow node handler
-func NewWorkflowNodeHandler(service domain.WorkflowNodeService) *WorkflowNodeHandler {
+func NewWorkflowNodeHandler(service domain.WorkflowNodeService, testService domain.NodeTestService) *WorkflowNodeHandler {
 	return &WorkflowNodeHandler{
-		service: service,
+		service:     service,
+		testService: testService,
 	}
 }
 
@@ -195,3 +199,101 @@ func (h *WorkflowNodeHandler) GetWorkflowNodes(c *fiber.Ctx) error {
 		"count": len(workflowNodes),
 	})
 }
+
+// TestWorkflowNode handles executing a single node with supplied input
+// @Summary Test workflow node
+// @Description Execute a node with its saved data and the given input, without running the workflow. The outcome is stored as a test run, apart from workflow runs.
+// @Tags Workflow Nodes
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Workflow Node ID (UUID)"
+// @Param request body domain.TestNodeRequest false "Node input, keyed by input handle"
+// @Success 200 {object} domain.NodeTestRunResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /workflow-nodes/{id}/test [post]
+func (h *WorkflowNodeHandler) TestWorkflowNode(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uuid.UUID)
+
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
+			Error:   "invalid_request",
+			Message: "Invalid workflow node ID",
+		})
+	}
+
+	var req domain.TestNodeRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
+				Error:   "invalid_request",
+				Message: "Invalid request body",
+			})
+		}
+	}
+
+	run, err := h.testService.TestNode(c.Context(), id, userID, &req)
+	if err != nil {
+		return nodeTestError(c, err, "Failed to test workflow node")
+	}
+
+	return c.JSON(run)
+}
+
+// GetWorkflowNodeTestRuns handles listing the test runs of a node
+// @Summary List node test runs
+// @Description Retrieve the latest test runs of a node, newest first
+// @Tags Workflow Nodes
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Workflow Node ID (UUID)"
+// @Success 200 {array} domain.NodeTestRunResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /workflow-nodes/{id}/tests [get]
+func (h *WorkflowNodeHandler) GetWorkflowNodeTestRuns(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uuid.UUID)
+
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
+			Error:   "invalid_request",
+			Message: "Invalid workflow node ID",
+		})
+	}
+
+	runs, err := h.testService.ListNodeTestRuns(c.Context(), id, userID)
+	if err != nil {
+		return nodeTestError(c, err, "Failed to retrieve node test runs")
+	}
+
+	return c.JSON(runs)
+}
+
+func nodeTestError(c *fiber.Ctx, err error, message string) error {
+	switch {
+	case domain.IsNotFoundError(err), errors.Is(err, domain.ErrWorkflowNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(ErrorResponse{
+			Error:   "not_found",
+			Message: "Workflow node not found",
+		})
+	case errors.Is(err, domain.ErrUnauthorized):
+		return c.Status(fiber.StatusForbidden).JSON(ErrorResponse{
+			Error:   "forbidden",
+			Message: "You don't have access to this workflow",
+		})
+	default:
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+			Error:   "internal_error",
+			Message: message,
+		})
+	}
+}
@@ -43,7 +43,7 @@ func (h *WorkflowRunHandler) StartWorkflowRun(c *fiber.Ctx) error {
 		})
 	}
 
-	run, err := h.service.StartWorkflowRun(c.Context(), workflowID)
+	run, err := h.service.StartWorkflowRun(c.Context(), workflowID, nil)
 	if err != nil {
 		if errors.Is(err, domain.ErrForeignKeyViolation) {
 			return c.Status(fiber.StatusNotFound).JSON(ErrorResponse{
@@ -218,3 +218,94 @@ func (h *WorkflowRunHandler) UpdateWorkflowRunStatus(c *fiber.Ctx) error {
 
 	return c.SendStatus(fiber.StatusNoContent)
 }
+
+// CancelWorkflowRun handles cancelling a running workflow run
+// @Summary Cancel workflow run
+// @Description Stop a pending or running workflow run. Nodes that are still running are cancelled and nodes that have not started are skipped.
+// @Tags Workflow Runs
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Workflow Run ID (UUID)"
+// @Success 202 {object} domain.WorkflowRunResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /workflow-runs/{id}/cancel [post]
+func (h *WorkflowRunHandler) CancelWorkflowRun(c *fiber.Ctx) error {
+	idParam := c.Params("id")
+	id, err := uuid.Parse(idParam)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
+			Error:   "invalid_id",
+			Message: "Invalid workflow run ID",
+		})
+	}
+
+	userID := c.Locals("userID").(uuid.UUID)
+
+	run, err := h.service.CancelWorkflowRun(c.Context(), id, userID)
+	if err != nil {
+		if errors.Is(err, domain.ErrWorkflowRunNotFound) || errors.Is(err, domain.ErrNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(ErrorResponse{
+				Error:   "not_found",
+				Message: "Workflow run not found",
+			})
+		}
+		if errors.Is(err, domain.ErrWorkflowRunFinished) {
+			return c.Status(fiber.StatusConflict).JSON(ErrorResponse{
+				Error:   "run_finished",
+				Message: "Workflow run has already finished",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+			Error:   "internal_error",
+			Message: "Failed to cancel workflow run",
+		})
+	}
+
+	return c.Status(fiber.StatusAccepted).JSON(run)
+}
+
+// ListChildRuns handles listing the sub-workflow runs of a run
+// @Summary List child runs
+// @Description List the runs started by execute_workflow nodes of a workflow run
+// @Tags Workflow Runs
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Workflow Run ID (UUID)"
+// @Success 200 {array} domain.WorkflowRunResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /workflow-runs/{id}/children [get]
+func (h *WorkflowRunHandler) ListChildRuns(c *fiber.Ctx) error {
+	idParam := c.Params("id")
+	id, err := uuid.Parse(idParam)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
+			Error:   "invalid_id",
+			Message: "Invalid workflow run ID",
+		})
+	}
+
+	userID := c.Locals("userID").(uuid.UUID)
+
+	runs, err := h.service.ListChildRuns(c.Context(), id, userID)
+	if err != nil {
+		if errors.Is(err, domain.ErrWorkflowRunNotFound) || errors.Is(err, domain.ErrNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(ErrorResponse{
+				Error:   "not_found",
+				Message: "Workflow run not found",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+			Error:   "internal_error",
+			Message: "Failed to list child runs",
+		})
+	}
+
+	return c.JSON(runs)
+}
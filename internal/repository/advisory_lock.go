@@ -0,0 +1,97 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/mr-isik/loki-backend/internal/domain"
+)
+
+// AdvisoryLock is a leader lock backed by a session-level Postgres advisory
+// lock. The lock lives as long as the connection that took it, so it is
+// released by the database when the instance holding it dies.
+type AdvisoryLock struct {
+	db  *pgxpool.Pool
+	key int64
+
+	mu sync.Mutex
+	// conn is the connection holding the lock, or nil when it is not held.
+	conn *pgxpool.Conn
+}
+
+func NewAdvisoryLock(db *pgxpool.Pool, key int64) domain.LeaderLock {
+	return &AdvisoryLock{db: db, key: key}
+}
+
+func (l *AdvisoryLock) TryAcquire(ctx context.Context) (bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.conn != nil {
+		return true, nil
+	}
+
+	conn, err := l.db.Acquire(ctx)
+	if err != nil {
+		return false, domain.ParseDBError(err)
+	}
+
+	var acquired bool
+	if err := conn.QueryRow(ctx, `SELECT pg_try_advisory_lock($1)`, l.key).Scan(&acquired); err != nil {
+		conn.Release()
+		return false, domain.ParseDBError(err)
+	}
+	if !acquired {
+		conn.Release()
+		return false, nil
+	}
+
+	l.conn = conn
+	return true, nil
+}
+
+func (l *AdvisoryLock) Check(ctx context.Context) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.conn == nil {
+		return errors.New("advisory lock is not held")
+	}
+
+	// The lock is gone with the session, so a broken connection means it
+	// may already be held elsewhere.
+	if _, err := l.conn.Exec(ctx, `SELECT 1`); err != nil {
+		l.discard()
+		return domain.ParseDBError(err)
+	}
+
+	return nil
+}
+
+func (l *AdvisoryLock) Release(ctx context.Context) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.conn == nil {
+		return nil
+	}
+
+	if _, err := l.conn.Exec(ctx, `SELECT pg_advisory_unlock($1)`, l.key); err != nil {
+		l.discard()
+		return domain.ParseDBError(err)
+	}
+
+	l.conn.Release()
+	l.conn = nil
+	return nil
+}
+
+// discard closes the connection holding the lock instead of returning it to
+// the pool, which ends the session and with it the lock.
+func (l *AdvisoryLock) discard() {
+	conn := l.conn.Hijack()
+	conn.Close(context.Background())
+	l.conn = nil
+}
@@ -2,8 +2,10 @@ package repository
 
 import (
 	"context"
+	"encoding/json"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/mr-isik/loki-backend/internal/domain"
 )
@@ -16,65 +18,62 @@ func NewNodeRunLogRepository(db *pgxpool.Pool) domain.NodeRunLogRepository {
 	return &NodeRunLogRepository{db: db}
 }
 
-func (r *NodeRunLogRepository) Create(ctx context.Context, req *domain.CreateNodeRunLogRequest) (*domain.NodeRunLog, error) {
-	query := `
-		INSERT INTO node_run_logs (id, run_id, node_id, status, started_at, created_at, updated_at)
-		VALUES (gen_random_uuid(), $1, $2, $3, NOW(), NOW(), NOW())
-		RETURNING id, run_id, node_id, status, log_output, error_msg, started_at, finished_at, created_at, updated_at
-	`
+// nodeRunLogColumns lists the columns scanned by scanNodeRunLog. Logs are
+// created before their output is known, so the text columns may be NULL.
+const nodeRunLogColumns = `id, run_id, node_id, status, COALESCE(log_output, ''), COALESCE(error_msg, ''), attempts, iteration,
+		pinned, input_data, output_data, started_at, finished_at, created_at, updated_at`
 
+func scanNodeRunLog(row pgx.Row) (*domain.NodeRunLog, error) {
 	var log domain.NodeRunLog
-	err := r.db.QueryRow(ctx, query, req.RunID, req.NodeID, req.Status).Scan(
+	err := row.Scan(
 		&log.ID,
 		&log.RunID,
 		&log.NodeID,
 		&log.Status,
 		&log.LogOutput,
 		&log.ErrorMsg,
+		&log.Attempts,
+		&log.Iteration,
+		&log.Pinned,
+		&log.InputData,
+		&log.OutputData,
 		&log.StartedAt,
 		&log.FinishedAt,
 		&log.CreatedAt,
 		&log.UpdatedAt,
 	)
-
 	if err != nil {
 		return nil, domain.ParseDBError(err)
 	}
-
 	return &log, nil
 }
 
+func (r *NodeRunLogRepository) Create(ctx context.Context, req *domain.CreateNodeRunLogRequest) (*domain.NodeRunLog, error) {
+	query := `
+		INSERT INTO node_run_logs (id, run_id, node_id, status, iteration, pinned, started_at, finished_at, created_at, updated_at)
+		VALUES (
+			gen_random_uuid(), $1, $2, $3, $4, $5, NOW(),
+			CASE WHEN $3 IN ('completed', 'failed', 'skipped', 'timed_out', 'cancelled') THEN NOW() END,
+			NOW(), NOW()
+		)
+		RETURNING ` + nodeRunLogColumns
+
+	return scanNodeRunLog(r.db.QueryRow(ctx, query, req.RunID, req.NodeID, req.Status, req.Iteration, req.Pinned))
+}
+
 func (r *NodeRunLogRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.NodeRunLog, error) {
 	query := `
-		SELECT id, run_id, node_id, status, log_output, error_msg, started_at, finished_at, created_at, updated_at
+		SELECT ` + nodeRunLogColumns + `
 		FROM node_run_logs
 		WHERE id = $1
 	`
 
-	var log domain.NodeRunLog
-	err := r.db.QueryRow(ctx, query, id).Scan(
-		&log.ID,
-		&log.RunID,
-		&log.NodeID,
-		&log.Status,
-		&log.LogOutput,
-		&log.ErrorMsg,
-		&log.StartedAt,
-		&log.FinishedAt,
-		&log.CreatedAt,
-		&log.UpdatedAt,
-	)
-
-	if err != nil {
-		return nil, domain.ParseDBError(err)
-	}
-
-	return &log, nil
+	return scanNodeRunLog(r.db.QueryRow(ctx, query, id))
 }
 
 func (r *NodeRunLogRepository) GetByRunID(ctx context.Context, runID uuid.UUID) ([]*domain.NodeRunLog, error) {
 	query := `
-		SELECT id, run_id, node_id, status, log_output, error_msg, started_at, finished_at, created_at, updated_at
+		SELECT ` + nodeRunLogColumns + `
 		FROM node_run_logs
 		WHERE run_id = $1
 		ORDER BY started_at ASC
@@ -88,22 +87,11 @@ func (r *NodeRunLogRepository) GetByRunID(ctx context.Context, runID uuid.UUID)
 
 	var logs []*domain.NodeRunLog
 	for rows.Next() {
-		var log domain.NodeRunLog
-		if err := rows.Scan(
-			&log.ID,
-			&log.RunID,
-			&log.NodeID,
-			&log.Status,
-			&log.LogOutput,
-			&log.ErrorMsg,
-			&log.StartedAt,
-			&log.FinishedAt,
-			&log.CreatedAt,
-			&log.UpdatedAt,
-		); err != nil {
-			return nil, domain.ParseDBError(err)
+		log, err := scanNodeRunLog(rows)
+		if err != nil {
+			return nil, err
 		}
-		logs = append(logs, &log)
+		logs = append(logs, log)
 	}
 
 	if err := rows.Err(); err != nil {
@@ -121,15 +109,17 @@ func (r *NodeRunLogRepository) Update(ctx context.Context, id uuid.UUID, req *do
 			status = COALESCE(NULLIF($1::text, ''), status::text)::varchar(50),
 			log_output = COALESCE(NULLIF($2, ''), log_output),
 			error_msg = COALESCE(NULLIF($3, ''), error_msg),
+			input_data = COALESCE($5::jsonb, input_data),
+			output_data = COALESCE($6::jsonb, output_data),
 			finished_at = CASE 
-				WHEN $1 IN ('completed', 'failed', 'skipped') AND finished_at IS NULL THEN NOW()
+				WHEN $1 IN ('completed', 'failed', 'skipped', 'timed_out', 'cancelled') AND finished_at IS NULL THEN NOW()
 				ELSE finished_at
 			END,
 			updated_at = NOW()
 		WHERE id = $4
 	`
 
-	result, err := r.db.Exec(ctx, query, req.Status, req.LogOutput, req.ErrorMsg, id)
+	result, err := r.db.Exec(ctx, query, req.Status, req.LogOutput, req.ErrorMsg, id, req.InputData, req.OutputData)
 	if err != nil {
 		return domain.ParseDBError(err)
 	}
@@ -140,3 +130,41 @@ func (r *NodeRunLogRepository) Update(ctx context.Context, id uuid.UUID, req *do
 
 	return nil
 }
+
+func (r *NodeRunLogRepository) AddAttempt(ctx context.Context, id uuid.UUID, attempt *domain.NodeRunAttempt) error {
+	attemptJSON, err := json.Marshal(attempt)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		UPDATE node_run_logs
+		SET attempts = attempts || jsonb_build_array($1::jsonb), updated_at = NOW()
+		WHERE id = $2
+	`
+
+	result, err := r.db.Exec(ctx, query, string(attemptJSON), id)
+	if err != nil {
+		return domain.ParseDBError(err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return domain.ErrNodeRunLogNotFound
+	}
+
+	return nil
+}
+
+func (r *NodeRunLogRepository) CancelUnfinished(ctx context.Context, runID uuid.UUID) error {
+	query := `
+		UPDATE node_run_logs
+		SET status = $1, finished_at = NOW(), updated_at = NOW()
+		WHERE run_id = $2 AND status IN ('pending', 'running')
+	`
+
+	if _, err := r.db.Exec(ctx, query, domain.NodeRunLogStatusCancelled, runID); err != nil {
+		return domain.ParseDBError(err)
+	}
+
+	return nil
+}
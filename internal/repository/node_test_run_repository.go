@@ -0,0 +1,91 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/mr-isik/loki-backend/internal/domain"
+)
+
+type NodeTestRunRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewNodeTestRunRepository(db *pgxpool.Pool) domain.NodeTestRunRepository {
+	return &NodeTestRunRepository{db: db}
+}
+
+func (r *NodeTestRunRepository) Create(ctx context.Context, run *domain.NodeTestRun) error {
+	query := `
+		INSERT INTO node_test_runs (
+			id, node_id, workflow_id, user_id, status, triggered_handle, log_output, error_msg,
+			input_data, output_data, duration_ms, created_at
+		)
+		VALUES (gen_random_uuid(), $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, NOW())
+		RETURNING id, created_at
+	`
+
+	err := r.db.QueryRow(ctx, query,
+		run.NodeID,
+		run.WorkflowID,
+		run.UserID,
+		run.Status,
+		run.TriggeredHandle,
+		run.Log,
+		run.ErrorMsg,
+		run.InputData,
+		run.OutputData,
+		run.DurationMs,
+	).Scan(&run.ID, &run.CreatedAt)
+	if err != nil {
+		return domain.ParseDBError(err)
+	}
+
+	return nil
+}
+
+func (r *NodeTestRunRepository) ListByNodeID(ctx context.Context, nodeID uuid.UUID, limit int) ([]*domain.NodeTestRun, error) {
+	query := `
+		SELECT id, node_id, workflow_id, user_id, status, triggered_handle, log_output, error_msg,
+			input_data, output_data, duration_ms, created_at
+		FROM node_test_runs
+		WHERE node_id = $1
+		ORDER BY created_at DESC
+		LIMIT $2
+	`
+
+	rows, err := r.db.Query(ctx, query, nodeID, limit)
+	if err != nil {
+		return nil, domain.ParseDBError(err)
+	}
+	defer rows.Close()
+
+	runs := []*domain.NodeTestRun{}
+	for rows.Next() {
+		var run domain.NodeTestRun
+		if err := rows.Scan(
+			&run.ID,
+			&run.NodeID,
+			&run.WorkflowID,
+			&run.UserID,
+			&run.Status,
+			&run.TriggeredHandle,
+			&run.Log,
+			&run.ErrorMsg,
+			&run.InputData,
+			&run.OutputData,
+			&run.DurationMs,
+			&run.CreatedAt,
+		); err != nil {
+			return nil, domain.ParseDBError(err)
+		}
+		runs = append(runs, &run)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, domain.ParseDBError(err)
+	}
+
+	return runs, nil
+}
@@ -0,0 +1,46 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/mr-isik/loki-backend/internal/domain"
+)
+
+type WorkflowListenRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewWorkflowListenRepository(db *pgxpool.Pool) domain.WorkflowListenRepository {
+	return &WorkflowListenRepository{db: db}
+}
+
+func (r *WorkflowListenRepository) ListActive(ctx context.Context) ([]*domain.ListenTrigger, error) {
+	query := `
+		SELECT n.id, n.workflow_id, n.data
+		FROM workflow_nodes n
+		JOIN workflows w ON w.id = n.workflow_id
+		WHERE n.data->>'type' = 'db_postgres_listen' AND w.status = 'published'
+	`
+
+	rows, err := r.db.Query(ctx, query)
+	if err != nil {
+		return nil, domain.ParseDBError(err)
+	}
+	defer rows.Close()
+
+	var triggers []*domain.ListenTrigger
+	for rows.Next() {
+		var trigger domain.ListenTrigger
+		if err := rows.Scan(&trigger.NodeID, &trigger.WorkflowID, &trigger.Data); err != nil {
+			return nil, domain.ParseDBError(err)
+		}
+		triggers = append(triggers, &trigger)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, domain.ParseDBError(err)
+	}
+
+	return triggers, nil
+}
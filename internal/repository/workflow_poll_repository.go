@@ -0,0 +1,129 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/mr-isik/loki-backend/internal/domain"
+)
+
+type WorkflowPollRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewWorkflowPollRepository(db *pgxpool.Pool) domain.WorkflowPollRepository {
+	return &WorkflowPollRepository{db: db}
+}
+
+func (r *WorkflowPollRepository) ListActive(ctx context.Context) ([]*domain.PollTrigger, error) {
+	query := `
+		SELECT n.id, n.workflow_id, n.data, s.last_poll_at
+		FROM workflow_nodes n
+		JOIN workflows w ON w.id = n.workflow_id
+		LEFT JOIN workflow_poll_states s ON s.node_id = n.id
+		WHERE n.data->>'type' = 'poll_http' AND w.status = 'published'
+	`
+
+	rows, err := r.db.Query(ctx, query)
+	if err != nil {
+		return nil, domain.ParseDBError(err)
+	}
+	defer rows.Close()
+
+	var triggers []*domain.PollTrigger
+	for rows.Next() {
+		var trigger domain.PollTrigger
+		err := rows.Scan(
+			&trigger.NodeID,
+			&trigger.WorkflowID,
+			&trigger.Data,
+			&trigger.LastPollAt,
+		)
+		if err != nil {
+			return nil, domain.ParseDBError(err)
+		}
+		triggers = append(triggers, &trigger)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, domain.ParseDBError(err)
+	}
+
+	return triggers, nil
+}
+
+func (r *WorkflowPollRepository) MarkSeen(ctx context.Context, nodeID uuid.UUID, workflowID uuid.UUID, keys []string) ([]string, error) {
+	if len(keys) == 0 {
+		return nil, nil
+	}
+
+	// Keys seen before only get their seen_at refreshed; xmax is 0 for the
+	// rows that were inserted.
+	query := `
+		INSERT INTO workflow_poll_seen_items (node_id, workflow_id, item_key, seen_at)
+		SELECT $1, $2, key, NOW() FROM unnest($3::TEXT[]) AS key
+		ON CONFLICT (node_id, item_key) DO UPDATE SET seen_at = NOW()
+		RETURNING item_key, xmax = 0
+	`
+
+	rows, err := r.db.Query(ctx, query, nodeID, workflowID, keys)
+	if err != nil {
+		return nil, domain.ParseDBError(err)
+	}
+	defer rows.Close()
+
+	var unseen []string
+	for rows.Next() {
+		var key string
+		var inserted bool
+		if err := rows.Scan(&key, &inserted); err != nil {
+			return nil, domain.ParseDBError(err)
+		}
+		if inserted {
+			unseen = append(unseen, key)
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, domain.ParseDBError(err)
+	}
+
+	return unseen, nil
+}
+
+func (r *WorkflowPollRepository) UnmarkSeen(ctx context.Context, nodeID uuid.UUID, keys []string) error {
+	query := `DELETE FROM workflow_poll_seen_items WHERE node_id = $1 AND item_key = ANY($2::TEXT[])`
+
+	if _, err := r.db.Exec(ctx, query, nodeID, keys); err != nil {
+		return domain.ParseDBError(err)
+	}
+
+	return nil
+}
+
+func (r *WorkflowPollRepository) SetLastPoll(ctx context.Context, nodeID uuid.UUID, workflowID uuid.UUID, polledAt time.Time) error {
+	query := `
+		INSERT INTO workflow_poll_states (node_id, workflow_id, last_poll_at, updated_at)
+		VALUES ($1, $2, $3, NOW())
+		ON CONFLICT (node_id) DO UPDATE
+		SET last_poll_at = EXCLUDED.last_poll_at, updated_at = NOW()
+	`
+
+	if _, err := r.db.Exec(ctx, query, nodeID, workflowID, polledAt); err != nil {
+		return domain.ParseDBError(err)
+	}
+
+	return nil
+}
+
+func (r *WorkflowPollRepository) DeleteSeenBefore(ctx context.Context, before time.Time) error {
+	query := `DELETE FROM workflow_poll_seen_items WHERE seen_at < $1`
+
+	if _, err := r.db.Exec(ctx, query, before); err != nil {
+		return domain.ParseDBError(err)
+	}
+
+	return nil
+}
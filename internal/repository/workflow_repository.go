@@ -21,8 +21,8 @@ func NewWorkflowRepository(db *pgxpool.Pool) domain.WorkflowRepository {
 // Create creates a new workflow
 func (r *workflowRepository) Create(ctx context.Context, workflow *domain.Workflow) error {
 	query := `
-		INSERT INTO workflows (id, workspace_id, title, status, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6)
+		INSERT INTO workflows (id, workspace_id, title, status, settings, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
 	`
 
 	workflow.ID = uuid.New()
@@ -42,6 +42,7 @@ func (r *workflowRepository) Create(ctx context.Context, workflow *domain.Workfl
 		workflow.WorkspaceID,
 		workflow.Title,
 		workflow.Status,
+		workflow.Settings,
 		workflow.CreatedAt,
 		workflow.UpdatedAt,
 	)
@@ -56,7 +57,7 @@ func (r *workflowRepository) Create(ctx context.Context, workflow *domain.Workfl
 // GetByID retrieves a workflow by ID
 func (r *workflowRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.Workflow, error) {
 	query := `
-		SELECT id, workspace_id, title, status, created_at, updated_at
+		SELECT id, workspace_id, title, status, settings, created_at, updated_at
 		FROM workflows
 		WHERE id = $1
 	`
@@ -67,6 +68,7 @@ func (r *workflowRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain
 		&workflow.WorkspaceID,
 		&workflow.Title,
 		&workflow.Status,
+		&workflow.Settings,
 		&workflow.CreatedAt,
 		&workflow.UpdatedAt,
 	)
@@ -81,7 +83,7 @@ func (r *workflowRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain
 // GetByWorkspaceID retrieves workflows by workspace ID with pagination
 func (r *workflowRepository) GetByWorkspaceID(ctx context.Context, workspaceID uuid.UUID, limit, offset int) ([]*domain.Workflow, error) {
 	query := `
-		SELECT id, workspace_id, title, status, created_at, updated_at
+		SELECT id, workspace_id, title, status, settings, created_at, updated_at
 		FROM workflows
 		WHERE workspace_id = $1
 		ORDER BY updated_at DESC
@@ -102,6 +104,7 @@ func (r *workflowRepository) GetByWorkspaceID(ctx context.Context, workspaceID u
 			&workflow.WorkspaceID,
 			&workflow.Title,
 			&workflow.Status,
+			&workflow.Settings,
 			&workflow.CreatedAt,
 			&workflow.UpdatedAt,
 		)
@@ -121,7 +124,7 @@ func (r *workflowRepository) GetByWorkspaceID(ctx context.Context, workspaceID u
 // GetAll retrieves all workflows with pagination
 func (r *workflowRepository) GetAll(ctx context.Context, limit, offset int) ([]*domain.Workflow, error) {
 	query := `
-		SELECT id, workspace_id, title, status, created_at, updated_at
+		SELECT id, workspace_id, title, status, settings, created_at, updated_at
 		FROM workflows
 		ORDER BY updated_at DESC
 		LIMIT $1 OFFSET $2
@@ -141,6 +144,7 @@ func (r *workflowRepository) GetAll(ctx context.Context, limit, offset int) ([]*
 			&workflow.WorkspaceID,
 			&workflow.Title,
 			&workflow.Status,
+			&workflow.Settings,
 			&workflow.CreatedAt,
 			&workflow.UpdatedAt,
 		)
@@ -161,8 +165,8 @@ func (r *workflowRepository) GetAll(ctx context.Context, limit, offset int) ([]*
 func (r *workflowRepository) Update(ctx context.Context, workflow *domain.Workflow) error {
 	query := `
 		UPDATE workflows
-		SET title = $1, status = $2, updated_at = $3
-		WHERE id = $4
+		SET title = $1, status = $2, settings = $3, updated_at = $4
+		WHERE id = $5
 	`
 
 	workflow.UpdatedAt = time.Now()
@@ -170,6 +174,7 @@ func (r *workflowRepository) Update(ctx context.Context, workflow *domain.Workfl
 	result, err := r.db.Exec(ctx, query,
 		workflow.Title,
 		workflow.Status,
+		workflow.Settings,
 		workflow.UpdatedAt,
 		workflow.ID,
 	)
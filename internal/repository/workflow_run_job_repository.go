@@ -0,0 +1,153 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/mr-isik/loki-backend/internal/domain"
+)
+
+type WorkflowRunJobRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewWorkflowRunJobRepository(db *pgxpool.Pool) domain.WorkflowRunJobRepository {
+	return &WorkflowRunJobRepository{db: db}
+}
+
+func (r *WorkflowRunJobRepository) Enqueue(ctx context.Context, runID uuid.UUID) (*domain.WorkflowRunJob, error) {
+	query := `
+		INSERT INTO workflow_run_jobs (id, run_id, status, created_at, updated_at)
+		VALUES (gen_random_uuid(), $1, $2, NOW(), NOW())
+		RETURNING id, run_id, status, attempts, lease_owner, lease_expires_at, created_at, updated_at
+	`
+
+	job, err := scanRunJob(r.db.QueryRow(ctx, query, runID, domain.WorkflowRunJobStatusQueued))
+	if err != nil {
+		return nil, domain.ParseDBError(err)
+	}
+
+	return job, nil
+}
+
+func (r *WorkflowRunJobRepository) Lease(ctx context.Context, owner string, lease time.Duration) (*domain.WorkflowRunJob, error) {
+	// SKIP LOCKED lets several workers poll the queue without blocking on,
+	// or double-claiming, the same job.
+	query := `
+		UPDATE workflow_run_jobs
+		SET status = $1,
+			attempts = attempts + 1,
+			lease_owner = $2,
+			lease_expires_at = NOW() + make_interval(secs => $3),
+			updated_at = NOW()
+		WHERE id = (
+			SELECT id
+			FROM workflow_run_jobs
+			WHERE status = $4 OR (status = $1 AND lease_expires_at < NOW())
+			ORDER BY created_at
+			LIMIT 1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING id, run_id, status, attempts, lease_owner, lease_expires_at, created_at, updated_at
+	`
+
+	job, err := scanRunJob(r.db.QueryRow(ctx, query,
+		domain.WorkflowRunJobStatusLeased,
+		owner,
+		lease.Seconds(),
+		domain.WorkflowRunJobStatusQueued,
+	))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrNoRunJobAvailable
+		}
+		return nil, domain.ParseDBError(err)
+	}
+
+	return job, nil
+}
+
+func (r *WorkflowRunJobRepository) ExtendLease(ctx context.Context, id uuid.UUID, owner string, lease time.Duration) error {
+	query := `
+		UPDATE workflow_run_jobs
+		SET lease_expires_at = NOW() + make_interval(secs => $1), updated_at = NOW()
+		WHERE id = $2 AND lease_owner = $3 AND status = $4
+	`
+
+	result, err := r.db.Exec(ctx, query, lease.Seconds(), id, owner, domain.WorkflowRunJobStatusLeased)
+	if err != nil {
+		return domain.ParseDBError(err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return domain.ErrRunJobLeaseLost
+	}
+
+	return nil
+}
+
+func (r *WorkflowRunJobRepository) Complete(ctx context.Context, id uuid.UUID, owner string) error {
+	query := `
+		UPDATE workflow_run_jobs
+		SET status = $1, lease_expires_at = NULL, updated_at = NOW()
+		WHERE id = $2 AND lease_owner = $3 AND status = $4
+	`
+
+	result, err := r.db.Exec(ctx, query, domain.WorkflowRunJobStatusCompleted, id, owner, domain.WorkflowRunJobStatusLeased)
+	if err != nil {
+		return domain.ParseDBError(err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return domain.ErrRunJobLeaseLost
+	}
+
+	return nil
+}
+
+func (r *WorkflowRunJobRepository) Fail(ctx context.Context, id uuid.UUID, owner string) error {
+	query := `
+		UPDATE workflow_run_jobs
+		SET status = $1, lease_expires_at = NULL, updated_at = NOW()
+		WHERE id = $2 AND lease_owner = $3 AND status = $4
+	`
+
+	result, err := r.db.Exec(ctx, query, domain.WorkflowRunJobStatusFailed, id, owner, domain.WorkflowRunJobStatusLeased)
+	if err != nil {
+		return domain.ParseDBError(err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return domain.ErrRunJobLeaseLost
+	}
+
+	return nil
+}
+
+func scanRunJob(row pgx.Row) (*domain.WorkflowRunJob, error) {
+	var job domain.WorkflowRunJob
+	var leaseOwner *string
+	err := row.Scan(
+		&job.ID,
+		&job.RunID,
+		&job.Status,
+		&job.Attempts,
+		&leaseOwner,
+		&job.LeaseExpiresAt,
+		&job.CreatedAt,
+		&job.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if leaseOwner != nil {
+		job.LeaseOwner = *leaseOwner
+	}
+
+	return &job, nil
+}
@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/mr-isik/loki-backend/internal/domain"
 )
@@ -17,15 +18,13 @@ func NewWorkflowRunRepository(db *pgxpool.Pool) domain.WorkflowRunRepository {
 	return &WorkflowRunRepository{db: db}
 }
 
-func (r *WorkflowRunRepository) Create(ctx context.Context, workflowID uuid.UUID) (*domain.WorkflowRun, error) {
-	query := `
-		INSERT INTO workflow_runs (id, workflow_id, status, started_at, created_at, updated_at)
-		VALUES (gen_random_uuid(), $1, $2, NOW(), NOW(), NOW())
-		RETURNING id, workflow_id, status, started_at, finished_at, created_at, updated_at
-	`
+// workflowRunColumns lists the columns scanned by scanWorkflowRun.
+const workflowRunColumns = `id, workflow_id, status, started_at, finished_at, created_at, updated_at, cancel_requested_at,
+		parent_run_id, parent_node_id, depth, input_data, output_data, source_run_id, from_node_id`
 
+func scanWorkflowRun(row pgx.Row) (*domain.WorkflowRun, error) {
 	var run domain.WorkflowRun
-	err := r.db.QueryRow(ctx, query, workflowID, domain.WorkflowRunStatusRunning).Scan(
+	err := row.Scan(
 		&run.ID,
 		&run.WorkflowID,
 		&run.Status,
@@ -33,38 +32,50 @@ func (r *WorkflowRunRepository) Create(ctx context.Context, workflowID uuid.UUID
 		&run.FinishedAt,
 		&run.CreatedAt,
 		&run.UpdatedAt,
+		&run.CancelRequestedAt,
+		&run.ParentRunID,
+		&run.ParentNodeID,
+		&run.Depth,
+		&run.InputData,
+		&run.OutputData,
+		&run.SourceRunID,
+		&run.FromNodeID,
 	)
-
 	if err != nil {
 		return nil, domain.ParseDBError(err)
 	}
-
 	return &run, nil
 }
 
+func (r *WorkflowRunRepository) Create(ctx context.Context, req *domain.CreateWorkflowRunRequest) (*domain.WorkflowRun, error) {
+	query := `
+		INSERT INTO workflow_runs (
+			id, workflow_id, status, parent_run_id, parent_node_id, depth, input_data, source_run_id, from_node_id,
+			started_at, created_at, updated_at
+		)
+		VALUES (gen_random_uuid(), $1, $2, $3, $4, $5, $6, $7, $8, NOW(), NOW(), NOW())
+		RETURNING ` + workflowRunColumns
+
+	return scanWorkflowRun(r.db.QueryRow(ctx, query,
+		req.WorkflowID,
+		domain.WorkflowRunStatusPending,
+		req.ParentRunID,
+		req.ParentNodeID,
+		req.Depth,
+		req.InputData,
+		req.SourceRunID,
+		req.FromNodeID,
+	))
+}
+
 func (r *WorkflowRunRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.WorkflowRun, error) {
 	query := `
-		SELECT id, workflow_id, status, started_at, finished_at, created_at, updated_at
+		SELECT ` + workflowRunColumns + `
 		FROM workflow_runs
 		WHERE id = $1
 	`
 
-	var run domain.WorkflowRun
-	err := r.db.QueryRow(ctx, query, id).Scan(
-		&run.ID,
-		&run.WorkflowID,
-		&run.Status,
-		&run.StartedAt,
-		&run.FinishedAt,
-		&run.CreatedAt,
-		&run.UpdatedAt,
-	)
-
-	if err != nil {
-		return nil, domain.ParseDBError(err)
-	}
-
-	return &run, nil
+	return scanWorkflowRun(r.db.QueryRow(ctx, query, id))
 }
 
 func (r *WorkflowRunRepository) UpdateStatus(ctx context.Context, id uuid.UUID, status domain.WorkflowRunStatus, finishedAt *time.Time) error {
@@ -96,7 +107,7 @@ func (r *WorkflowRunRepository) ListByWorkflowID(ctx context.Context, workflowID
 
 	// Get paginated results
 	query := `
-		SELECT id, workflow_id, status, started_at, finished_at, created_at, updated_at
+		SELECT ` + workflowRunColumns + `
 		FROM workflow_runs
 		WHERE workflow_id = $1
 		ORDER BY started_at DESC
@@ -111,19 +122,11 @@ func (r *WorkflowRunRepository) ListByWorkflowID(ctx context.Context, workflowID
 
 	var runs []*domain.WorkflowRun
 	for rows.Next() {
-		var run domain.WorkflowRun
-		if err := rows.Scan(
-			&run.ID,
-			&run.WorkflowID,
-			&run.Status,
-			&run.StartedAt,
-			&run.FinishedAt,
-			&run.CreatedAt,
-			&run.UpdatedAt,
-		); err != nil {
-			return nil, 0, domain.ParseDBError(err)
+		run, err := scanWorkflowRun(rows)
+		if err != nil {
+			return nil, 0, err
 		}
-		runs = append(runs, &run)
+		runs = append(runs, run)
 	}
 
 	if err := rows.Err(); err != nil {
@@ -132,3 +135,104 @@ func (r *WorkflowRunRepository) ListByWorkflowID(ctx context.Context, workflowID
 
 	return runs, total, nil
 }
+
+func (r *WorkflowRunRepository) ListByParentRunID(ctx context.Context, parentRunID uuid.UUID) ([]*domain.WorkflowRun, error) {
+	query := `
+		SELECT ` + workflowRunColumns + `
+		FROM workflow_runs
+		WHERE parent_run_id = $1
+		ORDER BY started_at ASC
+	`
+
+	rows, err := r.db.Query(ctx, query, parentRunID)
+	if err != nil {
+		return nil, domain.ParseDBError(err)
+	}
+	defer rows.Close()
+
+	var runs []*domain.WorkflowRun
+	for rows.Next() {
+		run, err := scanWorkflowRun(rows)
+		if err != nil {
+			return nil, err
+		}
+		runs = append(runs, run)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, domain.ParseDBError(err)
+	}
+
+	return runs, nil
+}
+
+func (r *WorkflowRunRepository) SetOutput(ctx context.Context, id uuid.UUID, output map[string]interface{}) error {
+	query := `
+		UPDATE workflow_runs
+		SET output_data = $1, updated_at = NOW()
+		WHERE id = $2
+	`
+
+	result, err := r.db.Exec(ctx, query, output, id)
+	if err != nil {
+		return domain.ParseDBError(err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return domain.ErrWorkflowRunNotFound
+	}
+
+	return nil
+}
+
+func (r *WorkflowRunRepository) RequestCancel(ctx context.Context, id uuid.UUID) error {
+	query := `
+		UPDATE workflow_runs
+		SET cancel_requested_at = COALESCE(cancel_requested_at, NOW()), updated_at = NOW()
+		WHERE id = $1 AND status IN ('pending', 'running')
+	`
+
+	result, err := r.db.Exec(ctx, query, id)
+	if err != nil {
+		return domain.ParseDBError(err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return domain.ErrWorkflowRunFinished
+	}
+
+	return nil
+}
+
+func (r *WorkflowRunRepository) ListCancelRequested(ctx context.Context, ids []uuid.UUID) ([]uuid.UUID, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	query := `
+		SELECT id
+		FROM workflow_runs
+		WHERE id = ANY($1) AND cancel_requested_at IS NOT NULL
+	`
+
+	rows, err := r.db.Query(ctx, query, ids)
+	if err != nil {
+		return nil, domain.ParseDBError(err)
+	}
+	defer rows.Close()
+
+	var cancelled []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, domain.ParseDBError(err)
+		}
+		cancelled = append(cancelled, id)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, domain.ParseDBError(err)
+	}
+
+	return cancelled, nil
+}
@@ -0,0 +1,78 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/mr-isik/loki-backend/internal/domain"
+)
+
+type WorkflowRunStateRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewWorkflowRunStateRepository(db *pgxpool.Pool) domain.WorkflowRunStateRepository {
+	return &WorkflowRunStateRepository{db: db}
+}
+
+func (r *WorkflowRunStateRepository) Save(ctx context.Context, state *domain.WorkflowRunNodeState) error {
+	query := `
+		INSERT INTO workflow_run_node_states (run_id, node_id, status, triggered_handle, output, updated_at)
+		VALUES ($1, $2, $3, $4, $5, NOW())
+		ON CONFLICT (run_id, node_id) DO UPDATE
+		SET status = EXCLUDED.status,
+			triggered_handle = EXCLUDED.triggered_handle,
+			output = EXCLUDED.output,
+			updated_at = NOW()
+	`
+
+	_, err := r.db.Exec(ctx, query,
+		state.RunID,
+		state.NodeID,
+		state.Status,
+		state.TriggeredHandle,
+		state.Output,
+	)
+	if err != nil {
+		return domain.ParseDBError(err)
+	}
+
+	return nil
+}
+
+func (r *WorkflowRunStateRepository) GetByRunID(ctx context.Context, runID uuid.UUID) ([]*domain.WorkflowRunNodeState, error) {
+	query := `
+		SELECT run_id, node_id, status, triggered_handle, output, updated_at
+		FROM workflow_run_node_states
+		WHERE run_id = $1
+	`
+
+	rows, err := r.db.Query(ctx, query, runID)
+	if err != nil {
+		return nil, domain.ParseDBError(err)
+	}
+	defer rows.Close()
+
+	var states []*domain.WorkflowRunNodeState
+	for rows.Next() {
+		var state domain.WorkflowRunNodeState
+		if err := rows.Scan(
+			&state.RunID,
+			&state.NodeID,
+			&state.Status,
+			&state.TriggeredHandle,
+			&state.Output,
+			&state.UpdatedAt,
+		); err != nil {
+			return nil, domain.ParseDBError(err)
+		}
+		states = append(states, &state)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, domain.ParseDBError(err)
+	}
+
+	return states, nil
+}
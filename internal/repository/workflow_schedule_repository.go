@@ -0,0 +1,105 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/mr-isik/loki-backend/internal/domain"
+)
+
+type WorkflowScheduleRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewWorkflowScheduleRepository(db *pgxpool.Pool) domain.WorkflowScheduleRepository {
+	return &WorkflowScheduleRepository{db: db}
+}
+
+// cronTriggerQuery selects the cron nodes of workflows with the state of
+// their schedules. Callers append the WHERE conditions.
+const cronTriggerQuery = `
+	SELECT n.id, n.workflow_id, w.status, n.data, s.last_fire_at
+	FROM workflow_nodes n
+	JOIN workflows w ON w.id = n.workflow_id
+	LEFT JOIN workflow_schedules s ON s.node_id = n.id
+	WHERE n.data->>'type' = 'cron'`
+
+func (r *WorkflowScheduleRepository) ListActive(ctx context.Context) ([]*domain.CronTrigger, error) {
+	return r.list(ctx, cronTriggerQuery+` AND w.status = 'published'`)
+}
+
+func (r *WorkflowScheduleRepository) ListByWorkflowID(ctx context.Context, workflowID uuid.UUID) ([]*domain.CronTrigger, error) {
+	return r.list(ctx, cronTriggerQuery+` AND n.workflow_id = $1 ORDER BY n.created_at`, workflowID)
+}
+
+func (r *WorkflowScheduleRepository) list(ctx context.Context, query string, args ...any) ([]*domain.CronTrigger, error) {
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, domain.ParseDBError(err)
+	}
+	defer rows.Close()
+
+	var triggers []*domain.CronTrigger
+	for rows.Next() {
+		var trigger domain.CronTrigger
+		err := rows.Scan(
+			&trigger.NodeID,
+			&trigger.WorkflowID,
+			&trigger.WorkflowStatus,
+			&trigger.Data,
+			&trigger.LastFireAt,
+		)
+		if err != nil {
+			return nil, domain.ParseDBError(err)
+		}
+		triggers = append(triggers, &trigger)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, domain.ParseDBError(err)
+	}
+
+	return triggers, nil
+}
+
+func (r *WorkflowScheduleRepository) SetLastFire(ctx context.Context, nodeID uuid.UUID, workflowID uuid.UUID, firedAt time.Time) error {
+	query := `
+		INSERT INTO workflow_schedules (node_id, workflow_id, last_fire_at, updated_at)
+		VALUES ($1, $2, $3, NOW())
+		ON CONFLICT (node_id) DO UPDATE
+		SET last_fire_at = EXCLUDED.last_fire_at, updated_at = NOW()
+	`
+
+	if _, err := r.db.Exec(ctx, query, nodeID, workflowID, firedAt); err != nil {
+		return domain.ParseDBError(err)
+	}
+
+	return nil
+}
+
+func (r *WorkflowScheduleRepository) ClaimFire(ctx context.Context, nodeID uuid.UUID, workflowID uuid.UUID, fireTime time.Time) (bool, error) {
+	query := `
+		INSERT INTO workflow_schedule_fires (workflow_id, node_id, fire_time)
+		VALUES ($1, $2, $3)
+		ON CONFLICT DO NOTHING
+	`
+
+	tag, err := r.db.Exec(ctx, query, workflowID, nodeID, fireTime)
+	if err != nil {
+		return false, domain.ParseDBError(err)
+	}
+
+	return tag.RowsAffected() == 1, nil
+}
+
+func (r *WorkflowScheduleRepository) DeleteFiresBefore(ctx context.Context, before time.Time) error {
+	query := `DELETE FROM workflow_schedule_fires WHERE fire_time < $1`
+
+	if _, err := r.db.Exec(ctx, query, before); err != nil {
+		return domain.ParseDBError(err)
+	}
+
+	return nil
+}
@@ -16,7 +16,7 @@ import (
 )
 
 // SetupRoutes configures all application routes
-func SetupRoutes(app *fiber.App, jwtManager *util.JWTManager, authHandler *handler.AuthHandler, userHandler *handler.UserHandler, workspaceHandler *handler.WorkspaceHandler, workflowHandler *handler.WorkflowHandler, workflowEdgeHandler *handler.WorkflowEdgeHandler, workflowNodeHandler *handler.WorkflowNodeHandler, nodeTemplateHandler *handler.NodeTemplateHandler, workflowRunHandler *handler.WorkflowRunHandler, nodeRunLogHandler *handler.NodeRunLogHandler) {
+func SetupRoutes(app *fiber.App, jwtManager *util.JWTManager, authHandler *handler.AuthHandler, userHandler *handler.UserHandler, workspaceHandler *handler.WorkspaceHandler, workflowHandler *handler.WorkflowHandler, workflowEdgeHandler *handler.WorkflowEdgeHandler, workflowNodeHandler *handler.WorkflowNodeHandler, nodeTemplateHandler *handler.NodeTemplateHandler, workflowRunHandler *handler.WorkflowRunHandler, nodeRunLogHandler *handler.NodeRunLogHandler, webhookHandler *handler.WebhookHandler) {
 	// Middleware
 	app.Use(recover.New())
 	app.Use(logger.New(logger.Config{
@@ -50,6 +50,9 @@ func SetupRoutes(app *fiber.App, jwtManager *util.JWTManager, authHandler *handl
 	auth.Post("/refresh-token", authHandler.RefreshToken)
 	auth.Get("/me", middleware.AuthMiddleware(jwtManager), authHandler.GetMe)
 
+	// Webhook routes (public, verified by the webhook node's own settings)
+	app.All("/hooks/:workflow_id/*", webhookHandler.HandleWebhook)
+
 	// Create auth middleware
 	authMiddleware := middleware.AuthMiddleware(jwtManager)
 
@@ -80,6 +83,8 @@ func SetupRoutes(app *fiber.App, jwtManager *util.JWTManager, authHandler *handl
 	workflows.Post("/:id/publish", workflowHandler.PublishWorkflow)
 	workflows.Post("/:id/archive", workflowHandler.ArchiveWorkflow)
 	workflows.Post("/:id/run", workflowHandler.RunWorkflow)
+	workflows.Post("/:id/validate", workflowHandler.ValidateWorkflow)
+	workflows.Get("/:id/schedule", workflowHandler.GetWorkflowSchedule)
 	workflows.Get("/:workflow_id/edges", workflowEdgeHandler.GetWorkflowEdgesByWorkflow)
 	workflows.Get("/:workflow_id/nodes", workflowNodeHandler.GetWorkflowNodes)
 	workflows.Post("/:workflow_id/runs", workflowRunHandler.StartWorkflowRun)
@@ -98,11 +103,16 @@ func SetupRoutes(app *fiber.App, jwtManager *util.JWTManager, authHandler *handl
 	nodes.Get("/:id", workflowNodeHandler.GetWorkflowNode)
 	nodes.Put("/:id", workflowNodeHandler.UpdateWorkflowNode)
 	nodes.Delete("/:id", workflowNodeHandler.DeleteWorkflowNode)
+	nodes.Post("/:id/test", workflowNodeHandler.TestWorkflowNode)
+	nodes.Get("/:id/tests", workflowNodeHandler.GetWorkflowNodeTestRuns)
 
 	// Workflow Run routes (protected)
 	workflowRuns := app.Group("/workflow-runs", authMiddleware)
 	workflowRuns.Get("/:id", workflowRunHandler.GetWorkflowRun)
 	workflowRuns.Patch("/:id/status", workflowRunHandler.UpdateWorkflowRunStatus)
+	workflowRuns.Post("/:id/cancel", workflowRunHandler.CancelWorkflowRun)
+	workflowRuns.Post("/:id/rerun", workflowHandler.RerunWorkflowRun)
+	workflowRuns.Get("/:id/children", workflowRunHandler.ListChildRuns)
 	workflowRuns.Get("/:run_id/logs", nodeRunLogHandler.GetNodeRunLogsByRunID)
 
 	// Node Run Log routes (protected)
@@ -8,12 +8,16 @@ import (
 )
 
 type nodeRunLogService struct {
-	repo domain.NodeRunLogRepository
+	repo            domain.NodeRunLogRepository
+	runRepo         domain.WorkflowRunRepository
+	workflowService domain.WorkflowService
 }
 
-func NewNodeRunLogService(repo domain.NodeRunLogRepository) domain.NodeRunLogService {
+func NewNodeRunLogService(repo domain.NodeRunLogRepository, runRepo domain.WorkflowRunRepository, workflowService domain.WorkflowService) domain.NodeRunLogService {
 	return &nodeRunLogService{
-		repo: repo,
+		repo:            repo,
+		runRepo:         runRepo,
+		workflowService: workflowService,
 	}
 }
 
@@ -35,7 +39,11 @@ func (s *nodeRunLogService) GetNodeRunLog(ctx context.Context, id uuid.UUID) (*d
 	return log.ToResponse(), nil
 }
 
-func (s *nodeRunLogService) GetNodeRunLogsByRunID(ctx context.Context, runID uuid.UUID) ([]*domain.NodeRunLogResponse, error) {
+func (s *nodeRunLogService) GetNodeRunLogsByRunID(ctx context.Context, runID uuid.UUID, userID uuid.UUID) ([]*domain.NodeRunLogResponse, error) {
+	if _, err := accessibleRun(ctx, s.runRepo, s.workflowService, runID, userID); err != nil {
+		return nil, err
+	}
+
 	logs, err := s.repo.GetByRunID(ctx, runID)
 	if err != nil {
 		return nil, err
@@ -0,0 +1,97 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mr-isik/loki-backend/internal/domain"
+	"github.com/mr-isik/loki-backend/internal/engine"
+)
+
+// nodeTestRunLimit is the number of test runs listed per node.
+const nodeTestRunLimit = 20
+
+type nodeTestService struct {
+	nodeRepo        domain.WorkflowNodeRepository
+	testRunRepo     domain.NodeTestRunRepository
+	workflowService domain.WorkflowService
+	engineConfig    engine.Config
+}
+
+func NewNodeTestService(
+	nodeRepo domain.WorkflowNodeRepository,
+	testRunRepo domain.NodeTestRunRepository,
+	workflowService domain.WorkflowService,
+	engineConfig engine.Config,
+) domain.NodeTestService {
+	return &nodeTestService{
+		nodeRepo:        nodeRepo,
+		testRunRepo:     testRunRepo,
+		workflowService: workflowService,
+		engineConfig:    engineConfig,
+	}
+}
+
+// TestNode executes a single node with the given input and stores the outcome as a test run
+func (s *nodeTestService) TestNode(ctx context.Context, nodeID uuid.UUID, userID uuid.UUID, req *domain.TestNodeRequest) (*domain.NodeTestRunResponse, error) {
+	node, err := s.accessibleNode(ctx, nodeID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	startedAt := time.Now()
+	result := engine.ExecuteNode(ctx, s.engineConfig, *node, req.Input)
+
+	run := &domain.NodeTestRun{
+		NodeID:          node.ID,
+		WorkflowID:      node.WorkflowID,
+		UserID:          userID,
+		Status:          result.Status,
+		TriggeredHandle: result.TriggeredHandle,
+		Log:             result.Log,
+		ErrorMsg:        result.ErrorMsg,
+		InputData:       engine.LimitPayload(engine.RedactPayload(req.Input), s.engineConfig.MaxLogPayloadBytes),
+		OutputData:      engine.LimitPayload(engine.RedactPayload(result.OutputData), s.engineConfig.MaxLogPayloadBytes),
+		DurationMs:      time.Since(startedAt).Milliseconds(),
+	}
+	if err := s.testRunRepo.Create(context.WithoutCancel(ctx), run); err != nil {
+		return nil, fmt.Errorf("failed to store node test run: %w", err)
+	}
+
+	return run.ToResponse(), nil
+}
+
+// ListNodeTestRuns returns the latest test runs of a node
+func (s *nodeTestService) ListNodeTestRuns(ctx context.Context, nodeID uuid.UUID, userID uuid.UUID) ([]*domain.NodeTestRunResponse, error) {
+	if _, err := s.accessibleNode(ctx, nodeID, userID); err != nil {
+		return nil, err
+	}
+
+	runs, err := s.testRunRepo.ListByNodeID(ctx, nodeID, nodeTestRunLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]*domain.NodeTestRunResponse, len(runs))
+	for i, run := range runs {
+		responses[i] = run.ToResponse()
+	}
+
+	return responses, nil
+}
+
+// accessibleNode returns a node whose workflow the user can access
+func (s *nodeTestService) accessibleNode(ctx context.Context, nodeID uuid.UUID, userID uuid.UUID) (*domain.WorkflowNode, error) {
+	node, err := s.nodeRepo.GetByID(ctx, nodeID)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := s.workflowService.GetWorkflow(ctx, node.WorkflowID, userID); err != nil {
+		return nil, err
+	}
+
+	return node, nil
+}
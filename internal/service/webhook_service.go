@@ -0,0 +1,103 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/mr-isik/loki-backend/internal/domain"
+	"github.com/mr-isik/loki-backend/internal/trigger"
+)
+
+type webhookService struct {
+	workflowRepo     domain.WorkflowRepository
+	workflowNodeRepo domain.WorkflowNodeRepository
+	runService       domain.WorkflowRunService
+	executionService domain.WorkflowExecutionService
+}
+
+func NewWebhookService(
+	workflowRepo domain.WorkflowRepository,
+	workflowNodeRepo domain.WorkflowNodeRepository,
+	runService domain.WorkflowRunService,
+	executionService domain.WorkflowExecutionService,
+) domain.WebhookService {
+	return &webhookService{
+		workflowRepo:     workflowRepo,
+		workflowNodeRepo: workflowNodeRepo,
+		runService:       runService,
+		executionService: executionService,
+	}
+}
+
+// HandleWebhook starts a run of a published workflow from an inbound request
+func (s *webhookService) HandleWebhook(ctx context.Context, req *domain.WebhookRequest) (*domain.WebhookResult, error) {
+	// Only published workflows receive webhooks; drafts and archived
+	// workflows look the same as unknown ones to the caller.
+	workflow, err := s.workflowRepo.GetByID(ctx, req.WorkflowID)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return nil, domain.ErrWebhookNotFound
+		}
+		return nil, fmt.Errorf("failed to get workflow: %w", err)
+	}
+	if workflow.Status != domain.WorkflowStatusPublished {
+		return nil, domain.ErrWebhookNotFound
+	}
+
+	nodes, err := s.workflowNodeRepo.GetByWorkflowID(ctx, req.WorkflowID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get workflow nodes: %w", err)
+	}
+
+	// Find the webhook node listening on the path and method
+	var node *domain.WorkflowNode
+	var config *trigger.WebhookConfig
+	pathMatched := false
+	for _, n := range nodes {
+		if nodeType, _ := n.Data["type"].(string); nodeType != trigger.WebhookNodeType {
+			continue
+		}
+		c, err := trigger.ParseWebhookConfig(n.Data)
+		if err != nil {
+			log.Printf("⚠️ Webhook node %s of workflow %s is not served: %v", n.ID, n.WorkflowID, err)
+			continue
+		}
+		if !c.MatchesPath(req.Path) {
+			continue
+		}
+		pathMatched = true
+		if c.AllowsMethod(req.Method) {
+			node, config = n, c
+			break
+		}
+	}
+	if node == nil {
+		if pathMatched {
+			return nil, domain.ErrWebhookMethodNotAllowed
+		}
+		return nil, domain.ErrWebhookNotFound
+	}
+
+	if err := config.Verify(req, time.Now()); err != nil {
+		return nil, err
+	}
+
+	input := config.Input(req)
+	input["trigger_node_id"] = node.ID.String()
+
+	run, err := s.runService.StartWorkflowRun(ctx, req.WorkflowID, input)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create run: %w", err)
+	}
+	if err := s.executionService.StartRun(ctx, run.ID); err != nil {
+		return nil, fmt.Errorf("failed to queue run %s: %w", run.ID, err)
+	}
+
+	return &domain.WebhookResult{
+		Run:          run,
+		ResponseMode: config.ResponseMode,
+	}, nil
+}
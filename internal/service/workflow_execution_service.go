@@ -0,0 +1,442 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mr-isik/loki-backend/internal/domain"
+	"github.com/mr-isik/loki-backend/internal/engine"
+)
+
+type workflowExecutionService struct {
+	workflowRepo   domain.WorkflowRepository
+	nodeRepo       domain.WorkflowNodeRepository
+	edgeRepo       domain.WorkflowEdgeRepository
+	runRepo        domain.WorkflowRunRepository
+	logRepo        domain.NodeRunLogRepository
+	stateRepo      domain.WorkflowRunStateRepository
+	jobRepo        domain.WorkflowRunJobRepository
+	engineConfig   engine.Config
+	activeRuns     *engine.ActiveRuns
+	recoveryPolicy domain.RunRecoveryPolicy
+}
+
+// NewWorkflowExecutionService creates a new workflow execution service
+func NewWorkflowExecutionService(
+	workflowRepo domain.WorkflowRepository,
+	nodeRepo domain.WorkflowNodeRepository,
+	edgeRepo domain.WorkflowEdgeRepository,
+	runRepo domain.WorkflowRunRepository,
+	logRepo domain.NodeRunLogRepository,
+	stateRepo domain.WorkflowRunStateRepository,
+	jobRepo domain.WorkflowRunJobRepository,
+	engineConfig engine.Config,
+	activeRuns *engine.ActiveRuns,
+	recoveryPolicy domain.RunRecoveryPolicy,
+) domain.WorkflowExecutionService {
+	return &workflowExecutionService{
+		workflowRepo:   workflowRepo,
+		nodeRepo:       nodeRepo,
+		edgeRepo:       edgeRepo,
+		runRepo:        runRepo,
+		logRepo:        logRepo,
+		stateRepo:      stateRepo,
+		jobRepo:        jobRepo,
+		engineConfig:   engineConfig,
+		activeRuns:     activeRuns,
+		recoveryPolicy: recoveryPolicy,
+	}
+}
+
+// StartRun queues the run; a worker picks it up and executes it
+func (s *workflowExecutionService) StartRun(ctx context.Context, runID uuid.UUID) error {
+	if _, err := s.jobRepo.Enqueue(ctx, runID); err != nil {
+		return fmt.Errorf("failed to queue run: %w", err)
+	}
+	return nil
+}
+
+// ExecuteRun executes a run on the engine and returns once it has finished
+func (s *workflowExecutionService) ExecuteRun(ctx context.Context, runID uuid.UUID, resume bool) error {
+	run, err := s.runRepo.GetByID(ctx, runID)
+	if err != nil {
+		return fmt.Errorf("failed to fetch run: %w", err)
+	}
+
+	switch run.Status {
+	case domain.WorkflowRunStatusPending, domain.WorkflowRunStatusRunning:
+	default:
+		// Finished (e.g. cancelled) while it was waiting in the queue.
+		return nil
+	}
+
+	// Sub-workflow runs this run was waiting on were interrupted with it.
+	var children *waitingChildren
+	if resume {
+		children, err = s.loadWaitingChildren(ctx, runID)
+		if err != nil {
+			s.closeRun(ctx, runID, domain.WorkflowRunStatusFailed)
+			return err
+		}
+		defer s.failUnclaimed(ctx, children)
+	}
+
+	if run.CancelRequestedAt != nil {
+		s.closeRun(ctx, runID, domain.WorkflowRunStatusCancelled)
+		return nil
+	}
+
+	if resume && s.recoveryPolicy == domain.RunRecoveryPolicyFail {
+		log.Printf("run %s was interrupted; failing it as configured", runID)
+		s.closeRun(ctx, runID, domain.WorkflowRunStatusFailed)
+		return nil
+	}
+
+	eng, err := s.newEngine(ctx, runID, run.WorkflowID)
+	if err != nil {
+		s.closeRun(ctx, runID, domain.WorkflowRunStatusFailed)
+		return err
+	}
+	eng.Input = run.InputData
+
+	runCtx, done := s.activeRuns.Track(ctx, runID)
+	defer done()
+
+	if resume {
+		log.Printf("resuming interrupted run %s", runID)
+		err = eng.Resume(context.WithValue(runCtx, waitingChildrenKey{}, children))
+	} else if run.FromNodeID != nil {
+		// A rerun from a node continues from the checkpoints copied from its source run.
+		err = eng.Resume(runCtx)
+	} else {
+		err = eng.Execute(runCtx)
+	}
+	return err
+}
+
+// FailRun fails a run that is still waiting or running
+func (s *workflowExecutionService) FailRun(ctx context.Context, runID uuid.UUID) error {
+	run, err := s.runRepo.GetByID(ctx, runID)
+	if err != nil {
+		return fmt.Errorf("failed to fetch run: %w", err)
+	}
+
+	switch run.Status {
+	case domain.WorkflowRunStatusPending, domain.WorkflowRunStatusRunning:
+	default:
+		return nil
+	}
+
+	s.closeRun(ctx, runID, domain.WorkflowRunStatusFailed)
+
+	children, err := s.loadWaitingChildren(ctx, runID)
+	if err != nil {
+		return err
+	}
+	s.failUnclaimed(ctx, children)
+	return nil
+}
+
+// CreateRerun creates a run of the source run's workflow with the same input.
+// With fromNodeID set, the new run continues from that node: the outputs the
+// source run recorded for the nodes before it are copied as checkpoints.
+func (s *workflowExecutionService) CreateRerun(ctx context.Context, sourceRunID uuid.UUID, fromNodeID *uuid.UUID) (*domain.WorkflowRunResponse, error) {
+	source, err := s.runRepo.GetByID(ctx, sourceRunID)
+	if err != nil {
+		return nil, err
+	}
+
+	var nodes []domain.WorkflowNode
+	var edges []domain.WorkflowEdge
+	var states []*domain.WorkflowRunNodeState
+	if fromNodeID != nil {
+		if source.Status == domain.WorkflowRunStatusPending || source.Status == domain.WorkflowRunStatusRunning {
+			return nil, domain.ErrWorkflowRunNotFinished
+		}
+
+		nodes, edges, err = s.loadGraph(ctx, source.WorkflowID)
+		if err != nil {
+			return nil, err
+		}
+		// Checked up front so that no run is created for an invalid node.
+		if _, err := engine.RerunStates(nodes, edges, nil, *fromNodeID, uuid.Nil); err != nil {
+			return nil, err
+		}
+
+		states, err = s.stateRepo.GetByRunID(ctx, source.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load checkpoints of run %s: %w", source.ID, err)
+		}
+	}
+
+	run, err := s.runRepo.Create(ctx, &domain.CreateWorkflowRunRequest{
+		WorkflowID:  source.WorkflowID,
+		InputData:   source.InputData,
+		SourceRunID: &source.ID,
+		FromNodeID:  fromNodeID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create run: %w", err)
+	}
+
+	if fromNodeID == nil {
+		return run.ToResponse(), nil
+	}
+
+	seeded, err := engine.RerunStates(nodes, edges, states, *fromNodeID, run.ID)
+	if err == nil {
+		for _, state := range seeded {
+			if err = s.stateRepo.Save(ctx, state); err != nil {
+				break
+			}
+		}
+	}
+	if err != nil {
+		s.closeRun(context.WithoutCancel(ctx), run.ID, domain.WorkflowRunStatusFailed)
+		return nil, fmt.Errorf("failed to copy checkpoints of run %s: %w", source.ID, err)
+	}
+
+	return run.ToResponse(), nil
+}
+
+// RunSubWorkflow starts a run of another workflow on behalf of a parent run
+func (s *workflowExecutionService) RunSubWorkflow(ctx context.Context, req *domain.SubWorkflowRequest) (*domain.SubWorkflowResult, error) {
+	parent, err := s.runRepo.GetByID(ctx, req.ParentRunID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch parent run: %w", err)
+	}
+
+	depth := parent.Depth + 1
+	if maxDepth := s.engineConfig.MaxWorkflowDepth; maxDepth > 0 && depth > maxDepth {
+		return nil, fmt.Errorf("%w: limit is %d", domain.ErrWorkflowDepthExceeded, maxDepth)
+	}
+
+	parentWorkflow, err := s.workflowRepo.GetByID(ctx, parent.WorkflowID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch parent workflow: %w", err)
+	}
+
+	// Workflows of other workspaces are reported as missing.
+	workflow, err := s.workflowRepo.GetByID(ctx, req.WorkflowID)
+	if err != nil {
+		return nil, err
+	}
+	if workflow.WorkspaceID != parentWorkflow.WorkspaceID {
+		return nil, domain.ErrWorkflowNotFound
+	}
+
+	// A resumed parent continues the run its node was waiting on when it
+	// was interrupted rather than starting another one.
+	if children, ok := ctx.Value(waitingChildrenKey{}).(*waitingChildren); ok && req.Wait {
+		if child := children.claim(req); child != nil {
+			if child.Status == domain.WorkflowRunStatusCompleted {
+				return s.subWorkflowResult(ctx, child.ID, nil)
+			}
+			log.Printf("reattaching run %s to sub-workflow run %s", parent.ID, child.ID)
+			return s.subWorkflowResult(ctx, child.ID, s.ExecuteRun(ctx, child.ID, true))
+		}
+	}
+
+	createReq := &domain.CreateWorkflowRunRequest{
+		WorkflowID:  workflow.ID,
+		ParentRunID: &parent.ID,
+		Depth:       depth,
+		InputData:   req.Input,
+	}
+	if req.Wait {
+		createReq.ParentNodeID = &req.ParentNodeID
+	}
+
+	run, err := s.runRepo.Create(ctx, createReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create sub-workflow run: %w", err)
+	}
+
+	if !req.Wait {
+		if err := s.StartRun(ctx, run.ID); err != nil {
+			return nil, err
+		}
+		return &domain.SubWorkflowResult{RunID: run.ID, Status: run.Status}, nil
+	}
+
+	// Waiting runs execute in the caller's worker slot, so that a parent can
+	// never wait on a child queued behind it. They have no job of their own;
+	// if the worker dies, they are resumed along with the parent.
+	return s.subWorkflowResult(ctx, run.ID, s.ExecuteRun(ctx, run.ID, false))
+}
+
+// subWorkflowResult reports how a sub-workflow run a parent waited on ended
+func (s *workflowExecutionService) subWorkflowResult(ctx context.Context, runID uuid.UUID, execErr error) (*domain.SubWorkflowResult, error) {
+	finished, err := s.runRepo.GetByID(context.WithoutCancel(ctx), runID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch sub-workflow run: %w", err)
+	}
+
+	result := &domain.SubWorkflowResult{
+		RunID:  finished.ID,
+		Status: finished.Status,
+		Output: finished.OutputData,
+	}
+	if finished.Status != domain.WorkflowRunStatusCompleted {
+		if execErr == nil {
+			execErr = errors.New("run did not complete")
+		}
+		return result, fmt.Errorf("sub-workflow run %s %s: %w", finished.ID, finished.Status, execErr)
+	}
+
+	return result, nil
+}
+
+// waitingChildren are the sub-workflow runs an interrupted run was waiting on.
+// Its resumed execute_workflow nodes claim them back, matched by node and
+// input, so that every child is continued once and none is started twice.
+type waitingChildren struct {
+	parentRunID uuid.UUID
+
+	mu   sync.Mutex
+	runs []*domain.WorkflowRun
+}
+
+type waitingChildrenKey struct{}
+
+// loadWaitingChildren returns the unfinished or completed sub-workflow runs a
+// run was waiting on. Failed and cancelled ones are started again.
+func (s *workflowExecutionService) loadWaitingChildren(ctx context.Context, runID uuid.UUID) (*waitingChildren, error) {
+	runs, err := s.runRepo.ListByParentRunID(ctx, runID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch sub-workflow runs: %w", err)
+	}
+
+	children := &waitingChildren{parentRunID: runID}
+	for _, run := range runs {
+		if run.ParentNodeID == nil {
+			continue
+		}
+		switch run.Status {
+		case domain.WorkflowRunStatusPending, domain.WorkflowRunStatusRunning, domain.WorkflowRunStatusCompleted:
+			children.runs = append(children.runs, run)
+		}
+	}
+	return children, nil
+}
+
+// claim removes and returns the child started by the request's node with the
+// same input, if there is one.
+func (c *waitingChildren) claim(req *domain.SubWorkflowRequest) *domain.WorkflowRun {
+	if req.ParentRunID != c.parentRunID {
+		return nil
+	}
+
+	input, err := json.Marshal(req.Input)
+	if err != nil {
+		return nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for i, run := range c.runs {
+		if *run.ParentNodeID != req.ParentNodeID || run.WorkflowID != req.WorkflowID {
+			continue
+		}
+		if runInput, err := json.Marshal(run.InputData); err != nil || !bytes.Equal(runInput, input) {
+			continue
+		}
+		c.runs = append(c.runs[:i], c.runs[i+1:]...)
+		return run
+	}
+	return nil
+}
+
+// failUnclaimed fails the children the resumed run no longer waited on, along
+// with the children they were waiting on themselves.
+func (s *workflowExecutionService) failUnclaimed(ctx context.Context, children *waitingChildren) {
+	ctx = context.WithoutCancel(ctx)
+
+	children.mu.Lock()
+	runs := children.runs
+	children.runs = nil
+	children.mu.Unlock()
+
+	for _, run := range runs {
+		if run.Status == domain.WorkflowRunStatusCompleted {
+			continue
+		}
+
+		log.Printf("failing sub-workflow run %s; its parent run %s no longer waits on it", run.ID, children.parentRunID)
+		s.closeRun(ctx, run.ID, domain.WorkflowRunStatusFailed)
+
+		grandchildren, err := s.loadWaitingChildren(ctx, run.ID)
+		if err != nil {
+			log.Printf("failed to fail sub-workflow runs of run %s: %v", run.ID, err)
+			continue
+		}
+		s.failUnclaimed(ctx, grandchildren)
+	}
+}
+
+// newEngine builds an engine for a run of the workflow's current graph
+func (s *workflowExecutionService) newEngine(ctx context.Context, runID uuid.UUID, workflowID uuid.UUID) (*engine.WorkflowEngine, error) {
+	workflow, err := s.workflowRepo.GetByID(ctx, workflowID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch workflow: %w", err)
+	}
+
+	nodes, edges, err := s.loadGraph(ctx, workflowID)
+	if err != nil {
+		return nil, err
+	}
+
+	eng := engine.NewWorkflowEngine(nodes, edges, runID, workflowID, s.logRepo, s.runRepo)
+	eng.Config = s.engineConfig
+	eng.Settings = workflow.Settings
+	eng.StateRepo = s.stateRepo
+	// Pinned outputs are a development aid; published workflows run for real.
+	eng.UsePinnedOutputs = s.engineConfig.PinnedOutputs && workflow.Status != domain.WorkflowStatusPublished
+
+	return eng, nil
+}
+
+// loadGraph fetches the current nodes and edges of a workflow
+func (s *workflowExecutionService) loadGraph(ctx context.Context, workflowID uuid.UUID) ([]domain.WorkflowNode, []domain.WorkflowEdge, error) {
+	nodePtrs, err := s.nodeRepo.GetByWorkflowID(ctx, workflowID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to fetch workflow nodes: %w", err)
+	}
+
+	edgePtrs, err := s.edgeRepo.GetByWorkflowID(ctx, workflowID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to fetch workflow edges: %w", err)
+	}
+
+	nodes := make([]domain.WorkflowNode, 0, len(nodePtrs))
+	for _, node := range nodePtrs {
+		nodes = append(nodes, *node)
+	}
+
+	edges := make([]domain.WorkflowEdge, 0, len(edgePtrs))
+	for _, edge := range edgePtrs {
+		edges = append(edges, *edge)
+	}
+
+	return nodes, edges, nil
+}
+
+// closeRun finishes a run without executing it further
+func (s *workflowExecutionService) closeRun(ctx context.Context, runID uuid.UUID, status domain.WorkflowRunStatus) {
+	if err := s.logRepo.CancelUnfinished(ctx, runID); err != nil {
+		log.Printf("failed to close logs of run %s: %v", runID, err)
+	}
+
+	now := time.Now()
+	if err := s.runRepo.UpdateStatus(ctx, runID, status, &now); err != nil {
+		log.Printf("failed to close run %s: %v", runID, err)
+	}
+}
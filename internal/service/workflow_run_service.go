@@ -2,6 +2,7 @@ package service
 
 import (
 	"context"
+	"errors"
 	"time"
 
 	"github.com/google/uuid"
@@ -9,17 +10,24 @@ import (
 )
 
 type workflowRunService struct {
-	repo domain.WorkflowRunRepository
+	repo            domain.WorkflowRunRepository
+	workflowService domain.WorkflowService
+	canceller       domain.RunCanceller
 }
 
-func NewWorkflowRunService(repo domain.WorkflowRunRepository) domain.WorkflowRunService {
+func NewWorkflowRunService(repo domain.WorkflowRunRepository, workflowService domain.WorkflowService, canceller domain.RunCanceller) domain.WorkflowRunService {
 	return &workflowRunService{
-		repo: repo,
+		repo:            repo,
+		workflowService: workflowService,
+		canceller:       canceller,
 	}
 }
 
-func (s *workflowRunService) StartWorkflowRun(ctx context.Context, workflowID uuid.UUID) (*domain.WorkflowRunResponse, error) {
-	run, err := s.repo.Create(ctx, workflowID)
+func (s *workflowRunService) StartWorkflowRun(ctx context.Context, workflowID uuid.UUID, input map[string]interface{}) (*domain.WorkflowRunResponse, error) {
+	run, err := s.repo.Create(ctx, &domain.CreateWorkflowRunRequest{
+		WorkflowID: workflowID,
+		InputData:  input,
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -74,3 +82,98 @@ func (s *workflowRunService) UpdateRunStatus(ctx context.Context, id uuid.UUID,
 
 	return s.repo.UpdateStatus(ctx, id, status, finishedAt)
 }
+
+func (s *workflowRunService) CancelWorkflowRun(ctx context.Context, id uuid.UUID, userID uuid.UUID) (*domain.WorkflowRunResponse, error) {
+	run, err := accessibleRun(ctx, s.repo, s.workflowService, id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if run.Status != domain.WorkflowRunStatusPending && run.Status != domain.WorkflowRunStatusRunning {
+		return nil, domain.ErrWorkflowRunFinished
+	}
+
+	// The request is stored first so that the instance executing the run
+	// picks it up even when that is not this one.
+	if err := s.repo.RequestCancel(ctx, id); err != nil {
+		return nil, err
+	}
+
+	if !s.canceller.Cancel(id) && run.Status == domain.WorkflowRunStatusPending {
+		// Nothing is executing a pending run yet, so it can be closed right away.
+		now := time.Now()
+		if err := s.repo.UpdateStatus(ctx, id, domain.WorkflowRunStatusCancelled, &now); err != nil {
+			return nil, err
+		}
+	}
+
+	run, err = s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return run.ToResponse(), nil
+}
+
+// ListChildRuns returns the sub-workflow runs started by a run
+func (s *workflowRunService) ListChildRuns(ctx context.Context, id uuid.UUID, userID uuid.UUID) ([]*domain.WorkflowRunResponse, error) {
+	if _, err := accessibleRun(ctx, s.repo, s.workflowService, id, userID); err != nil {
+		return nil, err
+	}
+
+	runs, err := s.repo.ListByParentRunID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]*domain.WorkflowRunResponse, len(runs))
+	for i, run := range runs {
+		responses[i] = run.ToResponse()
+	}
+
+	return responses, nil
+}
+
+// accessibleRun returns a run whose workflow the user can access. Runs of
+// other workspaces are reported as missing.
+func accessibleRun(ctx context.Context, runRepo domain.WorkflowRunRepository, workflowService domain.WorkflowService, id uuid.UUID, userID uuid.UUID) (*domain.WorkflowRun, error) {
+	run, err := runRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := workflowService.GetWorkflow(ctx, run.WorkflowID, userID); err != nil {
+		if errors.Is(err, domain.ErrUnauthorized) || errors.Is(err, domain.ErrWorkflowNotFound) {
+			return nil, domain.ErrWorkflowRunNotFound
+		}
+		return nil, err
+	}
+
+	return run, nil
+}
+
+// runPollInterval is how often WaitForRun checks whether a run has finished.
+const runPollInterval = 250 * time.Millisecond
+
+// WaitForRun polls a run until it has finished or ctx is done
+func (s *workflowRunService) WaitForRun(ctx context.Context, id uuid.UUID) (*domain.WorkflowRunResponse, bool, error) {
+	ticker := time.NewTicker(runPollInterval)
+	defer ticker.Stop()
+
+	for {
+		// The last check must not fail because the wait is over.
+		run, err := s.repo.GetByID(context.WithoutCancel(ctx), id)
+		if err != nil {
+			return nil, false, err
+		}
+		if run.Status != domain.WorkflowRunStatusPending && run.Status != domain.WorkflowRunStatusRunning {
+			return run.ToResponse(), true, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return run.ToResponse(), false, nil
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mr-isik/loki-backend/internal/domain"
+	"github.com/mr-isik/loki-backend/internal/trigger"
+)
+
+type workflowScheduleService struct {
+	scheduleRepo    domain.WorkflowScheduleRepository
+	workflowService domain.WorkflowService
+}
+
+func NewWorkflowScheduleService(
+	scheduleRepo domain.WorkflowScheduleRepository,
+	workflowService domain.WorkflowService,
+) domain.WorkflowScheduleService {
+	return &workflowScheduleService{
+		scheduleRepo:    scheduleRepo,
+		workflowService: workflowService,
+	}
+}
+
+// GetWorkflowSchedule returns the upcoming fire times of the cron triggers of a workflow
+func (s *workflowScheduleService) GetWorkflowSchedule(ctx context.Context, workflowID uuid.UUID, userID uuid.UUID, count int) (*domain.WorkflowScheduleResponse, error) {
+	workflow, err := s.workflowService.GetWorkflow(ctx, workflowID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	triggers, err := s.scheduleRepo.ListByWorkflowID(ctx, workflowID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &domain.WorkflowScheduleResponse{
+		WorkflowID: workflowID,
+		Active:     workflow.Status == domain.WorkflowStatusPublished,
+		Triggers:   make([]*domain.CronTriggerSchedule, 0, len(triggers)),
+	}
+
+	now := time.Now()
+	for _, t := range triggers {
+		item := &domain.CronTriggerSchedule{
+			NodeID:        t.NodeID,
+			LastFireAt:    t.LastFireAt,
+			NextFireTimes: []time.Time{},
+		}
+		item.Expression, _ = t.Data["expression"].(string)
+
+		schedule, err := trigger.ParseCronSchedule(t.Data)
+		if err != nil {
+			item.Error = err.Error()
+			response.Triggers = append(response.Triggers, item)
+			continue
+		}
+
+		item.Timezone = schedule.Timezone
+		item.StartAt = schedule.StartAt
+		item.EndAt = schedule.EndAt
+		item.MissedFirePolicy = schedule.MissedFirePolicy
+		if schedule.MissedFirePolicy == domain.MissedFirePolicyCatchUp {
+			item.CatchUpLimit = schedule.CatchUpLimit
+		}
+		item.NextFireTimes = schedule.NextN(now, count)
+
+		response.Triggers = append(response.Triggers, item)
+	}
+
+	return response, nil
+}
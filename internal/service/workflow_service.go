@@ -7,18 +7,31 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/mr-isik/loki-backend/internal/domain"
+	"github.com/mr-isik/loki-backend/internal/engine"
 )
 
 type workflowService struct {
 	workflowRepo  domain.WorkflowRepository
 	workspaceRepo domain.WorkspaceRepository
+	nodeRepo      domain.WorkflowNodeRepository
+	edgeRepo      domain.WorkflowEdgeRepository
+	templateRepo  domain.NodeTemplateRepository
 }
 
 // NewWorkflowService creates a new workflow service
-func NewWorkflowService(workflowRepo domain.WorkflowRepository, workspaceRepo domain.WorkspaceRepository) domain.WorkflowService {
+func NewWorkflowService(
+	workflowRepo domain.WorkflowRepository,
+	workspaceRepo domain.WorkspaceRepository,
+	nodeRepo domain.WorkflowNodeRepository,
+	edgeRepo domain.WorkflowEdgeRepository,
+	templateRepo domain.NodeTemplateRepository,
+) domain.WorkflowService {
 	return &workflowService{
 		workflowRepo:  workflowRepo,
 		workspaceRepo: workspaceRepo,
+		nodeRepo:      nodeRepo,
+		edgeRepo:      edgeRepo,
+		templateRepo:  templateRepo,
 	}
 }
 
@@ -43,6 +56,10 @@ func (s *workflowService) CreateWorkflow(ctx context.Context, workspaceID uuid.U
 		workflow.Title = "Untitled Workflow"
 	}
 
+	if req.Settings != nil {
+		workflow.Settings = *req.Settings
+	}
+
 	if err := s.workflowRepo.Create(ctx, workflow); err != nil {
 		return nil, fmt.Errorf("failed to create workflow: %w", err)
 	}
@@ -131,6 +148,10 @@ func (s *workflowService) UpdateWorkflow(ctx context.Context, id uuid.UUID, user
 		workflow.Status = req.Status
 	}
 
+	if req.Settings != nil {
+		workflow.Settings = *req.Settings
+	}
+
 	if err := s.workflowRepo.Update(ctx, workflow); err != nil {
 		return nil, fmt.Errorf("failed to update workflow: %w", err)
 	}
@@ -185,6 +206,15 @@ func (s *workflowService) PublishWorkflow(ctx context.Context, id uuid.UUID, use
 		return domain.ErrUnauthorized
 	}
 
+	// Only valid graphs can be published
+	validation, err := s.validateGraph(ctx, id)
+	if err != nil {
+		return err
+	}
+	if !validation.Valid {
+		return &domain.WorkflowValidationError{Result: validation}
+	}
+
 	// Update status
 	if err := s.workflowRepo.UpdateStatus(ctx, id, domain.WorkflowStatusPublished); err != nil {
 		return fmt.Errorf("failed to publish workflow: %w", err)
@@ -232,3 +262,44 @@ func (s *workflowService) ArchiveWorkflow(ctx context.Context, id uuid.UUID, use
 
 	return nil
 }
+
+// ValidateWorkflow runs the static checks on a workflow's graph
+func (s *workflowService) ValidateWorkflow(ctx context.Context, id uuid.UUID, userID uuid.UUID) (*domain.WorkflowValidationResult, error) {
+	if _, err := s.GetWorkflow(ctx, id, userID); err != nil {
+		return nil, err
+	}
+
+	return s.validateGraph(ctx, id)
+}
+
+func (s *workflowService) validateGraph(ctx context.Context, workflowID uuid.UUID) (*domain.WorkflowValidationResult, error) {
+	nodePtrs, err := s.nodeRepo.GetByWorkflowID(ctx, workflowID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch workflow nodes: %w", err)
+	}
+
+	edgePtrs, err := s.edgeRepo.GetByWorkflowID(ctx, workflowID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch workflow edges: %w", err)
+	}
+
+	templateList, err := s.templateRepo.GetAll(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch node templates: %w", err)
+	}
+
+	nodes := make([]domain.WorkflowNode, len(nodePtrs))
+	for i, n := range nodePtrs {
+		nodes[i] = *n
+	}
+	edges := make([]domain.WorkflowEdge, len(edgePtrs))
+	for i, e := range edgePtrs {
+		edges[i] = *e
+	}
+	templates := make(map[uuid.UUID]*domain.NodeTemplate, len(templateList))
+	for _, t := range templateList {
+		templates[t.ID] = t
+	}
+
+	return engine.ValidateGraph(nodes, edges, templates), nil
+}
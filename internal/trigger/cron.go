@@ -0,0 +1,152 @@
+package trigger
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	// Timezones must resolve even on hosts without a zoneinfo database.
+	_ "time/tzdata"
+
+	"github.com/mr-isik/loki-backend/internal/domain"
+	"github.com/robfig/cron/v3"
+)
+
+// CronNodeType is the type key of the nodes the scheduler fires.
+const CronNodeType = "cron"
+
+// maxMissedScan bounds how many missed fire times are looked at when a
+// schedule has been down for a long time.
+const maxMissedScan = 100000
+
+// cronParser accepts standard five-field expressions, six fields with a
+// leading seconds field, and descriptors such as @hourly or @every 5m.
+var cronParser = cron.NewParser(
+	cron.SecondOptional | cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor,
+)
+
+// CronSchedule is the schedule configured on a cron node.
+type CronSchedule struct {
+	Expression string `json:"expression"`
+	// Timezone is the IANA name of the timezone the expression is
+	// evaluated in (default UTC).
+	Timezone string `json:"timezone"`
+	// StartAt and EndAt limit the window in which the schedule fires.
+	StartAt *time.Time `json:"start_at"`
+	EndAt   *time.Time `json:"end_at"`
+	// MissedFirePolicy is skip (default) or catch_up.
+	MissedFirePolicy string `json:"missed_fire_policy"`
+	// CatchUpLimit is the number of missed fire times caught up (default 1).
+	CatchUpLimit int `json:"catch_up_limit"`
+
+	schedule cron.Schedule
+}
+
+// ParseCronSchedule reads the schedule from the data of a cron node.
+func ParseCronSchedule(data map[string]any) (*CronSchedule, error) {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+	var s CronSchedule
+	if err := json.Unmarshal(raw, &s); err != nil {
+		return nil, fmt.Errorf("invalid schedule: %w", err)
+	}
+
+	s.Expression = strings.TrimSpace(s.Expression)
+	if s.Expression == "" {
+		return nil, errors.New("expression is required")
+	}
+	if s.Timezone == "" {
+		s.Timezone = "UTC"
+	}
+	loc, err := time.LoadLocation(s.Timezone)
+	if err != nil {
+		return nil, fmt.Errorf("unknown timezone %q", s.Timezone)
+	}
+
+	switch s.MissedFirePolicy {
+	case "":
+		s.MissedFirePolicy = domain.MissedFirePolicySkip
+	case domain.MissedFirePolicySkip, domain.MissedFirePolicyCatchUp:
+	default:
+		return nil, fmt.Errorf("unknown missed_fire_policy %q", s.MissedFirePolicy)
+	}
+	if s.CatchUpLimit <= 0 {
+		s.CatchUpLimit = 1
+	}
+	if s.StartAt != nil && s.EndAt != nil && !s.EndAt.After(*s.StartAt) {
+		return nil, errors.New("end_at must be after start_at")
+	}
+
+	schedule, err := cronParser.Parse(s.Expression)
+	if err != nil {
+		return nil, fmt.Errorf("invalid expression %q: %w", s.Expression, err)
+	}
+	if spec, ok := schedule.(*cron.SpecSchedule); ok {
+		spec.Location = loc
+	}
+	s.schedule = schedule
+
+	return &s, nil
+}
+
+// Next returns the first fire time after t, or the zero time when the
+// schedule does not fire again.
+func (s *CronSchedule) Next(t time.Time) time.Time {
+	if s.StartAt != nil && t.Before(*s.StartAt) {
+		// The start of the window is itself a possible fire time.
+		t = s.StartAt.Add(-time.Nanosecond)
+	}
+	next := s.schedule.Next(t)
+	if next.IsZero() || (s.EndAt != nil && next.After(*s.EndAt)) {
+		return time.Time{}
+	}
+	return next
+}
+
+// NextN returns up to n fire times after t.
+func (s *CronSchedule) NextN(t time.Time, n int) []time.Time {
+	times := make([]time.Time, 0, n)
+	for len(times) < n {
+		t = s.Next(t)
+		if t.IsZero() {
+			break
+		}
+		times = append(times, t)
+	}
+	return times
+}
+
+// Due returns the fire times after last and up to now that should start a
+// run. Fire times more than threshold before now are missed: the skip policy
+// drops them and the catch_up policy keeps the latest CatchUpLimit of them.
+func (s *CronSchedule) Due(last, now time.Time, threshold time.Duration) []time.Time {
+	onTime := now.Add(-threshold)
+
+	var missed, due []time.Time
+	t := last
+	if s.MissedFirePolicy == domain.MissedFirePolicySkip && t.Before(onTime) {
+		t = onTime
+	}
+	for scanned := 0; ; scanned++ {
+		if scanned == maxMissedScan && t.Before(onTime) {
+			t = onTime
+		}
+		t = s.Next(t)
+		if t.IsZero() || t.After(now) {
+			break
+		}
+		if t.Before(onTime) {
+			missed = append(missed, t)
+			if len(missed) > s.CatchUpLimit {
+				missed = missed[1:]
+			}
+			continue
+		}
+		due = append(due, t)
+	}
+
+	return append(missed, due...)
+}
@@ -0,0 +1,90 @@
+package trigger
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mr-isik/loki-backend/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func mustTime(t *testing.T, value string) time.Time {
+	t.Helper()
+	parsed, err := time.Parse(time.RFC3339, value)
+	require.NoError(t, err)
+	return parsed
+}
+
+func TestParseCronSchedule(t *testing.T) {
+	s, err := ParseCronSchedule(map[string]any{"type": "cron", "expression": "0 9 * * *"})
+	require.NoError(t, err)
+	assert.Equal(t, "UTC", s.Timezone)
+	assert.Equal(t, domain.MissedFirePolicySkip, s.MissedFirePolicy)
+	assert.Equal(t, 1, s.CatchUpLimit)
+
+	invalid := []map[string]any{
+		{},
+		{"expression": "not a cron"},
+		{"expression": "* * * * *", "timezone": "Mars/Olympus"},
+		{"expression": "* * * * *", "missed_fire_policy": "sometimes"},
+		{"expression": "* * * * *", "start_at": "2026-01-02T00:00:00Z", "end_at": "2026-01-01T00:00:00Z"},
+	}
+	for _, data := range invalid {
+		_, err := ParseCronSchedule(data)
+		assert.Error(t, err, data)
+	}
+}
+
+func TestCronSchedule_Next(t *testing.T) {
+	// Seconds field and timezone: 09:00:30 in Istanbul is 06:00:30 UTC.
+	s, err := ParseCronSchedule(map[string]any{"expression": "30 0 9 * * *", "timezone": "Europe/Istanbul"})
+	require.NoError(t, err)
+	next := s.Next(mustTime(t, "2026-03-01T00:00:00Z"))
+	assert.Equal(t, mustTime(t, "2026-03-01T06:00:30Z"), next.UTC())
+
+	// Window: fires from start_at and not after end_at.
+	s, err = ParseCronSchedule(map[string]any{
+		"expression": "0 * * * *",
+		"start_at":   "2026-03-01T10:00:00Z",
+		"end_at":     "2026-03-01T12:00:00Z",
+	})
+	require.NoError(t, err)
+	times := s.NextN(mustTime(t, "2026-03-01T00:00:00Z"), 5)
+	require.Len(t, times, 3)
+	assert.Equal(t, mustTime(t, "2026-03-01T10:00:00Z"), times[0].UTC())
+	assert.Equal(t, mustTime(t, "2026-03-01T12:00:00Z"), times[2].UTC())
+	assert.True(t, s.Next(mustTime(t, "2026-03-01T12:00:00Z")).IsZero())
+}
+
+func TestCronSchedule_Due(t *testing.T) {
+	last := mustTime(t, "2026-03-01T10:00:00Z")
+	now := mustTime(t, "2026-03-01T15:00:10Z")
+
+	// 11:00 to 14:00 are missed; 15:00 is on time.
+	skip, err := ParseCronSchedule(map[string]any{"expression": "0 * * * *"})
+	require.NoError(t, err)
+	assert.Equal(t, []time.Time{mustTime(t, "2026-03-01T15:00:00Z")}, utc(skip.Due(last, now, time.Minute)))
+
+	catchUp, err := ParseCronSchedule(map[string]any{
+		"expression":         "0 * * * *",
+		"missed_fire_policy": "catch_up",
+		"catch_up_limit":     2,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []time.Time{
+		mustTime(t, "2026-03-01T13:00:00Z"),
+		mustTime(t, "2026-03-01T14:00:00Z"),
+		mustTime(t, "2026-03-01T15:00:00Z"),
+	}, utc(catchUp.Due(last, now, time.Minute)))
+
+	assert.Empty(t, skip.Due(now, now, time.Minute))
+}
+
+func utc(times []time.Time) []time.Time {
+	out := make([]time.Time, len(times))
+	for i, t := range times {
+		out[i] = t.UTC()
+	}
+	return out
+}
@@ -0,0 +1,94 @@
+package trigger
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/mr-isik/loki-backend/internal/domain"
+)
+
+// LeaderLockKey is the Postgres advisory lock key held by the instance that
+// runs the triggers.
+const LeaderLockKey int64 = 0x6c6f6b69_74726967
+
+// RunAsLeader runs lead while this instance holds the leader lock, until ctx
+// is done. Instances that do not hold the lock try to take it every interval,
+// so another instance takes over within an interval or so of the leader
+// dying. The leader checks the lock at the same interval and stops lead when
+// it has been lost.
+func RunAsLeader(ctx context.Context, lock domain.LeaderLock, interval time.Duration, lead func(ctx context.Context)) {
+	tick := time.NewTicker(interval)
+	defer tick.Stop()
+
+	for {
+		acquired, err := lock.TryAcquire(ctx)
+		if err != nil && ctx.Err() == nil {
+			log.Printf("⚠️ Failed to acquire leader lock: %v", err)
+		}
+		if acquired {
+			log.Println("👑 Acquired leader lock; running triggers")
+			lostErr := holdLeadership(ctx, lock, tick.C, lead)
+			if lostErr != nil {
+				log.Printf("⚠️ Lost leader lock: %v", lostErr)
+			}
+			if err := lock.Release(context.WithoutCancel(ctx)); err != nil {
+				log.Printf("⚠️ Failed to release leader lock: %v", err)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-tick.C:
+		}
+	}
+}
+
+// holdLeadership runs lead until ctx is done or the lock is lost, and waits
+// for lead to return. It returns the error that showed the lock was lost.
+func holdLeadership(ctx context.Context, lock domain.LeaderLock, tick <-chan time.Time, lead func(ctx context.Context)) error {
+	leaderCtx, stop := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		lead(leaderCtx)
+	}()
+	defer func() {
+		stop()
+		<-done
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-done:
+			return nil
+		case <-tick:
+			if err := lock.Check(ctx); err != nil {
+				if ctx.Err() != nil {
+					return nil
+				}
+				return err
+			}
+		}
+	}
+}
+
+// RunAll runs every trigger runner until ctx is done and they have all
+// returned. It lets a single leader run several kinds of triggers.
+func RunAll(runners ...func(ctx context.Context)) func(ctx context.Context) {
+	return func(ctx context.Context) {
+		var wg sync.WaitGroup
+		for _, run := range runners {
+			wg.Add(1)
+			go func(run func(ctx context.Context)) {
+				defer wg.Done()
+				run(ctx)
+			}(run)
+		}
+		wg.Wait()
+	}
+}
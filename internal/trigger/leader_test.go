@@ -0,0 +1,165 @@
+package trigger
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mr-isik/loki-backend/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// sharedLock is a leader lock shared by the lockHandles of several
+// simulated instances.
+type sharedLock struct {
+	mu     sync.Mutex
+	holder *lockHandle
+}
+
+type lockHandle struct {
+	lock *sharedLock
+	// dead simulates an instance whose database session has ended.
+	dead bool
+}
+
+func (h *lockHandle) TryAcquire(ctx context.Context) (bool, error) {
+	h.lock.mu.Lock()
+	defer h.lock.mu.Unlock()
+	if h.dead {
+		return false, errors.New("connection closed")
+	}
+	if h.lock.holder == nil {
+		h.lock.holder = h
+	}
+	return h.lock.holder == h, nil
+}
+
+func (h *lockHandle) Check(ctx context.Context) error {
+	h.lock.mu.Lock()
+	defer h.lock.mu.Unlock()
+	if h.lock.holder != h {
+		return errors.New("lock not held")
+	}
+	return nil
+}
+
+func (h *lockHandle) Release(ctx context.Context) error {
+	h.lock.mu.Lock()
+	defer h.lock.mu.Unlock()
+	if h.lock.holder == h {
+		h.lock.holder = nil
+	}
+	return nil
+}
+
+// kill ends the instance's session, which releases its lock.
+func (h *lockHandle) kill() {
+	h.lock.mu.Lock()
+	defer h.lock.mu.Unlock()
+	h.dead = true
+	if h.lock.holder == h {
+		h.lock.holder = nil
+	}
+}
+
+func TestRunAsLeader_FailsOver(t *testing.T) {
+	lock := &sharedLock{}
+	first := &lockHandle{lock: lock}
+	second := &lockHandle{lock: lock}
+
+	var mu sync.Mutex
+	leading := map[string]bool{}
+	lead := func(name string) func(ctx context.Context) {
+		return func(ctx context.Context) {
+			mu.Lock()
+			leading[name] = true
+			mu.Unlock()
+			<-ctx.Done()
+			mu.Lock()
+			leading[name] = false
+			mu.Unlock()
+		}
+	}
+	isLeading := func(name string) func() bool {
+		return func() bool {
+			mu.Lock()
+			defer mu.Unlock()
+			return leading[name]
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go RunAsLeader(ctx, first, 10*time.Millisecond, lead("first"))
+	require.Eventually(t, isLeading("first"), time.Second, 5*time.Millisecond)
+
+	go RunAsLeader(ctx, second, 10*time.Millisecond, lead("second"))
+	time.Sleep(50 * time.Millisecond)
+	assert.False(t, isLeading("second")(), "only one instance leads")
+
+	// When the leader's session ends, it stops leading and the other
+	// instance takes over.
+	first.kill()
+	require.Eventually(t, func() bool { return !isLeading("first")() }, time.Second, 5*time.Millisecond)
+	require.Eventually(t, isLeading("second"), time.Second, 5*time.Millisecond)
+
+	cancel()
+	require.Eventually(t, func() bool { return !isLeading("second")() }, time.Second, 5*time.Millisecond)
+}
+
+type fireRecorder struct {
+	domain.WorkflowScheduleRepository
+	mu      sync.Mutex
+	claimed map[time.Time]bool
+}
+
+func (r *fireRecorder) ClaimFire(ctx context.Context, nodeID, workflowID uuid.UUID, fireTime time.Time) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.claimed[fireTime] {
+		return false, nil
+	}
+	r.claimed[fireTime] = true
+	return true, nil
+}
+
+type countingRuns struct {
+	domain.WorkflowRunService
+	domain.WorkflowExecutionService
+	mu      sync.Mutex
+	started int
+}
+
+func (r *countingRuns) StartWorkflowRun(ctx context.Context, workflowID uuid.UUID, input map[string]interface{}) (*domain.WorkflowRunResponse, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.started++
+	return &domain.WorkflowRunResponse{ID: uuid.New(), WorkflowID: workflowID}, nil
+}
+
+func (r *countingRuns) StartRun(ctx context.Context, runID uuid.UUID) error {
+	return nil
+}
+
+func TestScheduler_FireTimeStartsOneRun(t *testing.T) {
+	repo := &fireRecorder{claimed: map[time.Time]bool{}}
+	runs := &countingRuns{}
+	// Two schedulers share the claims, as two instances share the database.
+	a := NewScheduler(DefaultConfig(), repo, runs, runs)
+	b := NewScheduler(DefaultConfig(), repo, runs, runs)
+
+	trigger := &domain.CronTrigger{NodeID: uuid.New(), WorkflowID: uuid.New()}
+	fireTime := mustTime(t, "2026-03-01T09:00:00Z")
+
+	require.NoError(t, a.fire(context.Background(), trigger, fireTime))
+	require.NoError(t, b.fire(context.Background(), trigger, fireTime))
+	assert.Equal(t, 1, runs.started)
+
+	require.NoError(t, b.fire(context.Background(), trigger, fireTime.Add(time.Minute)))
+	assert.Equal(t, 2, runs.started)
+}
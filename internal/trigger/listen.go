@@ -0,0 +1,106 @@
+package trigger
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// ListenNodeType is the type key of the nodes started by Postgres
+// notifications.
+const ListenNodeType = "db_postgres_listen"
+
+// ListenConfig is the configuration of a db_postgres_listen node.
+type ListenConfig struct {
+	// DSN is the connection string of the database to listen on.
+	DSN     string
+	Channel string
+}
+
+type listenData struct {
+	Host     string `json:"host"`
+	Port     int    `json:"port"`
+	User     string `json:"user"`
+	Password string `json:"password"`
+	DbName   string `json:"dbname"`
+	SSLMode  string `json:"sslmode"`
+	Channel  string `json:"channel"`
+}
+
+// ParseListenConfig reads the configuration of a db_postgres_listen node. The
+// connection fields are the same as those of db_postgres nodes.
+func ParseListenConfig(data map[string]any) (*ListenConfig, error) {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+	var d listenData
+	if err := json.Unmarshal(raw, &d); err != nil {
+		return nil, fmt.Errorf("invalid listen configuration: %w", err)
+	}
+
+	if d.Host == "" || d.User == "" || d.DbName == "" {
+		return nil, errors.New("host, user and dbname are required")
+	}
+	if d.Channel == "" {
+		return nil, errors.New("channel is required")
+	}
+	if d.Port == 0 {
+		d.Port = 5432
+	}
+	if d.SSLMode == "" {
+		d.SSLMode = "disable"
+	}
+
+	dsn := &url.URL{
+		Scheme: "postgres",
+		User:   url.UserPassword(d.User, d.Password),
+		Host:   d.Host + ":" + strconv.Itoa(d.Port),
+		Path:   d.DbName,
+	}
+	q := dsn.Query()
+	q.Set("sslmode", d.SSLMode)
+	dsn.RawQuery = q.Encode()
+
+	return &ListenConfig{DSN: dsn.String(), Channel: d.Channel}, nil
+}
+
+// ListenStatement returns the LISTEN statement for the channel, which is
+// quoted as an identifier.
+func (c *ListenConfig) ListenStatement() string {
+	return "LISTEN " + pgx.Identifier{c.Channel}.Sanitize()
+}
+
+// NotificationInput builds the run input of a notification. The payload is
+// parsed as JSON when possible and passed on as text otherwise.
+func NotificationInput(n *pgconn.Notification) map[string]interface{} {
+	var payload interface{} = n.Payload
+	var parsed interface{}
+	if err := json.Unmarshal([]byte(n.Payload), &parsed); err == nil {
+		payload = parsed
+	}
+
+	return map[string]interface{}{
+		"channel":     n.Channel,
+		"payload":     payload,
+		"raw_payload": n.Payload,
+		"pid":         n.PID,
+		"received_at": time.Now().Format(time.RFC3339),
+	}
+}
+
+// backoff returns how long to wait before reconnect attempt number attempt
+// (starting at 0): minDelay doubled for every attempt, capped at maxDelay.
+func backoff(attempt int, minDelay, maxDelay time.Duration) time.Duration {
+	delay := minDelay
+	for i := 0; i < attempt && delay < maxDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, maxDelay)
+}
@@ -0,0 +1,213 @@
+package trigger
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"reflect"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/mr-isik/loki-backend/internal/domain"
+)
+
+// ListenerConfig controls how the listener loads triggers and reconnects.
+type ListenerConfig struct {
+	// SyncInterval is how often triggers are reloaded. Listening stops within
+	// about this long of a workflow being unpublished.
+	SyncInterval time.Duration
+	// MinBackoff and MaxBackoff bound the wait before reconnecting a lost
+	// connection; the wait doubles with every failed attempt.
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+}
+
+// DefaultListenerConfig returns the settings used when nothing else is configured.
+func DefaultListenerConfig() ListenerConfig {
+	return ListenerConfig{
+		SyncInterval: 10 * time.Second,
+		MinBackoff:   time.Second,
+		MaxBackoff:   time.Minute,
+	}
+}
+
+// Listener keeps a connection listening on the channel of every
+// db_postgres_listen node of a published workflow, and starts a run for each
+// notification. Like the scheduler, it should only run on the leader.
+// Notifications sent while no connection is listening are not delivered.
+type Listener struct {
+	config           ListenerConfig
+	listenRepo       domain.WorkflowListenRepository
+	runService       domain.WorkflowRunService
+	executionService domain.WorkflowExecutionService
+
+	// entries holds the triggers being listened on by node ID. It is only
+	// used from the goroutine running the listener.
+	entries map[uuid.UUID]*listenEntry
+}
+
+type listenEntry struct {
+	trigger *domain.ListenTrigger
+	stop    context.CancelFunc
+	done    chan struct{}
+}
+
+// NewListener creates a new listener
+func NewListener(
+	config ListenerConfig,
+	listenRepo domain.WorkflowListenRepository,
+	runService domain.WorkflowRunService,
+	executionService domain.WorkflowExecutionService,
+) *Listener {
+	return &Listener{
+		config:           config,
+		listenRepo:       listenRepo,
+		runService:       runService,
+		executionService: executionService,
+		entries:          make(map[uuid.UUID]*listenEntry),
+	}
+}
+
+// Run listens until ctx is done and every connection has been closed.
+func (l *Listener) Run(ctx context.Context) {
+	log.Println("👂 Listener started")
+	l.entries = make(map[uuid.UUID]*listenEntry)
+
+	tick := time.NewTicker(l.config.SyncInterval)
+	defer tick.Stop()
+
+	for {
+		if err := l.sync(ctx); err != nil && ctx.Err() == nil {
+			log.Printf("⚠️ Failed to load listen triggers: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			for nodeID := range l.entries {
+				l.stop(nodeID)
+			}
+			log.Println("👂 Listener stopped")
+			return
+		case <-tick.C:
+		}
+	}
+}
+
+// sync starts listening for new triggers, restarts those whose configuration
+// changed and stops those of workflows that are no longer published.
+func (l *Listener) sync(ctx context.Context) error {
+	triggers, err := l.listenRepo.ListActive(ctx)
+	if err != nil {
+		return err
+	}
+
+	active := make(map[uuid.UUID]bool, len(triggers))
+	for _, trigger := range triggers {
+		active[trigger.NodeID] = true
+
+		if entry, ok := l.entries[trigger.NodeID]; ok {
+			if reflect.DeepEqual(entry.trigger.Data, trigger.Data) {
+				continue
+			}
+			l.stop(trigger.NodeID)
+		}
+
+		config, err := ParseListenConfig(trigger.Data)
+		if err != nil {
+			log.Printf("⚠️ Listen node %s of workflow %s is not listening: %v", trigger.NodeID, trigger.WorkflowID, err)
+			continue
+		}
+
+		listenCtx, stop := context.WithCancel(ctx)
+		entry := &listenEntry{trigger: trigger, stop: stop, done: make(chan struct{})}
+		l.entries[trigger.NodeID] = entry
+		go func() {
+			defer close(entry.done)
+			l.listen(listenCtx, trigger, config)
+		}()
+	}
+
+	for nodeID := range l.entries {
+		if !active[nodeID] {
+			l.stop(nodeID)
+		}
+	}
+
+	return nil
+}
+
+// stop closes the connection of a trigger and waits for it to finish.
+func (l *Listener) stop(nodeID uuid.UUID) {
+	entry := l.entries[nodeID]
+	entry.stop()
+	<-entry.done
+	delete(l.entries, nodeID)
+}
+
+// listen keeps a connection listening on the trigger's channel until ctx is
+// done, reconnecting with backoff when it is lost.
+func (l *Listener) listen(ctx context.Context, trigger *domain.ListenTrigger, config *ListenConfig) {
+	attempt := 0
+	for ctx.Err() == nil {
+		err := l.receive(ctx, trigger, config, func() { attempt = 0 })
+		if ctx.Err() != nil {
+			return
+		}
+
+		delay := backoff(attempt, l.config.MinBackoff, l.config.MaxBackoff)
+		attempt++
+		log.Printf("⚠️ Listen node %s lost channel %q: %v; reconnecting in %s", trigger.NodeID, config.Channel, err, delay)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+	}
+}
+
+// receive connects, listens and starts runs for notifications until the
+// connection fails or ctx is done. connected is called once listening.
+func (l *Listener) receive(ctx context.Context, trigger *domain.ListenTrigger, config *ListenConfig, connected func()) error {
+	conn, err := pgx.Connect(ctx, config.DSN)
+	if err != nil {
+		return fmt.Errorf("failed to connect: %w", err)
+	}
+	defer conn.Close(context.WithoutCancel(ctx))
+
+	if _, err := conn.Exec(ctx, config.ListenStatement()); err != nil {
+		return fmt.Errorf("failed to listen: %w", err)
+	}
+	connected()
+	log.Printf("👂 Listen node %s is listening on channel %q", trigger.NodeID, config.Channel)
+
+	for {
+		notification, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return err
+		}
+		if err := l.fire(ctx, trigger, notification); err != nil {
+			log.Printf("⚠️ Failed to start run of workflow %s for notification on %q: %v", trigger.WorkflowID, notification.Channel, err)
+		}
+	}
+}
+
+// fire starts a run for a notification. The db_postgres_listen node outputs
+// the notification.
+func (l *Listener) fire(ctx context.Context, trigger *domain.ListenTrigger, notification *pgconn.Notification) error {
+	input := NotificationInput(notification)
+	input["trigger_node_id"] = trigger.NodeID.String()
+
+	run, err := l.runService.StartWorkflowRun(ctx, trigger.WorkflowID, input)
+	if err != nil {
+		return fmt.Errorf("failed to create run: %w", err)
+	}
+	if err := l.executionService.StartRun(ctx, run.ID); err != nil {
+		return fmt.Errorf("failed to queue run %s: %w", run.ID, err)
+	}
+
+	log.Printf("👂 Listen node %s started run %s", trigger.NodeID, run.ID)
+	return nil
+}
@@ -0,0 +1,233 @@
+package trigger
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// PollNodeType is the type key of the nodes that poll an HTTP endpoint.
+const PollNodeType = "poll_http"
+
+const (
+	defaultPollInterval = 5 * time.Minute
+	// MinPollInterval keeps triggers from hammering the endpoints they poll.
+	MinPollInterval    = 10 * time.Second
+	defaultPollTimeout = 30 * time.Second
+	// maxPollResponseBytes bounds the response bodies read by a poll.
+	maxPollResponseBytes = 10 << 20
+)
+
+// PollConfig is the configuration of a poll_http node.
+type PollConfig struct {
+	URL     string
+	Method  string
+	Headers map[string]string
+	Body    interface{}
+	// Interval is the time between two polls.
+	Interval time.Duration
+	Timeout  time.Duration
+	// ItemsPath locates the items array in the response, e.g. "data.items"
+	// or "$.results[0].rows". Empty means the response itself is the array.
+	ItemsPath string
+	// IDField is the path of an item's ID within the item. Items are keyed
+	// by a hash of their content when it is empty or the item lacks it.
+	IDField string
+	// FireOnFirstPoll starts runs for the items of the very first poll.
+	// By default they are only recorded as seen.
+	FireOnFirstPoll bool
+}
+
+type pollData struct {
+	URL             string            `json:"url"`
+	Method          string            `json:"method"`
+	Headers         map[string]string `json:"headers"`
+	Body            interface{}       `json:"body"`
+	Interval        string            `json:"interval"`
+	Timeout         int               `json:"timeout"`
+	ItemsPath       string            `json:"items_path"`
+	IDField         string            `json:"id_field"`
+	FireOnFirstPoll bool              `json:"fire_on_first_poll"`
+}
+
+// ParsePollConfig reads the configuration of a poll_http node and fills in
+// the defaults.
+func ParsePollConfig(data map[string]any) (*PollConfig, error) {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+	var d pollData
+	if err := json.Unmarshal(raw, &d); err != nil {
+		return nil, fmt.Errorf("invalid poll configuration: %w", err)
+	}
+
+	if d.URL == "" {
+		return nil, errors.New("url is required")
+	}
+
+	c := &PollConfig{
+		URL:             d.URL,
+		Method:          strings.ToUpper(d.Method),
+		Headers:         d.Headers,
+		Body:            d.Body,
+		Interval:        defaultPollInterval,
+		Timeout:         defaultPollTimeout,
+		ItemsPath:       d.ItemsPath,
+		IDField:         d.IDField,
+		FireOnFirstPoll: d.FireOnFirstPoll,
+	}
+	if c.Method == "" {
+		c.Method = http.MethodGet
+	}
+	if d.Interval != "" {
+		c.Interval, err = time.ParseDuration(d.Interval)
+		if err != nil {
+			return nil, fmt.Errorf("invalid interval %q: %w", d.Interval, err)
+		}
+		if c.Interval < MinPollInterval {
+			return nil, fmt.Errorf("interval must be at least %s", MinPollInterval)
+		}
+	}
+	if d.Timeout > 0 {
+		c.Timeout = time.Duration(d.Timeout) * time.Second
+	}
+
+	return c, nil
+}
+
+// Fetch calls the endpoint and returns the items of its response.
+func (c *PollConfig) Fetch(ctx context.Context, client *http.Client) ([]interface{}, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.Timeout)
+	defer cancel()
+
+	var body io.Reader
+	if c.Body != nil {
+		encoded, err := json.Marshal(c.Body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal body: %w", err)
+		}
+		body = bytes.NewReader(encoded)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, c.Method, c.URL, body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	for k, v := range c.Headers {
+		req.Header.Set(k, v)
+	}
+	if c.Body != nil && req.Header.Get("Content-Type") == "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if req.Header.Get("Accept") == "" {
+		req.Header.Set("Accept", "application/json")
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("request failed with status %d", resp.StatusCode)
+	}
+
+	var response interface{}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxPollResponseBytes)).Decode(&response); err != nil {
+		return nil, fmt.Errorf("response is not valid JSON: %w", err)
+	}
+
+	return c.Items(response)
+}
+
+// Items extracts the items array from a decoded response.
+func (c *PollConfig) Items(response interface{}) ([]interface{}, error) {
+	value, err := lookupPath(response, c.ItemsPath)
+	if err != nil {
+		return nil, fmt.Errorf("items_path %q: %w", c.ItemsPath, err)
+	}
+	if value == nil {
+		return nil, nil
+	}
+	items, ok := value.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("items_path %q does not point to an array", c.ItemsPath)
+	}
+	return items, nil
+}
+
+// ItemKey identifies an item across polls: by its ID field when it has one,
+// otherwise by a hash of its content.
+func (c *PollConfig) ItemKey(item interface{}) string {
+	if c.IDField != "" {
+		if id, err := lookupPath(item, c.IDField); err == nil && id != nil {
+			switch v := id.(type) {
+			case string:
+				return "id:" + v
+			case float64:
+				return "id:" + strconv.FormatFloat(v, 'f', -1, 64)
+			default:
+				encoded, _ := json.Marshal(v)
+				return "id:" + string(encoded)
+			}
+		}
+	}
+
+	// encoding/json sorts map keys, so equal items hash the same.
+	encoded, _ := json.Marshal(item)
+	sum := sha256.Sum256(encoded)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// lookupPath follows a path of dot-separated keys and [n] indexes, with an
+// optional leading "$", through decoded JSON. A missing key yields nil.
+func lookupPath(v interface{}, path string) (interface{}, error) {
+	path = strings.TrimPrefix(strings.TrimSpace(path), "$")
+	for path != "" {
+		switch path[0] {
+		case '.':
+			path = path[1:]
+		case '[':
+			end := strings.IndexByte(path, ']')
+			if end < 0 {
+				return nil, errors.New("unclosed [")
+			}
+			index, err := strconv.Atoi(path[1:end])
+			if err != nil {
+				return nil, fmt.Errorf("invalid index %q", path[1:end])
+			}
+			path = path[end+1:]
+
+			list, ok := v.([]interface{})
+			if !ok || index < 0 || index >= len(list) {
+				return nil, nil
+			}
+			v = list[index]
+		default:
+			end := strings.IndexAny(path, ".[")
+			if end < 0 {
+				end = len(path)
+			}
+			key := path[:end]
+			path = path[end:]
+
+			object, ok := v.(map[string]interface{})
+			if !ok {
+				return nil, nil
+			}
+			v = object[key]
+		}
+	}
+	return v, nil
+}
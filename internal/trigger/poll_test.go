@@ -0,0 +1,192 @@
+package trigger
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mr-isik/loki-backend/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePollConfig(t *testing.T) {
+	c, err := ParsePollConfig(map[string]any{"type": "poll_http", "url": "https://example.com/orders"})
+	require.NoError(t, err)
+	assert.Equal(t, http.MethodGet, c.Method)
+	assert.Equal(t, defaultPollInterval, c.Interval)
+	assert.Equal(t, defaultPollTimeout, c.Timeout)
+	assert.False(t, c.FireOnFirstPoll)
+
+	c, err = ParsePollConfig(map[string]any{"url": "https://example.com", "interval": "30s", "timeout": 5})
+	require.NoError(t, err)
+	assert.Equal(t, 30*time.Second, c.Interval)
+	assert.Equal(t, 5*time.Second, c.Timeout)
+
+	invalid := []map[string]any{
+		{},
+		{"url": "https://example.com", "interval": "soon"},
+		{"url": "https://example.com", "interval": "1s"},
+	}
+	for _, data := range invalid {
+		_, err := ParsePollConfig(data)
+		assert.Error(t, err, data)
+	}
+}
+
+func TestPollConfig_Items(t *testing.T) {
+	var response interface{}
+	require.NoError(t, json.Unmarshal([]byte(`{"data":{"results":[{"rows":[1,2]}]}}`), &response))
+
+	c := &PollConfig{ItemsPath: "$.data.results[0].rows"}
+	items, err := c.Items(response)
+	require.NoError(t, err)
+	assert.Equal(t, []interface{}{1.0, 2.0}, items)
+
+	c.ItemsPath = "data.missing"
+	items, err = c.Items(response)
+	require.NoError(t, err)
+	assert.Empty(t, items)
+
+	c.ItemsPath = "data"
+	_, err = c.Items(response)
+	assert.Error(t, err)
+
+	c.ItemsPath = ""
+	items, err = c.Items([]interface{}{"a"})
+	require.NoError(t, err)
+	assert.Equal(t, []interface{}{"a"}, items)
+}
+
+func TestPollConfig_ItemKey(t *testing.T) {
+	c := &PollConfig{IDField: "meta.id"}
+	assert.Equal(t, "id:42", c.ItemKey(map[string]interface{}{"meta": map[string]interface{}{"id": 42.0}}))
+	assert.Equal(t, "id:abc", c.ItemKey(map[string]interface{}{"meta": map[string]interface{}{"id": "abc"}}))
+
+	// Items without the ID field are keyed by their content.
+	a := c.ItemKey(map[string]interface{}{"name": "x", "n": 1.0})
+	b := c.ItemKey(map[string]interface{}{"n": 1.0, "name": "x"})
+	assert.Equal(t, a, b)
+	assert.Contains(t, a, "sha256:")
+	assert.NotEqual(t, a, c.ItemKey(map[string]interface{}{"name": "y", "n": 1.0}))
+}
+
+type memoryPollRepo struct {
+	domain.WorkflowPollRepository
+	mu   sync.Mutex
+	seen map[string]bool
+}
+
+func (r *memoryPollRepo) MarkSeen(ctx context.Context, nodeID, workflowID uuid.UUID, keys []string) ([]string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var unseen []string
+	for _, key := range keys {
+		if !r.seen[key] {
+			r.seen[key] = true
+			unseen = append(unseen, key)
+		}
+	}
+	return unseen, nil
+}
+
+func (r *memoryPollRepo) UnmarkSeen(ctx context.Context, nodeID uuid.UUID, keys []string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, key := range keys {
+		delete(r.seen, key)
+	}
+	return nil
+}
+
+func (r *memoryPollRepo) SetLastPoll(ctx context.Context, nodeID, workflowID uuid.UUID, polledAt time.Time) error {
+	return nil
+}
+
+type inputRecorder struct {
+	domain.WorkflowRunService
+	domain.WorkflowExecutionService
+	inputs []map[string]interface{}
+	// queueErr fails StartRun.
+	queueErr error
+}
+
+func (r *inputRecorder) StartWorkflowRun(ctx context.Context, workflowID uuid.UUID, input map[string]interface{}) (*domain.WorkflowRunResponse, error) {
+	r.inputs = append(r.inputs, input)
+	return &domain.WorkflowRunResponse{ID: uuid.New(), WorkflowID: workflowID}, nil
+}
+
+func (r *inputRecorder) StartRun(ctx context.Context, runID uuid.UUID) error {
+	return r.queueErr
+}
+
+func TestPoller_StartsRunsForNewItems(t *testing.T) {
+	response := `{"items":[{"id":1},{"id":2}]}`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(response))
+	}))
+	defer server.Close()
+
+	repo := &memoryPollRepo{seen: map[string]bool{}}
+	runs := &inputRecorder{}
+	poller := NewPoller(DefaultPollerConfig(), repo, runs, runs)
+
+	data := map[string]any{"type": "poll_http", "url": server.URL, "items_path": "items", "id_field": "id"}
+	config, err := ParsePollConfig(data)
+	require.NoError(t, err)
+	entry := &pollEntry{
+		trigger: &domain.PollTrigger{NodeID: uuid.New(), WorkflowID: uuid.New(), Data: data},
+		config:  config,
+	}
+
+	// The first poll only records the existing items.
+	poller.poll(context.Background(), entry)
+	assert.Empty(t, runs.inputs)
+
+	response = `{"items":[{"id":3},{"id":1},{"id":2},{"id":3}]}`
+	poller.poll(context.Background(), entry)
+	require.Len(t, runs.inputs, 1)
+	assert.Equal(t, map[string]interface{}{"id": 3.0}, runs.inputs[0]["item"])
+	assert.Equal(t, "id:3", runs.inputs[0]["item_key"])
+	assert.Equal(t, entry.trigger.NodeID.String(), runs.inputs[0]["trigger_node_id"])
+
+	poller.poll(context.Background(), entry)
+	assert.Len(t, runs.inputs, 1)
+}
+
+func TestPoller_RetriesItemsWhoseRunFailedToStart(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"items":[{"id":1}]}`))
+	}))
+	defer server.Close()
+
+	repo := &memoryPollRepo{seen: map[string]bool{}}
+	runs := &inputRecorder{queueErr: errors.New("queue unavailable")}
+	poller := NewPoller(DefaultPollerConfig(), repo, runs, runs)
+
+	data := map[string]any{"type": "poll_http", "url": server.URL, "items_path": "items", "id_field": "id", "fire_on_first_poll": true}
+	config, err := ParsePollConfig(data)
+	require.NoError(t, err)
+	entry := &pollEntry{
+		trigger: &domain.PollTrigger{NodeID: uuid.New(), WorkflowID: uuid.New(), Data: data},
+		config:  config,
+	}
+
+	poller.poll(context.Background(), entry)
+	require.Len(t, runs.inputs, 1)
+	assert.False(t, repo.seen["id:1"])
+
+	runs.queueErr = nil
+	poller.poll(context.Background(), entry)
+	require.Len(t, runs.inputs, 2)
+	assert.True(t, repo.seen["id:1"])
+
+	poller.poll(context.Background(), entry)
+	assert.Len(t, runs.inputs, 2)
+}
@@ -0,0 +1,247 @@
+package trigger
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mr-isik/loki-backend/internal/domain"
+)
+
+// PollerConfig controls how the poller loads triggers and keeps their state.
+type PollerConfig struct {
+	// SyncInterval is how often triggers are reloaded, which picks up
+	// workflows that were published, archived or edited.
+	SyncInterval time.Duration
+	// TickInterval is how often the poller checks for due polls.
+	TickInterval time.Duration
+	// SeenRetention is how long an item that no poll returns any more is
+	// remembered. An item that comes back after that starts a new run.
+	SeenRetention time.Duration
+}
+
+// DefaultPollerConfig returns the settings used when nothing else is configured.
+func DefaultPollerConfig() PollerConfig {
+	return PollerConfig{
+		SyncInterval:  10 * time.Second,
+		TickInterval:  time.Second,
+		SeenRetention: 30 * 24 * time.Hour,
+	}
+}
+
+// Poller calls the endpoints of the poll_http nodes of published workflows
+// on their intervals, and starts a run for every item it has not seen before.
+// Like the scheduler, it should only run on the leader.
+type Poller struct {
+	config           PollerConfig
+	pollRepo         domain.WorkflowPollRepository
+	runService       domain.WorkflowRunService
+	executionService domain.WorkflowExecutionService
+	client           *http.Client
+
+	// entries holds the active triggers by node ID. It is only used from the
+	// goroutine running the poller.
+	entries map[uuid.UUID]*pollEntry
+}
+
+type pollEntry struct {
+	trigger *domain.PollTrigger
+	config  *PollConfig
+	next    time.Time
+	// polled is set once the trigger has been polled, so that the items of
+	// the first poll can be recorded without starting runs.
+	polled bool
+	// running is set while a poll of the trigger is in flight.
+	running atomic.Bool
+}
+
+// NewPoller creates a new poller
+func NewPoller(
+	config PollerConfig,
+	pollRepo domain.WorkflowPollRepository,
+	runService domain.WorkflowRunService,
+	executionService domain.WorkflowExecutionService,
+) *Poller {
+	return &Poller{
+		config:           config,
+		pollRepo:         pollRepo,
+		runService:       runService,
+		executionService: executionService,
+		client:           &http.Client{},
+		entries:          make(map[uuid.UUID]*pollEntry),
+	}
+}
+
+// Run polls triggers until ctx is done and every poll in flight has finished.
+func (p *Poller) Run(ctx context.Context) {
+	log.Println("📡 Poller started")
+	p.entries = make(map[uuid.UUID]*pollEntry)
+
+	tick := time.NewTicker(p.config.TickInterval)
+	defer tick.Stop()
+
+	var wg sync.WaitGroup
+	var lastSync time.Time
+	for {
+		if time.Since(lastSync) >= p.config.SyncInterval {
+			if err := p.sync(ctx); err != nil && ctx.Err() == nil {
+				log.Printf("⚠️ Failed to load poll triggers: %v", err)
+			}
+			if err := p.pollRepo.DeleteSeenBefore(ctx, time.Now().Add(-p.config.SeenRetention)); err != nil && ctx.Err() == nil {
+				log.Printf("⚠️ Failed to delete old seen items: %v", err)
+			}
+			lastSync = time.Now()
+		}
+
+		now := time.Now()
+		for _, entry := range p.entries {
+			if entry.next.After(now) || !entry.running.CompareAndSwap(false, true) {
+				continue
+			}
+			entry.next = now.Add(entry.config.Interval)
+
+			wg.Add(1)
+			go func(entry *pollEntry) {
+				defer wg.Done()
+				defer entry.running.Store(false)
+				p.poll(ctx, entry)
+			}(entry)
+		}
+
+		select {
+		case <-ctx.Done():
+			wg.Wait()
+			log.Println("📡 Poller stopped")
+			return
+		case <-tick.C:
+		}
+	}
+}
+
+// sync reloads the poll_http triggers of published workflows. Triggers whose
+// configuration did not change keep their state.
+func (p *Poller) sync(ctx context.Context) error {
+	triggers, err := p.pollRepo.ListActive(ctx)
+	if err != nil {
+		return err
+	}
+
+	active := make(map[uuid.UUID]bool, len(triggers))
+	for _, trigger := range triggers {
+		active[trigger.NodeID] = true
+
+		if entry, ok := p.entries[trigger.NodeID]; ok && reflect.DeepEqual(entry.trigger.Data, trigger.Data) {
+			continue
+		}
+
+		config, err := ParsePollConfig(trigger.Data)
+		if err != nil {
+			log.Printf("⚠️ Poll node %s of workflow %s is not polled: %v", trigger.NodeID, trigger.WorkflowID, err)
+			delete(p.entries, trigger.NodeID)
+			continue
+		}
+
+		entry := &pollEntry{trigger: trigger, config: config}
+		if trigger.LastPollAt != nil {
+			entry.polled = true
+			entry.next = trigger.LastPollAt.Add(config.Interval)
+		}
+		p.entries[trigger.NodeID] = entry
+	}
+
+	for nodeID := range p.entries {
+		if !active[nodeID] {
+			delete(p.entries, nodeID)
+		}
+	}
+
+	return nil
+}
+
+// poll fetches the items of a trigger and starts runs for the new ones.
+func (p *Poller) poll(ctx context.Context, entry *pollEntry) {
+	trigger := entry.trigger
+
+	items, err := entry.config.Fetch(ctx, p.client)
+	if err != nil {
+		if ctx.Err() == nil {
+			log.Printf("⚠️ Poll of node %s failed: %v", trigger.NodeID, err)
+		}
+		return
+	}
+
+	// Items repeated within a response count once.
+	keys := make([]string, 0, len(items))
+	byKey := make(map[string]interface{}, len(items))
+	for _, item := range items {
+		key := entry.config.ItemKey(item)
+		if _, ok := byKey[key]; !ok {
+			keys = append(keys, key)
+			byKey[key] = item
+		}
+	}
+
+	// Marking items seen is atomic, so an item starts one run even if polls
+	// of the trigger overlap. Items whose run cannot be queued are unmarked
+	// again, so that the next poll retries them.
+	unseen, err := p.pollRepo.MarkSeen(ctx, trigger.NodeID, trigger.WorkflowID, keys)
+	if err != nil {
+		log.Printf("⚠️ Failed to record items of poll node %s: %v", trigger.NodeID, err)
+		return
+	}
+	polledAt := time.Now()
+	if err := p.pollRepo.SetLastPoll(ctx, trigger.NodeID, trigger.WorkflowID, polledAt); err != nil {
+		log.Printf("⚠️ Failed to record poll of node %s: %v", trigger.NodeID, err)
+	}
+
+	firstPoll := !entry.polled
+	entry.polled = true
+	if firstPoll && !entry.config.FireOnFirstPoll {
+		log.Printf("📡 Poll node %s recorded %d existing items", trigger.NodeID, len(keys))
+		return
+	}
+
+	isNew := make(map[string]bool, len(unseen))
+	for _, key := range unseen {
+		isNew[key] = true
+	}
+	for _, key := range keys {
+		if !isNew[key] {
+			continue
+		}
+		if err := p.fire(ctx, trigger, key, byKey[key], polledAt); err != nil {
+			log.Printf("⚠️ Failed to start run of workflow %s for polled item %s: %v", trigger.WorkflowID, key, err)
+			if err := p.pollRepo.UnmarkSeen(context.WithoutCancel(ctx), trigger.NodeID, []string{key}); err != nil {
+				log.Printf("⚠️ Failed to unmark item %s of poll node %s: %v", key, trigger.NodeID, err)
+			}
+		}
+	}
+}
+
+// fire starts a run for a new item. The poll_http node outputs the item with
+// its key and the poll time.
+func (p *Poller) fire(ctx context.Context, trigger *domain.PollTrigger, key string, item interface{}, polledAt time.Time) error {
+	input := map[string]interface{}{
+		"item":            item,
+		"item_key":        key,
+		"polled_at":       polledAt.Format(time.RFC3339),
+		"trigger_node_id": trigger.NodeID.String(),
+	}
+
+	run, err := p.runService.StartWorkflowRun(ctx, trigger.WorkflowID, input)
+	if err != nil {
+		return fmt.Errorf("failed to create run: %w", err)
+	}
+	if err := p.executionService.StartRun(ctx, run.ID); err != nil {
+		return fmt.Errorf("failed to queue run %s: %w", run.ID, err)
+	}
+
+	log.Printf("📡 Poll node %s started run %s for item %s", trigger.NodeID, run.ID, key)
+	return nil
+}
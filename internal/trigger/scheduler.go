@@ -0,0 +1,213 @@
+package trigger
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"reflect"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mr-isik/loki-backend/internal/domain"
+)
+
+// Config controls how the scheduler loads schedules and fires them.
+type Config struct {
+	// SyncInterval is how often schedules are reloaded, which picks up
+	// workflows that were published, archived or edited.
+	SyncInterval time.Duration
+	// TickInterval is how often the scheduler checks for due fire times.
+	TickInterval time.Duration
+	// MisfireThreshold is how late a fire time may be and still count as on
+	// time rather than missed.
+	MisfireThreshold time.Duration
+	// FireRetention is how long claimed fire times are kept to stop other
+	// instances from firing them again.
+	FireRetention time.Duration
+}
+
+// DefaultConfig returns the settings used when nothing else is configured.
+func DefaultConfig() Config {
+	return Config{
+		SyncInterval:     10 * time.Second,
+		TickInterval:     time.Second,
+		MisfireThreshold: time.Minute,
+		FireRetention:    24 * time.Hour,
+	}
+}
+
+// Scheduler starts runs of published workflows at the fire times of their
+// cron nodes. In deployments with several instances it should only run on
+// the leader (see RunAsLeader); every fire time is also claimed in the
+// database before its run starts, so that an instance that lost leadership
+// without noticing cannot start it a second time.
+type Scheduler struct {
+	config           Config
+	scheduleRepo     domain.WorkflowScheduleRepository
+	runService       domain.WorkflowRunService
+	executionService domain.WorkflowExecutionService
+
+	// entries holds the active schedules by cron node ID. It is only used
+	// from the goroutine running the scheduler, and reset on every Run since
+	// another instance may have fired the schedules in between.
+	entries map[uuid.UUID]*scheduleEntry
+}
+
+type scheduleEntry struct {
+	trigger  *domain.CronTrigger
+	schedule *CronSchedule
+	// last is the latest fire time handled; next is the upcoming one, or
+	// zero when the schedule has ended.
+	last time.Time
+	next time.Time
+}
+
+// NewScheduler creates a new scheduler
+func NewScheduler(
+	config Config,
+	scheduleRepo domain.WorkflowScheduleRepository,
+	runService domain.WorkflowRunService,
+	executionService domain.WorkflowExecutionService,
+) *Scheduler {
+	return &Scheduler{
+		config:           config,
+		scheduleRepo:     scheduleRepo,
+		runService:       runService,
+		executionService: executionService,
+		entries:          make(map[uuid.UUID]*scheduleEntry),
+	}
+}
+
+// Run fires schedules until ctx is done.
+func (s *Scheduler) Run(ctx context.Context) {
+	log.Println("⏰ Scheduler started")
+	s.entries = make(map[uuid.UUID]*scheduleEntry)
+
+	tick := time.NewTicker(s.config.TickInterval)
+	defer tick.Stop()
+
+	var lastSync time.Time
+	for {
+		if time.Since(lastSync) >= s.config.SyncInterval {
+			if err := s.sync(ctx); err != nil && ctx.Err() == nil {
+				log.Printf("⚠️ Failed to load schedules: %v", err)
+			}
+			if err := s.scheduleRepo.DeleteFiresBefore(ctx, time.Now().Add(-s.config.FireRetention)); err != nil && ctx.Err() == nil {
+				log.Printf("⚠️ Failed to delete old fire times: %v", err)
+			}
+			lastSync = time.Now()
+		}
+		s.fireDue(ctx, time.Now())
+
+		select {
+		case <-ctx.Done():
+			log.Println("⏰ Scheduler stopped")
+			return
+		case <-tick.C:
+		}
+	}
+}
+
+// sync reloads the cron triggers of published workflows. Schedules whose
+// configuration did not change keep their state.
+func (s *Scheduler) sync(ctx context.Context) error {
+	triggers, err := s.scheduleRepo.ListActive(ctx)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	active := make(map[uuid.UUID]bool, len(triggers))
+	for _, trigger := range triggers {
+		active[trigger.NodeID] = true
+
+		if entry, ok := s.entries[trigger.NodeID]; ok && reflect.DeepEqual(entry.trigger.Data, trigger.Data) {
+			continue
+		}
+
+		schedule, err := ParseCronSchedule(trigger.Data)
+		if err != nil {
+			log.Printf("⚠️ Cron node %s of workflow %s is not scheduled: %v", trigger.NodeID, trigger.WorkflowID, err)
+			delete(s.entries, trigger.NodeID)
+			continue
+		}
+
+		last := now
+		if trigger.LastFireAt != nil {
+			last = *trigger.LastFireAt
+		} else if err := s.scheduleRepo.SetLastFire(ctx, trigger.NodeID, trigger.WorkflowID, now); err != nil {
+			log.Printf("⚠️ Failed to activate schedule of cron node %s: %v", trigger.NodeID, err)
+			continue
+		}
+
+		s.entries[trigger.NodeID] = &scheduleEntry{
+			trigger:  trigger,
+			schedule: schedule,
+			last:     last,
+			next:     schedule.Next(last),
+		}
+	}
+
+	for nodeID := range s.entries {
+		if !active[nodeID] {
+			delete(s.entries, nodeID)
+		}
+	}
+
+	return nil
+}
+
+// fireDue starts runs for every schedule with fire times up to now.
+func (s *Scheduler) fireDue(ctx context.Context, now time.Time) {
+	for _, entry := range s.entries {
+		if entry.next.IsZero() || entry.next.After(now) {
+			continue
+		}
+
+		due := entry.schedule.Due(entry.last, now, s.config.MisfireThreshold)
+
+		// The fire times are recorded as handled before any run starts, so
+		// that a crash cannot fire them twice.
+		if err := s.scheduleRepo.SetLastFire(ctx, entry.trigger.NodeID, entry.trigger.WorkflowID, now); err != nil {
+			log.Printf("⚠️ Failed to record fire of cron node %s: %v", entry.trigger.NodeID, err)
+			continue
+		}
+		entry.last = now
+		entry.next = entry.schedule.Next(now)
+
+		for _, fireTime := range due {
+			if err := s.fire(ctx, entry.trigger, fireTime); err != nil {
+				log.Printf("⚠️ Failed to start scheduled run of workflow %s: %v", entry.trigger.WorkflowID, err)
+			}
+		}
+	}
+}
+
+// fire starts a run for one fire time of a trigger, unless the fire time was
+// claimed already. The cron node outputs the fire time as its timestamp.
+func (s *Scheduler) fire(ctx context.Context, trigger *domain.CronTrigger, fireTime time.Time) error {
+	claimed, err := s.scheduleRepo.ClaimFire(ctx, trigger.NodeID, trigger.WorkflowID, fireTime)
+	if err != nil {
+		return fmt.Errorf("failed to claim fire time: %w", err)
+	}
+	if !claimed {
+		log.Printf("⏰ Fire time %s of cron node %s was already started", fireTime.Format(time.RFC3339), trigger.NodeID)
+		return nil
+	}
+
+	input := map[string]interface{}{
+		"timestamp":       fireTime.Format(time.RFC3339),
+		"trigger_node_id": trigger.NodeID.String(),
+	}
+
+	run, err := s.runService.StartWorkflowRun(ctx, trigger.WorkflowID, input)
+	if err != nil {
+		return fmt.Errorf("failed to create run: %w", err)
+	}
+	if err := s.executionService.StartRun(ctx, run.ID); err != nil {
+		return fmt.Errorf("failed to queue run %s: %w", run.ID, err)
+	}
+
+	log.Printf("⏰ Cron node %s started run %s (fire time %s)", trigger.NodeID, run.ID, fireTime.Format(time.RFC3339))
+	return nil
+}
@@ -0,0 +1,346 @@
+package trigger
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"mime"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mr-isik/loki-backend/internal/domain"
+)
+
+// WebhookNodeType is the type key of the nodes started by inbound requests.
+const WebhookNodeType = "webhook"
+
+// Authentication methods of webhook nodes.
+const (
+	WebhookAuthNone   = "none"
+	WebhookAuthHeader = "header"
+	WebhookAuthBasic  = "basic"
+	// WebhookAuthHMAC checks a signature of the raw body, as sent by GitHub
+	// in X-Hub-Signature-256.
+	WebhookAuthHMAC = "hmac"
+	// WebhookAuthStripe checks a Stripe-Signature header, which signs the
+	// timestamp and the raw body.
+	WebhookAuthStripe = "stripe"
+)
+
+// Response modes of webhook nodes.
+const (
+	// WebhookRespondOnReceived answers 202 as soon as the run is queued.
+	WebhookRespondOnReceived = "on_received"
+	// WebhookRespondOnFinish waits for the run and answers with its result,
+	// which a respond node can set.
+	WebhookRespondOnFinish = "respond_node"
+)
+
+// stripeTolerance is how old a Stripe signature timestamp may be.
+const stripeTolerance = 5 * time.Minute
+
+// WebhookConfig is the configuration of a webhook node.
+type WebhookConfig struct {
+	// Method is the HTTP method the webhook accepts (default POST); ANY
+	// accepts every method.
+	Method string `json:"method"`
+	// Path is matched against the part of the URL after the workflow ID.
+	Path         string      `json:"path"`
+	Auth         WebhookAuth `json:"auth"`
+	ResponseMode string      `json:"response_mode"`
+}
+
+// WebhookAuth configures how requests are verified.
+type WebhookAuth struct {
+	// Type is required; a webhook that accepts every request must say so
+	// with WebhookAuthNone.
+	Type string `json:"type"`
+	// HeaderName and Token are used by header authentication.
+	HeaderName string `json:"header_name"`
+	Token      string `json:"token"`
+	// Username and Password are used by basic authentication.
+	Username string `json:"username"`
+	Password string `json:"password"`
+	// Secret signs the requests of hmac and stripe authentication.
+	Secret string `json:"secret"`
+	// SignatureHeader, Algorithm (sha256, sha1 or sha512), Encoding (hex or
+	// base64) and Prefix describe the signature of hmac authentication.
+	SignatureHeader string `json:"signature_header"`
+	Algorithm       string `json:"algorithm"`
+	Encoding        string `json:"encoding"`
+	Prefix          string `json:"prefix"`
+}
+
+// ParseWebhookConfig reads the configuration of a webhook node and fills in
+// the defaults.
+func ParseWebhookConfig(data map[string]any) (*WebhookConfig, error) {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+	var c WebhookConfig
+	if err := json.Unmarshal(raw, &c); err != nil {
+		return nil, fmt.Errorf("invalid webhook configuration: %w", err)
+	}
+
+	c.Method = strings.ToUpper(strings.TrimSpace(c.Method))
+	if c.Method == "" {
+		c.Method = "POST"
+	}
+	c.Path = normalizeWebhookPath(c.Path)
+
+	switch c.ResponseMode {
+	case "":
+		c.ResponseMode = WebhookRespondOnReceived
+	case WebhookRespondOnReceived, WebhookRespondOnFinish:
+	default:
+		return nil, fmt.Errorf("unknown response_mode %q", c.ResponseMode)
+	}
+
+	a := &c.Auth
+	switch a.Type {
+	case "":
+		return nil, fmt.Errorf("auth.type is required; use %q for a public webhook", WebhookAuthNone)
+	case WebhookAuthNone:
+	case WebhookAuthHeader:
+		if a.HeaderName == "" {
+			a.HeaderName = "X-Webhook-Token"
+		}
+		if a.Token == "" {
+			return nil, errors.New("header authentication requires a token")
+		}
+	case WebhookAuthBasic:
+		if a.Username == "" || a.Password == "" {
+			return nil, errors.New("basic authentication requires a username and password")
+		}
+	case WebhookAuthHMAC:
+		if a.Secret == "" {
+			return nil, errors.New("hmac authentication requires a secret")
+		}
+		if a.SignatureHeader == "" {
+			a.SignatureHeader = "X-Hub-Signature-256"
+		}
+		if a.Algorithm == "" {
+			a.Algorithm = "sha256"
+		}
+		if a.Encoding == "" {
+			a.Encoding = "hex"
+		}
+		if newHash(a.Algorithm) == nil {
+			return nil, fmt.Errorf("unknown hmac algorithm %q", a.Algorithm)
+		}
+		if a.Encoding != "hex" && a.Encoding != "base64" {
+			return nil, fmt.Errorf("unknown signature encoding %q", a.Encoding)
+		}
+	case WebhookAuthStripe:
+		if a.Secret == "" {
+			return nil, errors.New("stripe authentication requires a secret")
+		}
+	default:
+		return nil, fmt.Errorf("unknown authentication type %q", a.Type)
+	}
+
+	return &c, nil
+}
+
+// MatchesPath reports whether the webhook listens on path.
+func (c *WebhookConfig) MatchesPath(path string) bool {
+	return c.Path == normalizeWebhookPath(path)
+}
+
+// AllowsMethod reports whether the webhook accepts the HTTP method.
+func (c *WebhookConfig) AllowsMethod(method string) bool {
+	return c.Method == "ANY" || strings.EqualFold(c.Method, method)
+}
+
+// Verify checks that a request is authorized to trigger the webhook. It
+// returns domain.ErrWebhookUnauthorized when it is not.
+func (c *WebhookConfig) Verify(req *domain.WebhookRequest, now time.Time) error {
+	a := c.Auth
+	var ok bool
+	switch a.Type {
+	case WebhookAuthNone:
+		ok = true
+	case WebhookAuthHeader:
+		ok = secureEqual(req.Header(a.HeaderName), a.Token)
+	case WebhookAuthBasic:
+		ok = verifyBasic(req.Header("Authorization"), a.Username, a.Password)
+	case WebhookAuthHMAC:
+		signature := req.Header(a.SignatureHeader)
+		ok = strings.HasPrefix(signature, a.Prefix) &&
+			verifySignature(strings.TrimPrefix(signature, a.Prefix), a.Algorithm, a.Encoding, a.Secret, req.Body)
+	case WebhookAuthStripe:
+		ok = verifyStripe(req.Header("Stripe-Signature"), a.Secret, req.Body, now)
+	}
+
+	if !ok {
+		return domain.ErrWebhookUnauthorized
+	}
+	return nil
+}
+
+// credentialHeaders are left out of the run input of every webhook request.
+var credentialHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie"}
+
+// Input builds the run input of a webhook request: the parsed body, the
+// query, the headers and the raw body. Credentials, such as the header
+// holding the token or signature the request was verified with, are left
+// out, since the input is stored with the run.
+func (c *WebhookConfig) Input(req *domain.WebhookRequest) map[string]interface{} {
+	query := make(map[string]interface{}, len(req.Query))
+	for k, v := range req.Query {
+		query[k] = v
+	}
+
+	hidden := append([]string{}, credentialHeaders...)
+	switch c.Auth.Type {
+	case WebhookAuthHeader:
+		hidden = append(hidden, c.Auth.HeaderName)
+	case WebhookAuthHMAC:
+		hidden = append(hidden, c.Auth.SignatureHeader)
+	case WebhookAuthStripe:
+		hidden = append(hidden, "Stripe-Signature")
+	}
+	headers := make(map[string]interface{}, len(req.Headers))
+	for k, v := range req.Headers {
+		headers[k] = v
+	}
+	for _, name := range hidden {
+		delete(headers, strings.ToLower(name))
+	}
+
+	return map[string]interface{}{
+		"method":   req.Method,
+		"path":     normalizeWebhookPath(req.Path),
+		"query":    query,
+		"headers":  headers,
+		"body":     parseWebhookBody(req.Header("Content-Type"), req.Body),
+		"raw_body": string(req.Body),
+	}
+}
+
+// parseWebhookBody decodes JSON and form bodies. Other bodies are passed on
+// as text.
+func parseWebhookBody(contentType string, body []byte) interface{} {
+	if len(body) == 0 {
+		return nil
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	switch {
+	case mediaType == "application/json" || strings.HasSuffix(mediaType, "+json"):
+		var parsed interface{}
+		if err := json.Unmarshal(body, &parsed); err == nil {
+			return parsed
+		}
+	case mediaType == "application/x-www-form-urlencoded":
+		values, err := url.ParseQuery(string(body))
+		if err == nil {
+			form := make(map[string]interface{}, len(values))
+			for k, v := range values {
+				if len(v) == 1 {
+					form[k] = v[0]
+				} else {
+					form[k] = v
+				}
+			}
+			return form
+		}
+	}
+	return string(body)
+}
+
+func normalizeWebhookPath(path string) string {
+	return strings.Trim(strings.TrimSpace(path), "/")
+}
+
+func secureEqual(a, b string) bool {
+	return subtle.ConstantTimeCompare([]byte(a), []byte(b)) == 1
+}
+
+func verifyBasic(header, username, password string) bool {
+	encoded, found := strings.CutPrefix(header, "Basic ")
+	if !found {
+		return false
+	}
+	decoded, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return false
+	}
+	user, pass, _ := strings.Cut(string(decoded), ":")
+	// Both comparisons run so that the timing does not reveal which failed.
+	userOK := secureEqual(user, username)
+	passOK := secureEqual(pass, password)
+	return userOK && passOK
+}
+
+func newHash(algorithm string) func() hash.Hash {
+	switch algorithm {
+	case "sha256":
+		return sha256.New
+	case "sha1":
+		return sha1.New
+	case "sha512":
+		return sha512.New
+	}
+	return nil
+}
+
+func verifySignature(signature, algorithm, encoding, secret string, payload []byte) bool {
+	var got []byte
+	var err error
+	if encoding == "base64" {
+		got, err = base64.StdEncoding.DecodeString(signature)
+	} else {
+		got, err = hex.DecodeString(signature)
+	}
+	if err != nil {
+		return false
+	}
+
+	mac := hmac.New(newHash(algorithm), []byte(secret))
+	mac.Write(payload)
+	return hmac.Equal(got, mac.Sum(nil))
+}
+
+// verifyStripe checks a header of the form t=<unix time>,v1=<signature>,...
+// where each v1 signature is the hex HMAC-SHA256 of "<t>.<body>".
+func verifyStripe(header, secret string, body []byte, now time.Time) bool {
+	var timestamp string
+	var signatures []string
+	for _, part := range strings.Split(header, ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch key {
+		case "t":
+			timestamp = value
+		case "v1":
+			signatures = append(signatures, value)
+		}
+	}
+
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return false
+	}
+	if age := now.Sub(time.Unix(seconds, 0)); age > stripeTolerance || age < -stripeTolerance {
+		return false
+	}
+
+	payload := append([]byte(timestamp+"."), body...)
+	for _, signature := range signatures {
+		if verifySignature(signature, "sha256", "hex", secret, payload) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,196 @@
+package trigger
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/mr-isik/loki-backend/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func sign(secret, payload string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(payload))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func webhookRequest(headers map[string]string, body string) *domain.WebhookRequest {
+	return &domain.WebhookRequest{Method: "POST", Headers: headers, Body: []byte(body)}
+}
+
+func TestParseWebhookConfig(t *testing.T) {
+	c, err := ParseWebhookConfig(map[string]any{"type": "webhook", "path": "/github/push/", "method": "post", "auth": map[string]any{"type": "none"}})
+	require.NoError(t, err)
+	assert.Equal(t, "POST", c.Method)
+	assert.Equal(t, WebhookRespondOnReceived, c.ResponseMode)
+	assert.Equal(t, WebhookAuthNone, c.Auth.Type)
+	assert.True(t, c.MatchesPath("github/push"))
+	assert.False(t, c.MatchesPath("github"))
+	assert.True(t, c.AllowsMethod("POST"))
+	assert.False(t, c.AllowsMethod("GET"))
+
+	c, err = ParseWebhookConfig(map[string]any{"method": "ANY", "auth": map[string]any{"type": "none"}})
+	require.NoError(t, err)
+	assert.True(t, c.MatchesPath("/"))
+	assert.True(t, c.AllowsMethod("DELETE"))
+
+	invalid := []map[string]any{
+		{},
+		{"auth": map[string]any{}},
+		{"response_mode": "later", "auth": map[string]any{"type": "none"}},
+		{"auth": map[string]any{"type": "magic"}},
+		{"auth": map[string]any{"type": "header"}},
+		{"auth": map[string]any{"type": "basic", "username": "u"}},
+		{"auth": map[string]any{"type": "hmac"}},
+		{"auth": map[string]any{"type": "hmac", "secret": "s", "algorithm": "md5"}},
+		{"auth": map[string]any{"type": "stripe"}},
+	}
+	for _, data := range invalid {
+		_, err := ParseWebhookConfig(data)
+		assert.Error(t, err, data)
+	}
+}
+
+func TestWebhookConfig_Verify(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	body := `{"action":"opened"}`
+	timestamp := strconv.FormatInt(now.Unix(), 10)
+	stale := strconv.FormatInt(now.Add(-10*time.Minute).Unix(), 10)
+
+	tests := []struct {
+		name    string
+		auth    map[string]any
+		headers map[string]string
+		wantErr bool
+	}{
+		{
+			name: "no auth",
+			auth: map[string]any{"type": "none"},
+		},
+		{
+			name:    "header token",
+			auth:    map[string]any{"type": "header", "header_name": "X-Token", "token": "secret"},
+			headers: map[string]string{"x-token": "secret"},
+		},
+		{
+			name:    "wrong header token",
+			auth:    map[string]any{"type": "header", "header_name": "X-Token", "token": "secret"},
+			headers: map[string]string{"x-token": "guess"},
+			wantErr: true,
+		},
+		{
+			name:    "basic",
+			auth:    map[string]any{"type": "basic", "username": "bot", "password": "pw"},
+			headers: map[string]string{"authorization": "Basic " + base64.StdEncoding.EncodeToString([]byte("bot:pw"))},
+		},
+		{
+			name:    "wrong basic password",
+			auth:    map[string]any{"type": "basic", "username": "bot", "password": "pw"},
+			headers: map[string]string{"authorization": "Basic " + base64.StdEncoding.EncodeToString([]byte("bot:nope"))},
+			wantErr: true,
+		},
+		{
+			name:    "github signature",
+			auth:    map[string]any{"type": "hmac", "secret": "gh", "prefix": "sha256="},
+			headers: map[string]string{"x-hub-signature-256": "sha256=" + sign("gh", body)},
+		},
+		{
+			name:    "github signature without prefix",
+			auth:    map[string]any{"type": "hmac", "secret": "gh", "prefix": "sha256="},
+			headers: map[string]string{"x-hub-signature-256": sign("gh", body)},
+			wantErr: true,
+		},
+		{
+			name:    "signature with another secret",
+			auth:    map[string]any{"type": "hmac", "secret": "gh"},
+			headers: map[string]string{"x-hub-signature-256": sign("other", body)},
+			wantErr: true,
+		},
+		{
+			name:    "missing signature",
+			auth:    map[string]any{"type": "hmac", "secret": "gh"},
+			wantErr: true,
+		},
+		{
+			name:    "stripe signature",
+			auth:    map[string]any{"type": "stripe", "secret": "whsec"},
+			headers: map[string]string{"stripe-signature": fmt.Sprintf("t=%s,v1=%s,v0=ignored", timestamp, sign("whsec", timestamp+"."+body))},
+		},
+		{
+			name:    "stale stripe signature",
+			auth:    map[string]any{"type": "stripe", "secret": "whsec"},
+			headers: map[string]string{"stripe-signature": fmt.Sprintf("t=%s,v1=%s", stale, sign("whsec", stale+"."+body))},
+			wantErr: true,
+		},
+		{
+			name:    "stripe signature of another timestamp",
+			auth:    map[string]any{"type": "stripe", "secret": "whsec"},
+			headers: map[string]string{"stripe-signature": fmt.Sprintf("t=%s,v1=%s", timestamp, sign("whsec", stale+"."+body))},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := ParseWebhookConfig(map[string]any{"auth": tt.auth})
+			require.NoError(t, err)
+
+			err = c.Verify(webhookRequest(tt.headers, body), now)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, domain.ErrWebhookUnauthorized)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestWebhookConfig_Input(t *testing.T) {
+	public, err := ParseWebhookConfig(map[string]any{"auth": map[string]any{"type": "none"}})
+	require.NoError(t, err)
+	req := &domain.WebhookRequest{
+		Method:  "POST",
+		Path:    "/orders/",
+		Headers: map[string]string{"content-type": "application/json; charset=utf-8"},
+		Query:   map[string]string{"source": "shop"},
+		Body:    []byte(`{"id":7,"items":["a"]}`),
+	}
+
+	input := public.Input(req)
+	assert.Equal(t, "POST", input["method"])
+	assert.Equal(t, "orders", input["path"])
+	assert.Equal(t, map[string]interface{}{"source": "shop"}, input["query"])
+	assert.Equal(t, map[string]interface{}{"content-type": "application/json; charset=utf-8"}, input["headers"])
+	assert.Equal(t, map[string]interface{}{"id": 7.0, "items": []interface{}{"a"}}, input["body"])
+	assert.Equal(t, `{"id":7,"items":["a"]}`, input["raw_body"])
+
+	req.Headers = map[string]string{"content-type": "application/x-www-form-urlencoded"}
+	req.Body = []byte("name=loki&tag=a&tag=b")
+	assert.Equal(t, map[string]interface{}{"name": "loki", "tag": []string{"a", "b"}}, public.Input(req)["body"])
+
+	req.Headers = map[string]string{"content-type": "text/plain"}
+	req.Body = []byte("hello")
+	assert.Equal(t, "hello", public.Input(req)["body"])
+
+	req.Headers = map[string]string{
+		"authorization":       "Bearer x",
+		"cookie":              "session=abc",
+		"x-hub-signature-256": "sha256=abc",
+		"x-github-event":      "push",
+	}
+	hmacHook, err := ParseWebhookConfig(map[string]any{"auth": map[string]any{"type": "hmac", "secret": "s"}})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"x-github-event": "push"}, hmacHook.Input(req)["headers"])
+
+	tokenHook, err := ParseWebhookConfig(map[string]any{"auth": map[string]any{"type": "header", "header_name": "X-Token", "token": "t"}})
+	require.NoError(t, err)
+	req.Headers = map[string]string{"x-token": "t", "accept": "*/*"}
+	assert.Equal(t, map[string]interface{}{"accept": "*/*"}, tokenHook.Input(req)["headers"])
+}
@@ -0,0 +1,170 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mr-isik/loki-backend/internal/domain"
+	"github.com/mr-isik/loki-backend/internal/engine"
+)
+
+// Config controls how a worker polls and leases jobs.
+type Config struct {
+	// Concurrency is the number of runs executed at the same time.
+	Concurrency int
+	// PollInterval is how long an idle worker waits before polling again.
+	PollInterval time.Duration
+	// LeaseDuration is how long a job stays leased without being extended.
+	// Leases are extended at a third of this interval while a run executes.
+	LeaseDuration time.Duration
+	// MaxAttempts is how often a job is leased before its run is failed
+	// instead of executed again, e.g. because it keeps crashing workers.
+	// Zero allows any number of attempts.
+	MaxAttempts int
+}
+
+// DefaultConfig returns the settings used when nothing else is configured.
+func DefaultConfig() Config {
+	return Config{
+		Concurrency:   4,
+		PollInterval:  time.Second,
+		LeaseDuration: 30 * time.Second,
+		MaxAttempts:   5,
+	}
+}
+
+// Worker leases queued runs and executes them.
+type Worker struct {
+	id               string
+	config           Config
+	jobRepo          domain.WorkflowRunJobRepository
+	executionService domain.WorkflowExecutionService
+}
+
+// NewWorker creates a new worker
+func NewWorker(config Config, jobRepo domain.WorkflowRunJobRepository, executionService domain.WorkflowExecutionService) *Worker {
+	hostname, _ := os.Hostname()
+	return &Worker{
+		id:               fmt.Sprintf("%s-%d-%s", hostname, os.Getpid(), uuid.NewString()[:8]),
+		config:           config,
+		jobRepo:          jobRepo,
+		executionService: executionService,
+	}
+}
+
+// Run executes jobs until ctx is done and every started run has finished.
+func (w *Worker) Run(ctx context.Context) {
+	concurrency := w.config.Concurrency
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	log.Printf("👷 Worker %s started with concurrency %d", w.id, concurrency)
+
+	var wg sync.WaitGroup
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			w.loop(ctx)
+		}()
+	}
+	wg.Wait()
+
+	log.Printf("👷 Worker %s stopped", w.id)
+}
+
+func (w *Worker) loop(ctx context.Context) {
+	for ctx.Err() == nil {
+		job, err := w.jobRepo.Lease(ctx, w.id, w.config.LeaseDuration)
+		if err != nil {
+			if !errors.Is(err, domain.ErrNoRunJobAvailable) && ctx.Err() == nil {
+				log.Printf("⚠️ Failed to lease run job: %v", err)
+			}
+			sleep(ctx, w.config.PollInterval)
+			continue
+		}
+
+		w.process(job)
+	}
+}
+
+// process executes a leased job and keeps its lease alive meanwhile. Runs are
+// not tied to the worker's context, so that a shutdown lets them finish; if
+// the lease is lost, another worker owns the job and the run is abandoned
+// without recording anything.
+func (w *Worker) process(job *domain.WorkflowRunJob) {
+	ctx, cancel := context.WithCancelCause(context.Background())
+	defer cancel(nil)
+
+	if w.config.MaxAttempts > 0 && job.Attempts > w.config.MaxAttempts {
+		w.giveUp(job)
+		return
+	}
+
+	go w.keepLease(ctx, cancel, job)
+
+	resume := job.Attempts > 1
+	err := w.executionService.ExecuteRun(ctx, job.RunID, resume)
+	if errors.Is(context.Cause(ctx), engine.ErrLeaseLost) {
+		return
+	}
+	if err != nil {
+		log.Printf("run %s finished with errors: %v", job.RunID, err)
+	}
+
+	if err := w.jobRepo.Complete(context.Background(), job.ID, w.id); err != nil {
+		log.Printf("⚠️ Failed to complete job %s: %v", job.ID, err)
+	}
+}
+
+// giveUp fails a job whose run was attempted too often, along with the run.
+func (w *Worker) giveUp(job *domain.WorkflowRunJob) {
+	log.Printf("⚠️ Job %s exceeded %d attempts; failing run %s", job.ID, w.config.MaxAttempts, job.RunID)
+
+	if err := w.executionService.FailRun(context.Background(), job.RunID); err != nil {
+		log.Printf("⚠️ Failed to fail run %s: %v", job.RunID, err)
+	}
+	if err := w.jobRepo.Fail(context.Background(), job.ID, w.id); err != nil {
+		log.Printf("⚠️ Failed to fail job %s: %v", job.ID, err)
+	}
+}
+
+func (w *Worker) keepLease(ctx context.Context, cancel context.CancelCauseFunc, job *domain.WorkflowRunJob) {
+	ticker := time.NewTicker(w.config.LeaseDuration / 3)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		err := w.jobRepo.ExtendLease(ctx, job.ID, w.id, w.config.LeaseDuration)
+		if errors.Is(err, domain.ErrRunJobLeaseLost) {
+			log.Printf("⚠️ Lost lease of job %s; abandoning run %s", job.ID, job.RunID)
+			cancel(engine.ErrLeaseLost)
+			return
+		}
+		if err != nil && ctx.Err() == nil {
+			log.Printf("⚠️ Failed to extend lease of job %s: %v", job.ID, err)
+		}
+	}
+}
+
+func sleep(ctx context.Context, d time.Duration) {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+	case <-ctx.Done():
+	}
+}